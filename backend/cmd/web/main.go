@@ -3,6 +3,7 @@ package main
 import (
 	"backend/internal/config"
 	"fmt"
+	"os"
 )
 
 func main() {
@@ -11,6 +12,17 @@ func main() {
 	validator := config.NewValidator()
 	redis := config.NewRedisClient(viper)
 	db := config.NewDatabase(viper, log)
+
+	// Subcommands run once and exit without starting the web server
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "reconcile":
+			os.Exit(runReconcile(db, log, validator))
+		default:
+			log.Fatalf("Unknown command: %s", os.Args[1])
+		}
+	}
+
 	app := config.NewFiber(viper)
 
 	config.Bootstrap(&config.BootstrapConfig{
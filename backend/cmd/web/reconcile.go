@@ -0,0 +1,44 @@
+package main
+
+import (
+	"backend/internal/repository"
+	"backend/internal/usecase"
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// runReconcile checks the ledger once and prints the JSON report to stdout.
+// It returns exit code 0 when the ledger is consistent, 1 on discrepancies and 2 on errors.
+func runReconcile(db *gorm.DB, log *logrus.Logger, validate *validator.Validate) int {
+	reconciliationUseCase := usecase.NewReconciliationUseCase(
+		db,
+		log,
+		validate,
+		repository.NewWalletRepository(log),
+		repository.NewWalletMutationRepository(log),
+		repository.NewTransactionRepository(log),
+	)
+
+	report, err := reconciliationUseCase.Run(context.Background())
+	if err != nil {
+		log.Errorf("Reconciliation failed: %v", err)
+		return 2
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Errorf("Failed to write reconciliation report: %v", err)
+		return 2
+	}
+
+	if !report.Consistent {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,20 @@
+-- Withdrawals leave the bank without a destination wallet, so to_wallet_id cannot become NOT NULL again
+-- while they are in the ledger. The migration refuses to run instead of failing halfway through.
+DROP PROCEDURE IF EXISTS refuse_withdraw_rollback;
+
+CREATE PROCEDURE refuse_withdraw_rollback()
+BEGIN
+    IF EXISTS (SELECT 1 FROM transactions WHERE to_wallet_id IS NULL) THEN
+        SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'Transactions without a destination wallet exist, refusing to make to_wallet_id NOT NULL';
+    END IF;
+END;
+
+CALL refuse_withdraw_rollback();
+
+DROP PROCEDURE refuse_withdraw_rollback;
+
+ALTER TABLE transactions
+    MODIFY COLUMN to_wallet_id BIGINT UNSIGNED NOT NULL;
+
+ALTER TABLE wallets
+    DROP COLUMN held_balance;
@@ -0,0 +1,5 @@
+ALTER TABLE wallets
+    ADD COLUMN held_balance DECIMAL(20, 2) NOT NULL DEFAULT 0.00 AFTER balance;
+
+ALTER TABLE transactions
+    MODIFY COLUMN to_wallet_id BIGINT UNSIGNED NULL;
@@ -0,0 +1,9 @@
+ALTER TABLE transactions
+    DROP FOREIGN KEY fk_transactions_reversed_transaction_id,
+    DROP INDEX idx_transactions_reversed_transaction_id,
+    DROP COLUMN reversed_transaction_id;
+
+DELETE FROM transactions WHERE type = 'reversal';
+
+ALTER TABLE transactions
+    MODIFY COLUMN type ENUM('top_up', 'transfer', 'withdraw') NOT NULL;
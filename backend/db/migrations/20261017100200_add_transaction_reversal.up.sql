@@ -0,0 +1,5 @@
+ALTER TABLE transactions
+    MODIFY COLUMN type ENUM('top_up', 'transfer', 'withdraw', 'reversal') NOT NULL,
+    ADD COLUMN reversed_transaction_id BIGINT UNSIGNED NULL AFTER performed_by_user_id,
+    ADD INDEX idx_transactions_reversed_transaction_id (reversed_transaction_id),
+    ADD CONSTRAINT fk_transactions_reversed_transaction_id FOREIGN KEY (reversed_transaction_id) REFERENCES transactions(id) ON DELETE RESTRICT ON UPDATE CASCADE;
@@ -0,0 +1,30 @@
+-- Deleting the treasury user cascades into its wallet, its mutations and every transaction booked against it,
+-- so the migration refuses to run once the treasury has ledger history.
+DROP PROCEDURE IF EXISTS refuse_treasury_rollback;
+
+CREATE PROCEDURE refuse_treasury_rollback()
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM wallet_mutations m
+        JOIN wallets w ON w.id = m.wallet_id
+        WHERE w.type = 'treasury'
+    ) THEN
+        SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'The treasury wallet has ledger history, refusing to delete it';
+    END IF;
+END;
+
+CALL refuse_treasury_rollback();
+
+DROP PROCEDURE refuse_treasury_rollback;
+
+DELETE FROM users WHERE username = 'system_treasury' AND is_system = TRUE;
+
+ALTER TABLE transactions
+    DROP COLUMN legacy_issue;
+
+ALTER TABLE users
+    DROP COLUMN is_system;
+
+ALTER TABLE wallets
+    DROP INDEX idx_wallets_type,
+    DROP COLUMN type;
@@ -0,0 +1,46 @@
+ALTER TABLE wallets
+    ADD COLUMN type ENUM('user', 'treasury') NOT NULL DEFAULT 'user' AFTER user_id,
+    ADD INDEX idx_wallets_type (type);
+
+-- System accounts are owned by the application, nobody can log in as them and admins cannot manage them.
+ALTER TABLE users
+    ADD COLUMN is_system BOOLEAN NOT NULL DEFAULT FALSE AFTER role;
+
+-- System user owning the treasury wallet. The password is not a bcrypt hash either, so it never matches.
+INSERT INTO users (username, password, role, is_system) VALUES ('system_treasury', '!', 'user', TRUE);
+
+INSERT INTO wallets (user_id, type, balance)
+SELECT id, 'treasury', 0.00 FROM users WHERE username = 'system_treasury';
+
+-- Super admins used to send money from their own wallet without debiting it. Those transfers are kept as they are
+-- and marked instead, the money they issued is part of the opening balance booked below.
+ALTER TABLE transactions
+    ADD COLUMN legacy_issue BOOLEAN NOT NULL DEFAULT FALSE AFTER reversed_transaction_id;
+
+UPDATE transactions t
+SET t.legacy_issue = TRUE
+WHERE t.status = 'completed'
+  AND t.from_wallet_id IS NOT NULL
+  AND NOT EXISTS (
+      SELECT 1 FROM wallet_mutations m
+      WHERE m.transaction_id = t.id AND m.wallet_id = t.from_wallet_id AND m.type = 'debit'
+  );
+
+-- Money issued before the treasury existed is booked as one opening debit on the treasury,
+-- so that the sum of all wallet balances is zero from now on.
+INSERT INTO transactions (type, amount, from_wallet_id, to_wallet_id, performed_by_user_id, status, description)
+SELECT 'top_up', issued.total, w.id, NULL, w.user_id, 'completed', 'Treasury opening balance'
+FROM wallets w
+CROSS JOIN (SELECT COALESCE(SUM(balance), 0.00) AS total FROM wallets WHERE type = 'user') issued
+WHERE w.type = 'treasury' AND issued.total <> 0;
+
+INSERT INTO wallet_mutations (wallet_id, transaction_id, type, amount, balance_before, balance_after)
+SELECT t.from_wallet_id, t.id, 'debit', t.amount, 0.00, -t.amount
+FROM transactions t
+JOIN wallets w ON w.id = t.from_wallet_id AND w.type = 'treasury'
+WHERE t.description = 'Treasury opening balance';
+
+UPDATE wallets w
+JOIN wallet_mutations m ON m.wallet_id = w.id
+SET w.balance = m.balance_after
+WHERE w.type = 'treasury';
@@ -0,0 +1,2 @@
+ALTER TABLE wallets
+    ADD COLUMN version BIGINT UNSIGNED NOT NULL DEFAULT 0 AFTER held_balance;
@@ -0,0 +1,14 @@
+ALTER TABLE users
+    ADD COLUMN totp_secret VARCHAR(255) NOT NULL DEFAULT '' AFTER role,
+    ADD COLUMN totp_enabled_at TIMESTAMP NULL AFTER totp_secret,
+    ADD COLUMN totp_last_step BIGINT NOT NULL DEFAULT 0 AFTER totp_enabled_at;
+
+CREATE TABLE user_recovery_codes (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT UNSIGNED NOT NULL,
+    code_hash CHAR(64) NOT NULL,
+    used_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE INDEX uq_user_recovery_codes_user_code (user_id, code_hash),
+    CONSTRAINT fk_user_recovery_codes_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,2 @@
+ALTER TABLE users
+    ADD COLUMN transaction_pin VARCHAR(255) NOT NULL DEFAULT '' AFTER password;
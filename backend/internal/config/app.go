@@ -4,10 +4,13 @@ import (
 	"backend/internal/delivery/http"
 	"backend/internal/delivery/http/middleware"
 	"backend/internal/delivery/http/route"
+	"backend/internal/delivery/webhook"
 	"backend/internal/delivery/websocket"
 	"backend/internal/repository"
 	"backend/internal/usecase"
 	"backend/internal/util"
+	"backend/internal/worker"
+	"context"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
@@ -33,45 +36,150 @@ func Bootstrap(config *BootstrapConfig) {
 	walletRepository := repository.NewWalletRepository(config.Log)
 	transactionRepository := repository.NewTransactionRepository(config.Log)
 	walletMutationRepository := repository.NewWalletMutationRepository(config.Log)
+	idempotencyKeyRepository := repository.NewIdempotencyKeyRepository(config.Log)
+	outboxEventRepository := repository.NewOutboxEventRepository(config.Log)
+	webhookEndpointRepository := repository.NewWebhookEndpointRepository(config.Log)
+	webhookDeliveryRepository := repository.NewWebhookDeliveryRepository(config.Log)
+	notificationRepository := repository.NewNotificationRepository(config.Log)
+	userRecoveryCodeRepository := repository.NewUserRecoveryCodeRepository(config.Log)
+	auditLogRepository := repository.NewAuditLogRepository(config.Log)
 
 	// Utilities
-	tokenUtil := util.NewTokenUtil(config.Config.GetString("JWT_SECRET"), config.Redis)
+	tokenUtil := util.NewTokenUtil(config.Config.GetString("JWT_SECRET"), config.Redis, config.Config.GetDuration("token.access_ttl"), config.Config.GetDuration("token.refresh_ttl"), config.Config.GetDuration("token.password_reset_ttl"))
+	totpUtil := util.NewTOTPUtil(config.Config.GetString("two_factor.issuer"), totpEncryptionKey(config))
+	twoFactorRoles := config.Config.GetStringSlice("two_factor.required_roles")
+	pinAttemptLimiter := util.NewAttemptLimiter(config.Redis, "transaction_pin", config.Config.GetInt("transaction_pin.max_attempts"), config.Config.GetDuration("transaction_pin.lockout"))
+	loginUserLimiter := util.NewAttemptLimiter(config.Redis, "login_user", config.Config.GetInt("login.max_attempts"), config.Config.GetDuration("login.lockout")).
+		WithProgressiveDelay(config.Config.GetInt("login.delay_after"), config.Config.GetDuration("login.base_delay"))
+	loginIPLimiter := util.NewAttemptLimiter(config.Redis, "login_ip", config.Config.GetInt("login.ip_max_attempts"), config.Config.GetDuration("login.ip_lockout"))
+	passwordLimiter := util.NewAttemptLimiter(config.Redis, "password", config.Config.GetInt("password.max_attempts"), config.Config.GetDuration("password.lockout"))
+	twoFactorLimiter := util.NewAttemptLimiter(config.Redis, "two_factor", config.Config.GetInt("two_factor.max_attempts"), config.Config.GetDuration("two_factor.lockout"))
 
-	// WebSocket Hub and Handler
-	wsHub := websocket.NewHub(config.Log)
-	wsHandler := websocket.NewHandler(wsHub, tokenUtil, config.Log)
-	wsNotifier := websocket.NewNotifier(wsHub, config.Log)
+	// Notification inbox, written by the Notifier next to the live push
+	notificationUseCase := usecase.NewNotificationUseCase(config.DB, config.Log, config.Validator, notificationRepository)
+
+	// WebSocket Hub and Notifier
+	wsHub := newWebSocketHub(config)
+	go wsHub.Run(context.Background())
+	wsNotifier := websocket.NewNotifierWithInbox(wsHub, notificationUseCase, config.Log)
 
 	// Use Cases
-	userUseCase := usecase.NewUserUseCase(config.DB, config.Log, config.Validator, userRepository, walletRepository, tokenUtil)
+	userUseCase := usecase.NewUserUseCase(config.DB, config.Log, config.Validator, userRepository, walletRepository, tokenUtil, loginUserLimiter, loginIPLimiter, passwordLimiter, wsHub)
+	adminUserUseCase := usecase.NewAdminUserUseCase(config.DB, config.Log, config.Validator, userRepository, walletRepository, tokenUtil, wsHub)
+	auditLogUseCase := usecase.NewAuditLogUseCase(config.DB, config.Log, config.Validator, auditLogRepository)
+	sessionUseCase := usecase.NewSessionUseCase(config.Log, tokenUtil, wsHub)
+	twoFactorUseCase := usecase.NewTwoFactorUseCase(config.DB, config.Log, config.Validator, userRepository, userRecoveryCodeRepository, tokenUtil, totpUtil, twoFactorRoles, passwordLimiter, twoFactorLimiter, wsHub)
+	transactionPINUseCase := usecase.NewTransactionPINUseCase(config.DB, config.Log, config.Validator, userRepository, pinAttemptLimiter)
 	walletUseCase := usecase.NewWalletUseCase(config.DB, config.Log, config.Validator, walletRepository)
-	transactionUseCase := usecase.NewTransactionUseCase(config.DB, config.Log, config.Validator, transactionRepository, walletRepository, walletMutationRepository)
+	transactionUseCase := usecase.NewTransactionUseCase(config.DB, config.Log, config.Validator, transactionRepository, walletRepository, walletMutationRepository, idempotencyKeyRepository, outboxEventRepository, userRepository, transactionPINUseCase)
 	walletMutationUseCase := usecase.NewWalletMutationUseCase(config.DB, config.Log, config.Validator, walletMutationRepository, walletRepository)
+	reconciliationUseCase := usecase.NewReconciliationUseCase(config.DB, config.Log, config.Validator, walletRepository, walletMutationRepository, transactionRepository)
+	webhookUseCase := usecase.NewWebhookUseCase(config.DB, config.Log, config.Validator, webhookEndpointRepository, webhookDeliveryRepository)
+
+	// WebSocket Handler serves connections and client commands, the SSE Handler streams the same events
+	wsClientConfig := newWebSocketClientConfig(config)
+	wsHandler := websocket.NewHandler(wsHub, tokenUtil, userUseCase, walletUseCase, config.Validator, config.Log, wsClientConfig, twoFactorRoles)
+	sseHandler := websocket.NewSSEHandler(wsHub, config.Log, wsClientConfig)
+	wsHub.SetAdminFeedAuthorizer(wsHandler.AuthorizeAdminFeed, config.Config.GetDuration("websocket.admin_feed_recheck"))
 
-	// Set notifier for real-time notifications
-	transactionUseCase.SetNotifier(wsNotifier)
+	// Outbox dispatcher delivers real-time notifications written by the use cases and queues webhooks
+	outboxDispatcher := worker.NewOutboxDispatcher(config.DB, config.Log, config.Config, outboxEventRepository, wsNotifier, webhookUseCase)
+	go outboxDispatcher.Run(context.Background())
+
+	// Webhook dispatcher sends queued webhook deliveries
+	webhookSender := webhook.NewSender(config.Log, config.Config.GetDuration("webhook.timeout"))
+	webhookDispatcher := worker.NewWebhookDispatcher(config.DB, config.Log, config.Config, webhookDeliveryRepository, webhookSender)
+	go webhookDispatcher.Run(context.Background())
 
 	// Controllers
 	userController := http.NewUserController(config.Log, config.Config, userUseCase)
+	adminUserController := http.NewAdminUserController(config.Log, adminUserUseCase)
+	sessionController := http.NewSessionController(config.Log, sessionUseCase)
+	twoFactorController := http.NewTwoFactorController(config.Log, config.Config, twoFactorUseCase)
+	transactionPINController := http.NewTransactionPINController(config.Log, transactionPINUseCase)
 	walletController := http.NewWalletController(config.Log, walletUseCase)
 	transactionController := http.NewTransactionController(config.Log, transactionUseCase)
 	walletMutationController := http.NewWalletMutationController(config.Log, walletMutationUseCase)
+	reconciliationController := http.NewReconciliationController(config.Log, reconciliationUseCase)
+	webhookController := http.NewWebhookController(config.Log, webhookUseCase)
+	notificationController := http.NewNotificationController(config.Log, notificationUseCase)
 
 	// Middleware
 	app := config.App
 	app.Use(middleware.NewRateLimiter())
 
 	authMiddleware := middleware.NewAuth(userUseCase, tokenUtil)
+	twoFactorMiddleware := middleware.NewTwoFactorRequired(config.Log, twoFactorRoles)
+	requirePermission := middleware.NewPermission(config.Log)
+	audit := middleware.NewAudit(auditLogUseCase)
 
 	routeConfig := route.ConfigRoute{
 		App:                      config.App,
 		UserController:           userController,
+		AdminUserController:      adminUserController,
+		SessionController:        sessionController,
+		TwoFactorController:      twoFactorController,
+		TransactionPINController: transactionPINController,
 		WalletController:         walletController,
 		TransactionController:    transactionController,
 		WalletMutationController: walletMutationController,
+		ReconciliationController: reconciliationController,
+		WebhookController:        webhookController,
+		NotificationController:   notificationController,
 		WebSocketHandler:         wsHandler,
+		SSEHandler:               sseHandler,
 		AuthMiddleware:           authMiddleware,
+		TwoFactorMiddleware:      twoFactorMiddleware,
+		RequirePermission:        requirePermission,
+		Audit:                    audit,
 	}
 
 	routeConfig.Setup()
 }
+
+// totpEncryptionKey returns the key TOTP secrets are encrypted with.
+// Without TOTP_ENCRYPTION_KEY it falls back to JWT_SECRET, rotating that key then makes enrolled authenticators unusable.
+func totpEncryptionKey(config *BootstrapConfig) string {
+	if key := config.Config.GetString("TOTP_ENCRYPTION_KEY"); key != "" {
+		return key
+	}
+	config.Log.Warn("TOTP_ENCRYPTION_KEY is not set, TOTP secrets are encrypted with JWT_SECRET")
+	return config.Config.GetString("JWT_SECRET")
+}
+
+// newWebSocketHub creates the WebSocket Hub for the configured websocket.backend.
+// "redis" fans messages out over Redis pub/sub so every instance reaches its own connections,
+// "memory" keeps them in process and only works with a single instance.
+func newWebSocketHub(config *BootstrapConfig) *websocket.Hub {
+	replaySize := config.Config.GetInt("websocket.replay_size")
+	replayTTL := config.Config.GetDuration("websocket.replay_ttl")
+
+	switch backend := config.Config.GetString("websocket.backend"); backend {
+	case "redis":
+		broker := websocket.NewRedisBroker(config.Redis, config.Config.GetString("websocket.redis_channel"), config.Log)
+		eventLog := websocket.NewRedisEventLog(config.Redis, replaySize, replayTTL)
+		return websocket.NewHubWithBroker(config.Log, broker, eventLog)
+	case "memory":
+		if config.Config.GetBool("web.prefork") {
+			config.Log.Warn("WebSocket backend is memory while prefork is enabled, notifications only reach connections of the same process")
+		}
+		return websocket.NewHub(config.Log, websocket.NewMemoryEventLog(replaySize, replayTTL))
+	default:
+		config.Log.Fatalf("Unknown websocket.backend: %s", backend)
+		return nil
+	}
+}
+
+// newWebSocketClientConfig reads the send queue and heartbeat settings of WebSocket connections.
+func newWebSocketClientConfig(config *BootstrapConfig) websocket.ClientConfig {
+	clientConfig := websocket.ClientConfig{
+		SendQueueSize: config.Config.GetInt("websocket.send_queue_size"),
+		WriteWait:     config.Config.GetDuration("websocket.write_wait"),
+		PongWait:      config.Config.GetDuration("websocket.pong_wait"),
+		PingPeriod:    config.Config.GetDuration("websocket.ping_period"),
+	}
+	if clientConfig.PingPeriod <= 0 || clientConfig.PingPeriod >= clientConfig.PongWait {
+		config.Log.Fatalf("websocket.ping_period (%s) must be positive and shorter than websocket.pong_wait (%s)", clientConfig.PingPeriod, clientConfig.PongWait)
+	}
+	return clientConfig
+}
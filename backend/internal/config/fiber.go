@@ -1,7 +1,10 @@
 package config
 
 import (
+	"backend/internal/usecase"
 	"errors"
+	"math"
+	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -21,7 +24,7 @@ func NewFiber(viper *viper.Viper) *fiber.App {
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     viper.GetString("CORS_ORIGIN"),
 		AllowMethods:     "GET,POST,PUT,DELETE,OPTIONS",
-		AllowHeaders:     "Origin,Content-Type,Accept,Authorization",
+		AllowHeaders:     "Origin,Content-Type,Accept,Authorization,Idempotency-Key",
 		AllowCredentials: true,
 	}))
 
@@ -40,8 +43,20 @@ func NewErrorHandler() fiber.ErrorHandler {
 			code = e.Code
 		}
 
-		return ctx.Status(code).JSON(fiber.Map{
+		response := fiber.Map{
 			"errors": err.Error(),
-		})
+		}
+
+		// Coded errors tell the client what happened and, when throttled, how long to wait
+		if codedErr, ok := usecase.AsCodedError(err); ok {
+			response["code"] = codedErr.ErrorCode
+			if codedErr.RetryAfter > 0 {
+				retryAfter := int(math.Ceil(codedErr.RetryAfter.Seconds()))
+				ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+				response["retry_after"] = retryAfter
+			}
+		}
+
+		return ctx.Status(code).JSON(response)
 	}
 }
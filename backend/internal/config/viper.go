@@ -20,10 +20,51 @@ func NewViper() *viper.Viper {
 	config.SetDefault("cookie.secure", true)
 	config.SetDefault("log.level", 7)
 
+	config.SetDefault("token.access_ttl", "15m")
+	config.SetDefault("token.refresh_ttl", "720h")
+	config.SetDefault("token.password_reset_ttl", "1h")
+
+	config.SetDefault("two_factor.issuer", "Wallet App")
+	config.SetDefault("two_factor.required_roles", []string{"super_admin"})
+	config.SetDefault("two_factor.max_attempts", 5)
+	config.SetDefault("two_factor.lockout", "15m")
+
+	config.SetDefault("transaction_pin.max_attempts", 5)
+	config.SetDefault("transaction_pin.lockout", "15m")
+
+	config.SetDefault("login.max_attempts", 10)
+	config.SetDefault("login.lockout", "15m")
+	config.SetDefault("login.delay_after", 3)
+	config.SetDefault("login.base_delay", "1s")
+	config.SetDefault("login.ip_max_attempts", 50)
+	config.SetDefault("login.ip_lockout", "15m")
+
+	config.SetDefault("password.max_attempts", 5)
+	config.SetDefault("password.lockout", "15m")
+
 	config.SetDefault("database.pool.idle", 10)
 	config.SetDefault("database.pool.max", 100)
 	config.SetDefault("database.pool.lifetime", 300)
 
+	config.SetDefault("outbox.interval", "500ms")
+	config.SetDefault("outbox.batch_size", 100)
+	config.SetDefault("outbox.max_attempts", 10)
+
+	config.SetDefault("webhook.interval", "1s")
+	config.SetDefault("webhook.batch_size", 20)
+	config.SetDefault("webhook.max_attempts", 8)
+	config.SetDefault("webhook.timeout", "10s")
+
+	config.SetDefault("websocket.backend", "memory")
+	config.SetDefault("websocket.redis_channel", "websocket:broadcast")
+	config.SetDefault("websocket.replay_size", 100)
+	config.SetDefault("websocket.replay_ttl", "24h")
+	config.SetDefault("websocket.send_queue_size", 64)
+	config.SetDefault("websocket.write_wait", "10s")
+	config.SetDefault("websocket.pong_wait", "60s")
+	config.SetDefault("websocket.ping_period", "54s")
+	config.SetDefault("websocket.admin_feed_recheck", "30s")
+
 	// Read config.json
 	config.SetConfigName("config")
 	config.SetConfigType("json")
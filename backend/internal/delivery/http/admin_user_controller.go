@@ -0,0 +1,157 @@
+package http
+
+import (
+	"backend/internal/delivery/http/middleware"
+	"backend/internal/model"
+	"backend/internal/usecase"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type AdminUserController struct {
+	Log              *logrus.Logger
+	AdminUserUseCase usecase.AdminUserUseCaseInterface
+}
+
+func NewAdminUserController(log *logrus.Logger, adminUserUseCase usecase.AdminUserUseCaseInterface) *AdminUserController {
+	return &AdminUserController{
+		Log:              log,
+		AdminUserUseCase: adminUserUseCase,
+	}
+}
+
+// Search lists users, filtered by the query, role and disabled query parameters.
+func (ac *AdminUserController) Search(ctx *fiber.Ctx) error {
+	page, _ := strconv.Atoi(ctx.Query("page", "1"))
+	limit, _ := strconv.Atoi(ctx.Query("limit", "10"))
+	request := &model.AdminUserSearchRequest{
+		Query: ctx.Query("query"),
+		Role:  ctx.Query("role"),
+		Page:  page,
+		Limit: limit,
+	}
+	if disabledParam := ctx.Query("disabled"); disabledParam != "" {
+		disabled, err := strconv.ParseBool(disabledParam)
+		if err != nil {
+			ac.Log.Warnf("Invalid disabled filter: %s", disabledParam)
+			return fiber.NewError(fiber.StatusBadRequest, "disabled must be true or false")
+		}
+		request.Disabled = &disabled
+	}
+
+	response, err := ac.AdminUserUseCase.Search(ctx.UserContext(), request)
+	if err != nil {
+		ac.Log.Warnf("AdminUserUseCase.Search error: %v", err)
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": response,
+	})
+}
+
+// Get returns a user with their wallet.
+func (ac *AdminUserController) Get(ctx *fiber.Ctx) error {
+	userID, err := ac.userID(ctx)
+	if err != nil {
+		return err
+	}
+
+	response, err := ac.AdminUserUseCase.Get(ctx.UserContext(), userID)
+	if err != nil {
+		ac.Log.Warnf("AdminUserUseCase.Get error: %v", err)
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": response,
+	})
+}
+
+// ChangeRole gives a user another role.
+func (ac *AdminUserController) ChangeRole(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+	userID, err := ac.userID(ctx)
+	if err != nil {
+		return err
+	}
+
+	request := new(model.ChangeRoleRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		ac.Log.Warnf("BodyParser error: %v", err)
+		return fiber.ErrBadRequest
+	}
+
+	response, err := ac.AdminUserUseCase.ChangeRole(ctx.UserContext(), auth, userID, request)
+	if err != nil {
+		ac.Log.Warnf("AdminUserUseCase.ChangeRole error: %v", err)
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": response,
+	})
+}
+
+// Disable disables a user account.
+func (ac *AdminUserController) Disable(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+	userID, err := ac.userID(ctx)
+	if err != nil {
+		return err
+	}
+
+	response, err := ac.AdminUserUseCase.Disable(ctx.UserContext(), auth, userID)
+	if err != nil {
+		ac.Log.Warnf("AdminUserUseCase.Disable error: %v", err)
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": response,
+	})
+}
+
+// Enable enables a disabled user account.
+func (ac *AdminUserController) Enable(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+	userID, err := ac.userID(ctx)
+	if err != nil {
+		return err
+	}
+
+	response, err := ac.AdminUserUseCase.Enable(ctx.UserContext(), auth, userID)
+	if err != nil {
+		ac.Log.Warnf("AdminUserUseCase.Enable error: %v", err)
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": response,
+	})
+}
+
+// ForceLogout ends every session of a user.
+func (ac *AdminUserController) ForceLogout(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+	userID, err := ac.userID(ctx)
+	if err != nil {
+		return err
+	}
+
+	response, err := ac.AdminUserUseCase.ForceLogout(ctx.UserContext(), auth, userID)
+	if err != nil {
+		ac.Log.Warnf("AdminUserUseCase.ForceLogout error: %v", err)
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": response,
+	})
+}
+
+// userID parses the user ID path parameter.
+func (ac *AdminUserController) userID(ctx *fiber.Ctx) (uint, error) {
+	userID, err := ctx.ParamsInt("id")
+	if err != nil || userID <= 0 {
+		ac.Log.Warnf("Invalid user ID: %s", ctx.Params("id"))
+		return 0, fiber.ErrBadRequest
+	}
+	return uint(userID), nil
+}
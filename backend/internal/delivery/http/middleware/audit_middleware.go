@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"backend/internal/model"
+	"backend/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// NewAudit creates the factory of audit middlewares, so routes declare what they record:
+//
+//	auth.Get("/admin/users/:id/transactions", audit(entity.AuditActionTransactionsView, entity.AuditTargetUser), handler)
+//
+// The returned middlewares record the access to the record named by the id path parameter before the
+// handler runs. When the entry cannot be stored the request fails, nothing is read without a trace.
+// They run after the auth middleware.
+func NewAudit(auditLogUseCase usecase.AuditLogUseCaseInterface) func(action string, targetType string) fiber.Handler {
+	return func(action string, targetType string) fiber.Handler {
+		return func(ctx *fiber.Ctx) error {
+			targetID, err := ctx.ParamsInt("id")
+			if err != nil || targetID <= 0 {
+				return fiber.ErrBadRequest
+			}
+
+			auth := GetUser(ctx)
+			if err := auditLogUseCase.Record(ctx.UserContext(), &model.AuditLogRequest{
+				ActorUserID: *auth.UserID,
+				ActorRole:   auth.Role,
+				Action:      action,
+				TargetType:  targetType,
+				TargetID:    uint(targetID),
+				IPAddress:   ctx.IP(),
+				UserAgent:   ctx.Get(fiber.HeaderUserAgent),
+			}); err != nil {
+				return err
+			}
+			return ctx.Next()
+		}
+	}
+}
@@ -48,7 +48,20 @@ func NewAuth(userUseCase *usecase.UserUseCase, tokenUtil *util.TokenUtil) fiber.
 			return fiber.ErrUnauthorized
 		}
 
+		// 5. Tolak akun yang sudah dihapus atau dinonaktifkan admin
+		if err := userUseCase.CheckActive(ctx.UserContext(), *auth.UserID); err != nil {
+			return err
+		}
+
+		// 6. Catat aktivitas session untuk daftar perangkat
+		if auth.FamilyID != "" {
+			if err := tokenUtil.TouchSession(ctx.UserContext(), auth.FamilyID, ctx.IP()); err != nil {
+				userUseCase.Log.Warnf("TouchSession error: %v", err)
+			}
+		}
+
 		ctx.Locals("auth", auth)
+		ctx.Locals("token", tokenString)
 		return ctx.Next()
 	}
 }
@@ -57,6 +70,12 @@ func GetUser(ctx *fiber.Ctx) *model.Auth {
 	return ctx.Locals("auth").(*model.Auth)
 }
 
+// GetToken returns the raw access token the request was authenticated with.
+func GetToken(ctx *fiber.Ctx) string {
+	token, _ := ctx.Locals("token").(string)
+	return token
+}
+
 func getLastCookie(c *fiber.Ctx, name string) string {
 	cookieHeader := c.Get("Cookie")
 	var lastValue string
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"backend/internal/entity"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// NewPermission creates the factory of permission middlewares, so routes declare what they need:
+//
+//	auth.Post("/transactions/topup", requirePermission(entity.PermissionTopUpCreate), handler)
+//
+// The returned middlewares reject sessions whose role does not grant the permission.
+// They run after the auth middleware.
+func NewPermission(log *logrus.Logger) func(permission entity.Permission) fiber.Handler {
+	return func(permission entity.Permission) fiber.Handler {
+		return func(ctx *fiber.Ctx) error {
+			auth := GetUser(ctx)
+			if !entity.RoleHasPermission(auth.Role, permission) {
+				log.Warnf("User ID %d with role %s lacks permission %s, access to %s denied", *auth.UserID, auth.Role, permission, ctx.Path())
+				return fiber.NewError(fiber.StatusForbidden, "You do not have permission to perform this action")
+			}
+			return ctx.Next()
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// NewTwoFactorRequired creates a middleware that rejects sessions of the given roles
+// that were not started with two-factor authentication. It runs after the auth middleware.
+func NewTwoFactorRequired(log *logrus.Logger, roles []string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		auth := GetUser(ctx)
+		if auth.MissingTwoFactor(roles) {
+			log.Warnf("User ID %d with role %s has no two-factor authentication, access to %s denied", *auth.UserID, auth.Role, ctx.Path())
+			return fiber.NewError(fiber.StatusForbidden, "Two-factor authentication is required for your role, enable it at /users/me/2fa")
+		}
+		return ctx.Next()
+	}
+}
@@ -0,0 +1,106 @@
+package http
+
+import (
+	"backend/internal/delivery/http/middleware"
+	"backend/internal/model"
+	"backend/internal/usecase"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type NotificationController struct {
+	Log                 *logrus.Logger
+	NotificationUseCase usecase.NotificationUseCaseInterface
+}
+
+func NewNotificationController(log *logrus.Logger, notificationUseCase usecase.NotificationUseCaseInterface) *NotificationController {
+	return &NotificationController{
+		Log:                 log,
+		NotificationUseCase: notificationUseCase,
+	}
+}
+
+// List returns the notification inbox of the current user, optionally only unread notifications.
+func (nc *NotificationController) List(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+	page, _ := strconv.Atoi(ctx.Query("page", "1"))
+	limit, _ := strconv.Atoi(ctx.Query("limit", "10"))
+	unreadOnly := ctx.QueryBool("unread", false)
+	response, err := nc.NotificationUseCase.List(ctx.UserContext(), auth, unreadOnly, page, limit)
+	if err != nil {
+		nc.Log.Warnf("NotificationUseCase.List error: %v", err)
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": response,
+	})
+}
+
+// CountUnread returns how many notifications of the current user are unread.
+func (nc *NotificationController) CountUnread(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+	response, err := nc.NotificationUseCase.CountUnread(ctx.UserContext(), auth)
+	if err != nil {
+		nc.Log.Warnf("NotificationUseCase.CountUnread error: %v", err)
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": response,
+	})
+}
+
+// MarkRead marks a notification of the current user read.
+func (nc *NotificationController) MarkRead(ctx *fiber.Ctx) error {
+	return nc.mark(ctx, true)
+}
+
+// MarkUnread marks a notification of the current user unread.
+func (nc *NotificationController) MarkUnread(ctx *fiber.Ctx) error {
+	return nc.mark(ctx, false)
+}
+
+// MarkAllRead marks the given notifications, or all of them, of the current user read.
+func (nc *NotificationController) MarkAllRead(ctx *fiber.Ctx) error {
+	return nc.markBulk(ctx, true)
+}
+
+// MarkAllUnread marks the given notifications, or all of them, of the current user unread.
+func (nc *NotificationController) MarkAllUnread(ctx *fiber.Ctx) error {
+	return nc.markBulk(ctx, false)
+}
+
+func (nc *NotificationController) mark(ctx *fiber.Ctx, read bool) error {
+	auth := middleware.GetUser(ctx)
+	notificationID, err := ctx.ParamsInt("id")
+	if err != nil || notificationID <= 0 {
+		nc.Log.Warnf("Invalid notification ID: %s", ctx.Params("id"))
+		return fiber.ErrBadRequest
+	}
+	response, err := nc.NotificationUseCase.Mark(ctx.UserContext(), auth, uint(notificationID), read)
+	if err != nil {
+		nc.Log.Warnf("NotificationUseCase.Mark error: %v", err)
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": response,
+	})
+}
+
+func (nc *NotificationController) markBulk(ctx *fiber.Ctx, read bool) error {
+	auth := middleware.GetUser(ctx)
+	request := new(model.MarkNotificationsRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		nc.Log.Warnf("BodyParser error: %v", err)
+		return fiber.ErrBadRequest
+	}
+	response, err := nc.NotificationUseCase.MarkBulk(ctx.UserContext(), auth, request, read)
+	if err != nil {
+		nc.Log.Warnf("NotificationUseCase.MarkBulk error: %v", err)
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": response,
+	})
+}
@@ -0,0 +1,34 @@
+package http
+
+import (
+	"backend/internal/delivery/http/middleware"
+	"backend/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type ReconciliationController struct {
+	Log                   *logrus.Logger
+	ReconciliationUseCase usecase.ReconciliationUseCaseInterface
+}
+
+func NewReconciliationController(log *logrus.Logger, reconciliationUseCase usecase.ReconciliationUseCaseInterface) *ReconciliationController {
+	return &ReconciliationController{
+		Log:                   log,
+		ReconciliationUseCase: reconciliationUseCase,
+	}
+}
+
+// GetReport runs the ledger reconciliation and returns the discrepancy report.
+func (rc *ReconciliationController) GetReport(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+	response, err := rc.ReconciliationUseCase.GetReport(ctx.UserContext(), auth)
+	if err != nil {
+		rc.Log.Warnf("ReconciliationUseCase.GetReport error: %v", err)
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": response,
+	})
+}
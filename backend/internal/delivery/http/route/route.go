@@ -3,18 +3,31 @@ package route
 import (
 	"backend/internal/delivery/http"
 	"backend/internal/delivery/websocket"
+	"backend/internal/entity"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/expvar"
 )
 
 type ConfigRoute struct {
 	App                      *fiber.App
 	UserController           *http.UserController
+	AdminUserController      *http.AdminUserController
+	SessionController        *http.SessionController
+	TwoFactorController      *http.TwoFactorController
+	TransactionPINController *http.TransactionPINController
 	WalletController         *http.WalletController
 	TransactionController    *http.TransactionController
 	WalletMutationController *http.WalletMutationController
+	ReconciliationController *http.ReconciliationController
+	WebhookController        *http.WebhookController
+	NotificationController   *http.NotificationController
 	WebSocketHandler         *websocket.Handler
+	SSEHandler               *websocket.SSEHandler
 	AuthMiddleware           fiber.Handler
+	TwoFactorMiddleware      fiber.Handler
+	RequirePermission        func(permission entity.Permission) fiber.Handler
+	Audit                    func(action string, targetType string) fiber.Handler
 }
 
 // Setup sets up the main routes for the application.
@@ -35,6 +48,9 @@ func (cr *ConfigRoute) SetupGuestRoutes() {
 	// User routes (public)
 	cr.App.Post("/users/register", cr.UserController.Register)
 	cr.App.Post("/users/login", cr.UserController.Login)
+	cr.App.Post("/users/login/2fa", cr.TwoFactorController.Login)
+	cr.App.Post("/users/refresh", cr.UserController.Refresh)
+	cr.App.Post("/users/password-reset", cr.UserController.ResetPassword)
 }
 
 func (cr *ConfigRoute) SetupAuthRoutes() {
@@ -45,16 +61,84 @@ func (cr *ConfigRoute) SetupAuthRoutes() {
 	auth.Post("/users/logout", cr.UserController.Logout)
 	auth.Get("/users/me", cr.UserController.GetProfile)
 
+	// Two-factor enrollment, reachable before two-factor authentication is enabled
+	auth.Get("/users/me/2fa", cr.TwoFactorController.Status)
+	auth.Post("/users/me/2fa/setup", cr.TwoFactorController.Setup)
+	auth.Post("/users/me/2fa/confirm", cr.TwoFactorController.Confirm)
+	auth.Post("/users/me/2fa/recovery-codes", cr.TwoFactorController.RegenerateRecoveryCodes)
+	auth.Post("/users/me/2fa/disable", cr.TwoFactorController.Disable)
+
+	// Routes below are closed to roles that require two-factor authentication until it is enabled
+	if cr.TwoFactorMiddleware != nil {
+		auth = auth.Group("", cr.TwoFactorMiddleware)
+	}
+
+	auth.Put("/users/me/password", cr.UserController.ChangePassword)
+
+	// Transaction PIN routes
+	auth.Get("/users/me/pin", cr.TransactionPINController.Status)
+	auth.Post("/users/me/pin", cr.TransactionPINController.Set)
+	auth.Put("/users/me/pin", cr.TransactionPINController.Change)
+	auth.Post("/users/me/pin/reset", cr.TransactionPINController.Reset)
+
+	// Session routes
+	auth.Get("/users/sessions", cr.SessionController.List)
+	auth.Delete("/users/sessions/others", cr.SessionController.RevokeOthers)
+	auth.Delete("/users/sessions/:id", cr.SessionController.Revoke)
+
 	// Wallet routes
 	auth.Get("/wallets/me", cr.WalletController.GetMyWallet)
 
 	// Transaction routes
-	auth.Post("/transactions/topup", cr.TransactionController.TopUp)
+	auth.Post("/transactions/topup", cr.RequirePermission(entity.PermissionTopUpCreate), cr.TransactionController.TopUp)
 	auth.Post("/transactions/transfer", cr.TransactionController.Transfer)
+	auth.Post("/transactions/withdraw", cr.TransactionController.Withdraw)
+	auth.Post("/transactions/:id/settle", cr.RequirePermission(entity.PermissionTransactionsSettle), cr.TransactionController.SettleWithdraw)
+	auth.Post("/transactions/:id/reverse", cr.RequirePermission(entity.PermissionTransactionsReverse), cr.TransactionController.Reverse)
 	auth.Get("/transactions", cr.TransactionController.GetMyTransactions)
 
 	// Wallet Mutation routes
 	auth.Get("/wallet-mutations", cr.WalletMutationController.GetMyMutations)
+
+	// Webhook routes
+	auth.Post("/webhooks", cr.WebhookController.Create)
+	auth.Get("/webhooks", cr.WebhookController.List)
+	auth.Delete("/webhooks/:id", cr.WebhookController.Delete)
+	auth.Get("/webhooks/:id/deliveries", cr.WebhookController.GetDeliveries)
+	auth.Post("/webhooks/:id/deliveries/:deliveryId/redeliver", cr.WebhookController.Redeliver)
+
+	// Notification inbox routes
+	auth.Get("/notifications", cr.NotificationController.List)
+	auth.Get("/notifications/unread-count", cr.NotificationController.CountUnread)
+	auth.Post("/notifications/read", cr.NotificationController.MarkAllRead)
+	auth.Post("/notifications/unread", cr.NotificationController.MarkAllUnread)
+	auth.Post("/notifications/:id/read", cr.NotificationController.MarkRead)
+	auth.Post("/notifications/:id/unread", cr.NotificationController.MarkUnread)
+
+	// Real-time event stream (Server-Sent Events), for clients that cannot use /ws
+	if cr.SSEHandler != nil {
+		auth.Get("/events", cr.SSEHandler.Stream)
+	}
+
+	// Admin routes
+	auth.Get("/admin/reconciliation", cr.RequirePermission(entity.PermissionReconciliationRead), cr.ReconciliationController.GetReport)
+	auth.Get("/admin/users", cr.RequirePermission(entity.PermissionUsersRead), cr.AdminUserController.Search)
+	auth.Get("/admin/users/:id", cr.RequirePermission(entity.PermissionUsersRead), cr.AdminUserController.Get)
+	auth.Put("/admin/users/:id/role", cr.RequirePermission(entity.PermissionUsersManageRoles), cr.AdminUserController.ChangeRole)
+	auth.Post("/admin/users/:id/disable", cr.RequirePermission(entity.PermissionUsersDisable), cr.AdminUserController.Disable)
+	auth.Post("/admin/users/:id/enable", cr.RequirePermission(entity.PermissionUsersDisable), cr.AdminUserController.Enable)
+	auth.Post("/admin/users/:id/logout", cr.RequirePermission(entity.PermissionUsersLogout), cr.AdminUserController.ForceLogout)
+	auth.Post("/admin/users/:id/password-reset", cr.RequirePermission(entity.PermissionUsersResetPassword), cr.UserController.IssuePasswordReset)
+	auth.Post("/admin/users/:id/unlock", cr.RequirePermission(entity.PermissionUsersUnlock), cr.UserController.Unlock)
+
+	// Admin history routes, every access is recorded in the audit log
+	auth.Get("/admin/users/:id/transactions", cr.RequirePermission(entity.PermissionTransactionsRead), cr.Audit(entity.AuditActionTransactionsView, entity.AuditTargetUser), cr.TransactionController.GetUserTransactions)
+	auth.Get("/admin/users/:id/wallet-mutations", cr.RequirePermission(entity.PermissionWalletMutationsRead), cr.Audit(entity.AuditActionWalletMutationsView, entity.AuditTargetUser), cr.WalletMutationController.GetUserMutations)
+	auth.Get("/admin/wallets/:id/transactions", cr.RequirePermission(entity.PermissionTransactionsRead), cr.Audit(entity.AuditActionTransactionsView, entity.AuditTargetWallet), cr.TransactionController.GetWalletTransactions)
+	auth.Get("/admin/wallets/:id/wallet-mutations", cr.RequirePermission(entity.PermissionWalletMutationsRead), cr.Audit(entity.AuditActionWalletMutationsView, entity.AuditTargetWallet), cr.WalletMutationController.GetWalletMutations)
+
+	// Runtime metrics (transaction retries, memstats)
+	auth.Get("/debug/vars", cr.RequirePermission(entity.PermissionMetricsRead), expvar.New())
 }
 
 // SetupWebSocketRoutes sets up WebSocket routes for real-time features.
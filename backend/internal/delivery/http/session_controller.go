@@ -0,0 +1,64 @@
+package http
+
+import (
+	"backend/internal/delivery/http/middleware"
+	"backend/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type SessionController struct {
+	Log            *logrus.Logger
+	SessionUseCase usecase.SessionUseCaseInterface
+}
+
+func NewSessionController(log *logrus.Logger, sessionUseCase usecase.SessionUseCaseInterface) *SessionController {
+	return &SessionController{
+		Log:            log,
+		SessionUseCase: sessionUseCase,
+	}
+}
+
+// List returns the active sessions of the current user.
+func (sc *SessionController) List(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+	response, err := sc.SessionUseCase.List(ctx.UserContext(), auth)
+	if err != nil {
+		sc.Log.Warnf("SessionUseCase.List error: %v", err)
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": response,
+	})
+}
+
+// Revoke ends a session of the current user.
+func (sc *SessionController) Revoke(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+	sessionID := ctx.Params("id")
+	if sessionID == "" || len(sessionID) > 64 {
+		sc.Log.Warnf("Invalid session ID: %s", sessionID)
+		return fiber.ErrBadRequest
+	}
+	if err := sc.SessionUseCase.Revoke(ctx.UserContext(), auth, sessionID); err != nil {
+		sc.Log.Warnf("SessionUseCase.Revoke error: %v", err)
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Session revoked",
+	})
+}
+
+// RevokeOthers ends every session of the current user except the one making the request.
+func (sc *SessionController) RevokeOthers(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+	response, err := sc.SessionUseCase.RevokeOthers(ctx.UserContext(), auth)
+	if err != nil {
+		sc.Log.Warnf("SessionUseCase.RevokeOthers error: %v", err)
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": response,
+	})
+}
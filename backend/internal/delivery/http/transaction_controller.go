@@ -28,6 +28,7 @@ func (tc *TransactionController) TopUp(ctx *fiber.Ctx) error {
 		tc.Log.Warnf("BodyParser error: %v", err)
 		return fiber.ErrBadRequest
 	}
+	request.IdempotencyKey = ctx.Get("Idempotency-Key")
 	response, err := tc.TransactionUseCase.TopUp(ctx.UserContext(), auth, request)
 	if err != nil {
 		tc.Log.Warnf("TransactionUseCase.TopUp error: %v", err)
@@ -44,6 +45,7 @@ func (tc *TransactionController) Transfer(ctx *fiber.Ctx) error {
 		tc.Log.Warnf("BodyParser error: %v", err)
 		return fiber.ErrBadRequest
 	}
+	request.IdempotencyKey = ctx.Get("Idempotency-Key")
 	response, err := tc.TransactionUseCase.Transfer(ctx.UserContext(), auth, request)
 	if err != nil {
 		tc.Log.Warnf("TransactionUseCase.Transfer error: %v", err)
@@ -53,6 +55,67 @@ func (tc *TransactionController) Transfer(ctx *fiber.Ctx) error {
 		"data": response,
 	})
 }
+func (tc *TransactionController) Withdraw(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+	request := new(model.WithdrawRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		tc.Log.Warnf("BodyParser error: %v", err)
+		return fiber.ErrBadRequest
+	}
+	request.IdempotencyKey = ctx.Get("Idempotency-Key")
+	response, err := tc.TransactionUseCase.Withdraw(ctx.UserContext(), auth, request)
+	if err != nil {
+		tc.Log.Warnf("TransactionUseCase.Withdraw error: %v", err)
+		return err
+	}
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"data": response,
+	})
+}
+func (tc *TransactionController) SettleWithdraw(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+	request := new(model.SettleWithdrawRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		tc.Log.Warnf("BodyParser error: %v", err)
+		return fiber.ErrBadRequest
+	}
+	transactionID, err := ctx.ParamsInt("id")
+	if err != nil || transactionID <= 0 {
+		tc.Log.Warnf("Invalid transaction ID: %s", ctx.Params("id"))
+		return fiber.ErrBadRequest
+	}
+	request.TransactionID = uint(transactionID)
+	response, err := tc.TransactionUseCase.SettleWithdraw(ctx.UserContext(), auth, request)
+	if err != nil {
+		tc.Log.Warnf("TransactionUseCase.SettleWithdraw error: %v", err)
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": response,
+	})
+}
+func (tc *TransactionController) Reverse(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+	request := new(model.ReverseTransactionRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		tc.Log.Warnf("BodyParser error: %v", err)
+		return fiber.ErrBadRequest
+	}
+	transactionID, err := ctx.ParamsInt("id")
+	if err != nil || transactionID <= 0 {
+		tc.Log.Warnf("Invalid transaction ID: %s", ctx.Params("id"))
+		return fiber.ErrBadRequest
+	}
+	request.TransactionID = uint(transactionID)
+	response, err := tc.TransactionUseCase.Reverse(ctx.UserContext(), auth, request)
+	if err != nil {
+		tc.Log.Warnf("TransactionUseCase.Reverse error: %v", err)
+		return err
+	}
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"data": response,
+	})
+}
 func (tc *TransactionController) GetMyTransactions(ctx *fiber.Ctx) error {
 	auth := middleware.GetUser(ctx)
 	page, _ := strconv.Atoi(ctx.Query("page", "1"))
@@ -66,3 +129,41 @@ func (tc *TransactionController) GetMyTransactions(ctx *fiber.Ctx) error {
 		"data": response,
 	})
 }
+
+// GetUserTransactions returns the transactions of any user's wallet, for staff.
+func (tc *TransactionController) GetUserTransactions(ctx *fiber.Ctx) error {
+	userID, err := ctx.ParamsInt("id")
+	if err != nil || userID <= 0 {
+		tc.Log.Warnf("Invalid user ID: %s", ctx.Params("id"))
+		return fiber.ErrBadRequest
+	}
+	page, _ := strconv.Atoi(ctx.Query("page", "1"))
+	limit, _ := strconv.Atoi(ctx.Query("limit", "10"))
+	response, err := tc.TransactionUseCase.GetTransactionsByUserID(ctx.UserContext(), uint(userID), page, limit)
+	if err != nil {
+		tc.Log.Warnf("TransactionUseCase.GetTransactionsByUserID error: %v", err)
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": response,
+	})
+}
+
+// GetWalletTransactions returns the transactions of any wallet, for staff.
+func (tc *TransactionController) GetWalletTransactions(ctx *fiber.Ctx) error {
+	walletID, err := ctx.ParamsInt("id")
+	if err != nil || walletID <= 0 {
+		tc.Log.Warnf("Invalid wallet ID: %s", ctx.Params("id"))
+		return fiber.ErrBadRequest
+	}
+	page, _ := strconv.Atoi(ctx.Query("page", "1"))
+	limit, _ := strconv.Atoi(ctx.Query("limit", "10"))
+	response, err := tc.TransactionUseCase.GetTransactionsByWalletID(ctx.UserContext(), uint(walletID), page, limit)
+	if err != nil {
+		tc.Log.Warnf("TransactionUseCase.GetTransactionsByWalletID error: %v", err)
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": response,
+	})
+}
@@ -0,0 +1,89 @@
+package http
+
+import (
+	"backend/internal/delivery/http/middleware"
+	"backend/internal/model"
+	"backend/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type TransactionPINController struct {
+	Log                   *logrus.Logger
+	TransactionPINUseCase usecase.TransactionPINUseCaseInterface
+}
+
+func NewTransactionPINController(log *logrus.Logger, transactionPINUseCase usecase.TransactionPINUseCaseInterface) *TransactionPINController {
+	return &TransactionPINController{
+		Log:                   log,
+		TransactionPINUseCase: transactionPINUseCase,
+	}
+}
+
+// Status returns whether the current user set a transaction PIN and whether it is locked.
+func (pc *TransactionPINController) Status(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+	response, err := pc.TransactionPINUseCase.Status(ctx.UserContext(), auth)
+	if err != nil {
+		pc.Log.Warnf("TransactionPINUseCase.Status error: %v", err)
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": response,
+	})
+}
+
+// Set sets the first transaction PIN of the current user.
+func (pc *TransactionPINController) Set(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+	request := new(model.TransactionPINRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		pc.Log.Warnf("BodyParser error: %v", err)
+		return fiber.ErrBadRequest
+	}
+
+	if err := pc.TransactionPINUseCase.Set(ctx.UserContext(), auth, request); err != nil {
+		pc.Log.Warnf("TransactionPINUseCase.Set error: %v", err)
+		return err
+	}
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Transaction PIN set",
+	})
+}
+
+// Change replaces the transaction PIN of the current user with the current PIN.
+func (pc *TransactionPINController) Change(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+	request := new(model.ChangeTransactionPINRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		pc.Log.Warnf("BodyParser error: %v", err)
+		return fiber.ErrBadRequest
+	}
+
+	if err := pc.TransactionPINUseCase.Change(ctx.UserContext(), auth, request); err != nil {
+		pc.Log.Warnf("TransactionPINUseCase.Change error: %v", err)
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Transaction PIN changed",
+	})
+}
+
+// Reset replaces a forgotten transaction PIN of the current user with the account password.
+func (pc *TransactionPINController) Reset(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+	request := new(model.TransactionPINRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		pc.Log.Warnf("BodyParser error: %v", err)
+		return fiber.ErrBadRequest
+	}
+
+	if err := pc.TransactionPINUseCase.Reset(ctx.UserContext(), auth, request); err != nil {
+		pc.Log.Warnf("TransactionPINUseCase.Reset error: %v", err)
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Transaction PIN reset",
+	})
+}
@@ -0,0 +1,129 @@
+package http
+
+import (
+	"backend/internal/delivery/http/middleware"
+	"backend/internal/model"
+	"backend/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+type TwoFactorController struct {
+	Log              *logrus.Logger
+	Config           *viper.Viper
+	TwoFactorUseCase usecase.TwoFactorUseCaseInterface
+}
+
+func NewTwoFactorController(log *logrus.Logger, config *viper.Viper, twoFactorUseCase usecase.TwoFactorUseCaseInterface) *TwoFactorController {
+	return &TwoFactorController{
+		Log:              log,
+		Config:           config,
+		TwoFactorUseCase: twoFactorUseCase,
+	}
+}
+
+// Status returns the two-factor authentication state of the current user.
+func (tc *TwoFactorController) Status(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+	response, err := tc.TwoFactorUseCase.Status(ctx.UserContext(), auth)
+	if err != nil {
+		tc.Log.Warnf("TwoFactorUseCase.Status error: %v", err)
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": response,
+	})
+}
+
+// Setup starts enrollment with a new TOTP secret for the authenticator app.
+func (tc *TwoFactorController) Setup(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+	response, err := tc.TwoFactorUseCase.Setup(ctx.UserContext(), auth)
+	if err != nil {
+		tc.Log.Warnf("TwoFactorUseCase.Setup error: %v", err)
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": response,
+	})
+}
+
+// Confirm finishes enrollment with a code from the authenticator app and returns the recovery codes.
+func (tc *TwoFactorController) Confirm(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+	request := new(model.TwoFactorCodeRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		tc.Log.Warnf("BodyParser error: %v", err)
+		return fiber.ErrBadRequest
+	}
+
+	response, err := tc.TwoFactorUseCase.Confirm(ctx.UserContext(), auth, request)
+	if err != nil {
+		tc.Log.Warnf("TwoFactorUseCase.Confirm error: %v", err)
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": response,
+	})
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the current user.
+func (tc *TwoFactorController) RegenerateRecoveryCodes(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+	request := new(model.TwoFactorCodeRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		tc.Log.Warnf("BodyParser error: %v", err)
+		return fiber.ErrBadRequest
+	}
+
+	response, err := tc.TwoFactorUseCase.RegenerateRecoveryCodes(ctx.UserContext(), auth, request)
+	if err != nil {
+		tc.Log.Warnf("TwoFactorUseCase.RegenerateRecoveryCodes error: %v", err)
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": response,
+	})
+}
+
+// Disable turns two-factor authentication off for the current user.
+func (tc *TwoFactorController) Disable(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+	request := new(model.TwoFactorDisableRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		tc.Log.Warnf("BodyParser error: %v", err)
+		return fiber.ErrBadRequest
+	}
+
+	if err := tc.TwoFactorUseCase.Disable(ctx.UserContext(), auth, request); err != nil {
+		tc.Log.Warnf("TwoFactorUseCase.Disable error: %v", err)
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Two-factor authentication disabled",
+	})
+}
+
+// Login completes a login challenge with a second factor and sets the token cookies.
+func (tc *TwoFactorController) Login(ctx *fiber.Ctx) error {
+	request := new(model.TwoFactorLoginRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		tc.Log.Warnf("BodyParser error: %v", err)
+		return fiber.ErrBadRequest
+	}
+	request.IPAddress = ctx.IP()
+
+	response, err := tc.TwoFactorUseCase.Login(ctx.UserContext(), request)
+	if err != nil {
+		tc.Log.Warnf("TwoFactorUseCase.Login error: %v", err)
+		return err
+	}
+
+	setTokenCookies(ctx, tc.Config, response)
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": response,
+	})
+}
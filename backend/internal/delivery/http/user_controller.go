@@ -12,6 +12,11 @@ import (
 	"github.com/spf13/viper"
 )
 
+const (
+	refreshTokenCookie     = "refresh_token"
+	refreshTokenCookiePath = "/users"
+)
+
 type UserController struct {
 	Log         *logrus.Logger
 	Config      *viper.Viper
@@ -34,6 +39,8 @@ func (uc *UserController) Register(ctx *fiber.Ctx) error {
 		uc.Log.Warnf("BodyParser error: %v", err)
 		return fiber.ErrBadRequest
 	}
+	request.UserAgent = ctx.Get(fiber.HeaderUserAgent)
+	request.IPAddress = ctx.IP()
 
 	response, err := uc.UserUseCase.Create(ctx.UserContext(), request)
 	if err != nil {
@@ -52,16 +59,7 @@ func (uc *UserController) Register(ctx *fiber.Ctx) error {
 	// 	SameSite: "None",
 	// })
 
-	ctx.Cookie(&fiber.Cookie{
-		Name:     "jwt",
-		Value:    response.Token,
-		Path:     "/",
-		Expires:  time.Now().Add(24 * time.Hour), // 1 day
-		HTTPOnly: true,
-		Secure:   uc.Config.GetBool("cookie.secure"),
-		Domain:   uc.Config.GetString("DOMAIN"),
-		SameSite: "Lax",
-	})
+	setTokenCookies(ctx, uc.Config, response)
 
 	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"data": response,
@@ -76,6 +74,8 @@ func (uc *UserController) Login(ctx *fiber.Ctx) error {
 		uc.Log.Warnf("BodyParser error: %v", err)
 		return fiber.ErrBadRequest
 	}
+	request.UserAgent = ctx.Get(fiber.HeaderUserAgent)
+	request.IPAddress = ctx.IP()
 
 	response, err := uc.UserUseCase.Login(ctx.UserContext(), request)
 	if err != nil {
@@ -83,6 +83,13 @@ func (uc *UserController) Login(ctx *fiber.Ctx) error {
 		return err
 	}
 
+	// With two-factor authentication the tokens only come with POST /users/login/2fa
+	if response.TwoFactorRequired {
+		return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+			"data": response,
+		})
+	}
+
 	// Set cookie
 	// ctx.Cookie(&fiber.Cookie{
 	// 	Name:     "jwt",
@@ -94,23 +101,49 @@ func (uc *UserController) Login(ctx *fiber.Ctx) error {
 	// 	SameSite: "None",
 	// })
 
-	ctx.Cookie(&fiber.Cookie{
-		Name:     "jwt",
-		Value:    response.Token,
-		Path:     "/",
-		Expires:  time.Now().Add(24 * time.Hour), // 1 day
-		HTTPOnly: true,
-		Secure:   uc.Config.GetBool("cookie.secure"),
-		Domain:   uc.Config.GetString("DOMAIN"),
-		SameSite: "Lax",
+	setTokenCookies(ctx, uc.Config, response.UserResponse)
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": response,
 	})
+}
+
+// Refresh rotates the refresh token from the request body or the refresh_token cookie into a new token pair.
+func (uc *UserController) Refresh(ctx *fiber.Ctx) error {
+	request := new(model.RefreshTokenRequest)
+
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(request); err != nil {
+			uc.Log.Warnf("BodyParser error: %v", err)
+			return fiber.ErrBadRequest
+		}
+	}
+	if request.RefreshToken == "" {
+		request.RefreshToken = ctx.Cookies(refreshTokenCookie)
+	}
+	request.IPAddress = ctx.IP()
+
+	response, err := uc.UserUseCase.Refresh(ctx.UserContext(), request)
+	if err != nil {
+		uc.Log.Warnf("UserUseCase.Refresh error: %v", err)
+		return err
+	}
+
+	setTokenCookies(ctx, uc.Config, response)
 
 	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
 		"data": response,
 	})
 }
 
+// Logout ends the current session on the server and clears the token cookies.
 func (uc *UserController) Logout(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+	if err := uc.UserUseCase.Logout(ctx.UserContext(), auth, middleware.GetToken(ctx)); err != nil {
+		uc.Log.Warnf("UserUseCase.Logout error: %v", err)
+		return err
+	}
+
 	// Clear cookie
 	// ctx.Cookie(&fiber.Cookie{
 	// 	Name:     "jwt",
@@ -155,11 +188,99 @@ func (uc *UserController) Logout(ctx *fiber.Ctx) error {
 		Path:     "/",
 	})
 
+	ctx.Cookie(&fiber.Cookie{
+		Name:     refreshTokenCookie,
+		Value:    "",
+		Path:     refreshTokenCookiePath,
+		MaxAge:   -1,
+		Expires:  time.Now().Add(-time.Hour),
+		HTTPOnly: true,
+		Secure:   uc.Config.GetBool("cookie.secure"),
+		Domain:   uc.Config.GetString("DOMAIN"),
+		SameSite: "Lax",
+	})
+
 	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "Logout successful",
 	})
 }
 
+// ChangePassword changes the password of the current user, other sessions are signed out.
+func (uc *UserController) ChangePassword(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+	request := new(model.ChangePasswordRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		uc.Log.Warnf("BodyParser error: %v", err)
+		return fiber.ErrBadRequest
+	}
+
+	if err := uc.UserUseCase.ChangePassword(ctx.UserContext(), auth, request); err != nil {
+		uc.Log.Warnf("UserUseCase.ChangePassword error: %v", err)
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Password changed",
+	})
+}
+
+// IssuePasswordReset creates a password reset token for a user, the admin passes it on to the user.
+func (uc *UserController) IssuePasswordReset(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+	userID, err := ctx.ParamsInt("id")
+	if err != nil || userID <= 0 {
+		uc.Log.Warnf("Invalid user ID: %s", ctx.Params("id"))
+		return fiber.ErrBadRequest
+	}
+
+	response, err := uc.UserUseCase.IssuePasswordReset(ctx.UserContext(), auth, uint(userID))
+	if err != nil {
+		uc.Log.Warnf("UserUseCase.IssuePasswordReset error: %v", err)
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"data": response,
+	})
+}
+
+// Unlock lifts the login lockout of a user.
+func (uc *UserController) Unlock(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+	userID, err := ctx.ParamsInt("id")
+	if err != nil || userID <= 0 {
+		uc.Log.Warnf("Invalid user ID: %s", ctx.Params("id"))
+		return fiber.ErrBadRequest
+	}
+
+	if err := uc.UserUseCase.Unlock(ctx.UserContext(), auth, uint(userID)); err != nil {
+		uc.Log.Warnf("UserUseCase.Unlock error: %v", err)
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "User unlocked",
+	})
+}
+
+// ResetPassword redeems a password reset token with a new password.
+func (uc *UserController) ResetPassword(ctx *fiber.Ctx) error {
+	request := new(model.ResetPasswordRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		uc.Log.Warnf("BodyParser error: %v", err)
+		return fiber.ErrBadRequest
+	}
+
+	if err := uc.UserUseCase.ResetPassword(ctx.UserContext(), request); err != nil {
+		uc.Log.Warnf("UserUseCase.ResetPassword error: %v", err)
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Password has been reset, please log in again",
+	})
+}
+
 // GetProfile returns the authenticated user's profile with wallet information.
 func (uc *UserController) GetProfile(ctx *fiber.Ctx) error {
 	auth := middleware.GetUser(ctx)
@@ -174,3 +295,29 @@ func (uc *UserController) GetProfile(ctx *fiber.Ctx) error {
 		"data": response,
 	})
 }
+
+// setTokenCookies stores the access token in the jwt cookie and the refresh token in a cookie
+// that is only sent to the /users routes, each expiring together with its token.
+func setTokenCookies(ctx *fiber.Ctx, config *viper.Viper, response *model.UserResponse) {
+	ctx.Cookie(&fiber.Cookie{
+		Name:     "jwt",
+		Value:    response.Token,
+		Path:     "/",
+		Expires:  response.TokenExpiresAt,
+		HTTPOnly: true,
+		Secure:   config.GetBool("cookie.secure"),
+		Domain:   config.GetString("DOMAIN"),
+		SameSite: "Lax",
+	})
+
+	ctx.Cookie(&fiber.Cookie{
+		Name:     refreshTokenCookie,
+		Value:    response.RefreshToken,
+		Path:     refreshTokenCookiePath,
+		Expires:  response.RefreshTokenExpiresAt,
+		HTTPOnly: true,
+		Secure:   config.GetBool("cookie.secure"),
+		Domain:   config.GetString("DOMAIN"),
+		SameSite: "Lax",
+	})
+}
@@ -33,3 +33,41 @@ func (wmc *WalletMutationController) GetMyMutations(ctx *fiber.Ctx) error {
 		"data": response,
 	})
 }
+
+// GetUserMutations returns the mutations of any user's wallet, for staff.
+func (wmc *WalletMutationController) GetUserMutations(ctx *fiber.Ctx) error {
+	userID, err := ctx.ParamsInt("id")
+	if err != nil || userID <= 0 {
+		wmc.Log.Warnf("Invalid user ID: %s", ctx.Params("id"))
+		return fiber.ErrBadRequest
+	}
+	page, _ := strconv.Atoi(ctx.Query("page", "1"))
+	limit, _ := strconv.Atoi(ctx.Query("limit", "10"))
+	response, err := wmc.WalletMutationUseCase.GetMutationsByUserID(ctx.UserContext(), uint(userID), page, limit)
+	if err != nil {
+		wmc.Log.Warnf("WalletMutationUseCase.GetMutationsByUserID error: %v", err)
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": response,
+	})
+}
+
+// GetWalletMutations returns the mutations of any wallet, for staff.
+func (wmc *WalletMutationController) GetWalletMutations(ctx *fiber.Ctx) error {
+	walletID, err := ctx.ParamsInt("id")
+	if err != nil || walletID <= 0 {
+		wmc.Log.Warnf("Invalid wallet ID: %s", ctx.Params("id"))
+		return fiber.ErrBadRequest
+	}
+	page, _ := strconv.Atoi(ctx.Query("page", "1"))
+	limit, _ := strconv.Atoi(ctx.Query("limit", "10"))
+	response, err := wmc.WalletMutationUseCase.GetMutationsByWalletID(ctx.UserContext(), uint(walletID), page, limit)
+	if err != nil {
+		wmc.Log.Warnf("WalletMutationUseCase.GetMutationsByWalletID error: %v", err)
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": response,
+	})
+}
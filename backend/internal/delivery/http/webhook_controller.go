@@ -0,0 +1,114 @@
+package http
+
+import (
+	"backend/internal/delivery/http/middleware"
+	"backend/internal/model"
+	"backend/internal/usecase"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type WebhookController struct {
+	Log            *logrus.Logger
+	WebhookUseCase usecase.WebhookUseCaseInterface
+}
+
+func NewWebhookController(log *logrus.Logger, webhookUseCase usecase.WebhookUseCaseInterface) *WebhookController {
+	return &WebhookController{
+		Log:            log,
+		WebhookUseCase: webhookUseCase,
+	}
+}
+
+// Create registers a webhook endpoint, the response carries the signing secret once.
+func (wc *WebhookController) Create(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+	request := new(model.CreateWebhookRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		wc.Log.Warnf("BodyParser error: %v", err)
+		return fiber.ErrBadRequest
+	}
+	response, err := wc.WebhookUseCase.Create(ctx.UserContext(), auth, request)
+	if err != nil {
+		wc.Log.Warnf("WebhookUseCase.Create error: %v", err)
+		return err
+	}
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"data": response,
+	})
+}
+
+// List returns the webhook endpoints of the current user.
+func (wc *WebhookController) List(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+	response, err := wc.WebhookUseCase.List(ctx.UserContext(), auth)
+	if err != nil {
+		wc.Log.Warnf("WebhookUseCase.List error: %v", err)
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": response,
+	})
+}
+
+// Delete removes a webhook endpoint of the current user.
+func (wc *WebhookController) Delete(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+	webhookID, err := ctx.ParamsInt("id")
+	if err != nil || webhookID <= 0 {
+		wc.Log.Warnf("Invalid webhook ID: %s", ctx.Params("id"))
+		return fiber.ErrBadRequest
+	}
+	if err := wc.WebhookUseCase.Delete(ctx.UserContext(), auth, uint(webhookID)); err != nil {
+		wc.Log.Warnf("WebhookUseCase.Delete error: %v", err)
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Webhook deleted",
+	})
+}
+
+// GetDeliveries returns the delivery log of a webhook endpoint.
+func (wc *WebhookController) GetDeliveries(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+	webhookID, err := ctx.ParamsInt("id")
+	if err != nil || webhookID <= 0 {
+		wc.Log.Warnf("Invalid webhook ID: %s", ctx.Params("id"))
+		return fiber.ErrBadRequest
+	}
+	page, _ := strconv.Atoi(ctx.Query("page", "1"))
+	limit, _ := strconv.Atoi(ctx.Query("limit", "10"))
+	response, err := wc.WebhookUseCase.GetDeliveries(ctx.UserContext(), auth, uint(webhookID), page, limit)
+	if err != nil {
+		wc.Log.Warnf("WebhookUseCase.GetDeliveries error: %v", err)
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": response,
+	})
+}
+
+// Redeliver queues a webhook delivery to be sent again.
+func (wc *WebhookController) Redeliver(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+	webhookID, err := ctx.ParamsInt("id")
+	if err != nil || webhookID <= 0 {
+		wc.Log.Warnf("Invalid webhook ID: %s", ctx.Params("id"))
+		return fiber.ErrBadRequest
+	}
+	deliveryID, err := ctx.ParamsInt("deliveryId")
+	if err != nil || deliveryID <= 0 {
+		wc.Log.Warnf("Invalid webhook delivery ID: %s", ctx.Params("deliveryId"))
+		return fiber.ErrBadRequest
+	}
+	response, err := wc.WebhookUseCase.Redeliver(ctx.UserContext(), auth, uint(webhookID), uint(deliveryID))
+	if err != nil {
+		wc.Log.Warnf("WebhookUseCase.Redeliver error: %v", err)
+		return err
+	}
+	return ctx.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"data": response,
+	})
+}
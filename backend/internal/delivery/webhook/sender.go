@@ -0,0 +1,113 @@
+package webhook
+
+import (
+	"backend/internal/entity"
+	"backend/internal/util"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Headers sent with every webhook request.
+const (
+	HeaderEventID   = "X-Webhook-Event-ID"
+	HeaderEventType = "X-Webhook-Event-Type"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// errInsecureURL is returned for endpoints that do not use https.
+var errInsecureURL = errors.New("webhook URL must use https")
+
+// Sender posts webhook deliveries to their endpoints.
+type Sender struct {
+	Client *http.Client
+	Log    *logrus.Logger
+}
+
+// NewSender creates a new Sender whose requests give up after timeout.
+// Users choose the endpoint URLs, so the client only connects to public addresses, checked on every
+// connection against the resolved address, ignores proxy settings and never follows redirects.
+func NewSender(log *logrus.Logger, timeout time.Duration) *Sender {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: util.PublicDialControl,
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &Sender{
+		Client: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+			// A redirect is reported as the non-2xx status it is
+			CheckRedirect: func(request *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		Log: log,
+	}
+}
+
+// Send posts a delivery to its endpoint and returns the response status code.
+// Any status outside 2xx is returned as an error together with the code.
+func (s *Sender) Send(ctx context.Context, endpoint *entity.WebhookEndpoint, delivery *entity.WebhookDelivery) (int, error) {
+	target, err := url.Parse(endpoint.URL)
+	if err != nil {
+		return 0, err
+	}
+	if target.Scheme != "https" {
+		return 0, errInsecureURL
+	}
+
+	timestamp := time.Now().Unix()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "wallet-exhibition-webhook/1.0")
+	request.Header.Set(HeaderEventID, strconv.FormatUint(uint64(delivery.OutboxEventID), 10))
+	request.Header.Set(HeaderEventType, string(delivery.EventType))
+	request.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(HeaderSignature, Sign(endpoint.Secret, timestamp, delivery.Payload))
+
+	response, err := s.Client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	// Drain a bounded part of the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, 64*1024))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("unexpected response status %d", response.StatusCode)
+	}
+	return response.StatusCode, nil
+}
+
+// Sign computes the signature header value for a request body.
+// Receivers recompute HMAC-SHA256 over "<timestamp>.<body>" with their secret and compare.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,32 @@
+package websocket
+
+import (
+	"backend/internal/model"
+	"slices"
+
+	"github.com/shopspring/decimal"
+)
+
+// AdminFeedFilter selects the system-wide transactions streamed to an admin connection.
+type AdminFeedFilter struct {
+	// MinAmount skips transactions below this amount, zero streams every amount
+	MinAmount decimal.Decimal
+	// TransactionTypes limits the stream to these types, empty streams every type
+	TransactionTypes []string
+}
+
+// Matches reports whether a transaction passes the filter.
+func (f *AdminFeedFilter) Matches(notification *model.AdminTransactionNotification) bool {
+	if len(f.TransactionTypes) > 0 && !slices.Contains(f.TransactionTypes, notification.TransactionType) {
+		return false
+	}
+
+	if f.MinAmount.IsPositive() {
+		amount, err := decimal.NewFromString(notification.Amount)
+		if err != nil || amount.LessThan(f.MinAmount) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,86 @@
+package websocket
+
+import (
+	"backend/internal/model"
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// BrokerMessage is a message fanned out to every instance, addressed either to one user or to the admin feed.
+// A message with Disconnect set carries no Message, it closes the selected connections instead.
+type BrokerMessage struct {
+	UserID     uint                     `json:"user_id,omitempty"`
+	AdminFeed  bool                     `json:"admin_feed,omitempty"`
+	Message    []byte                   `json:"message,omitempty"`
+	Disconnect *model.DisconnectRequest `json:"disconnect,omitempty"`
+}
+
+// Broker fans messages out to every application instance,
+// each instance then delivers them to its own local connections.
+type Broker interface {
+	// Publish sends a message to all instances.
+	Publish(ctx context.Context, message *BrokerMessage) error
+	// Subscribe calls handler for every published message until ctx is cancelled or the subscription fails.
+	Subscribe(ctx context.Context, handler func(message *BrokerMessage)) error
+}
+
+// RedisBroker is a Broker backed by Redis pub/sub.
+type RedisBroker struct {
+	Client  *redis.Client
+	Channel string
+	Log     *logrus.Logger
+}
+
+// NewRedisBroker creates a new RedisBroker publishing on the given channel.
+func NewRedisBroker(client *redis.Client, channel string, log *logrus.Logger) *RedisBroker {
+	return &RedisBroker{
+		Client:  client,
+		Channel: channel,
+		Log:     log,
+	}
+}
+
+// Publish publishes a message on the Redis channel.
+func (b *RedisBroker) Publish(ctx context.Context, message *BrokerMessage) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	return b.Client.Publish(ctx, b.Channel, data).Err()
+}
+
+// Subscribe listens on the Redis channel and hands every message to handler.
+func (b *RedisBroker) Subscribe(ctx context.Context, handler func(message *BrokerMessage)) error {
+	pubsub := b.Client.Subscribe(ctx, b.Channel)
+	defer pubsub.Close()
+
+	// Wait for the subscription to be confirmed
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return err
+	}
+
+	b.Log.Infof("Subscribed to Redis channel %s for WebSocket fan-out", b.Channel)
+
+	channel := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg, ok := <-channel:
+			if !ok {
+				return errors.New("redis subscription closed")
+			}
+
+			message := new(BrokerMessage)
+			if err := json.Unmarshal([]byte(msg.Payload), message); err != nil {
+				b.Log.Warnf("Invalid WebSocket fan-out message on channel %s: %v", b.Channel, err)
+				continue
+			}
+			handler(message)
+		}
+	}
+}
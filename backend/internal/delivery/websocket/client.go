@@ -0,0 +1,258 @@
+package websocket
+
+import (
+	"backend/internal/model"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/sirupsen/logrus"
+)
+
+// SubscribableEventTypes are the notification types a client can subscribe to and unsubscribe from.
+// Other messages, such as resync_required, are always delivered.
+var SubscribableEventTypes = []string{"transaction", "wallet_update"}
+
+// Conn is the part of a WebSocket connection used by a Client.
+type Conn interface {
+	WriteMessage(messageType int, data []byte) error
+	WriteControl(messageType int, data []byte, deadline time.Time) error
+	SetWriteDeadline(t time.Time) error
+	SetReadDeadline(t time.Time) error
+	SetPongHandler(h func(appData string) error)
+	Close() error
+}
+
+// ClientConfig holds the send queue and heartbeat settings of WebSocket clients.
+type ClientConfig struct {
+	// SendQueueSize is the number of messages buffered per connection before it is evicted as a slow consumer
+	SendQueueSize int
+	// WriteWait is the time allowed to write a message to the peer
+	WriteWait time.Duration
+	// PongWait is the time allowed to read the next pong or message from the peer
+	PongWait time.Duration
+	// PingPeriod is the interval of server pings, it must be shorter than PongWait
+	PingPeriod time.Duration
+}
+
+// DefaultClientConfig returns the default ClientConfig.
+func DefaultClientConfig() ClientConfig {
+	return ClientConfig{
+		SendQueueSize: 64,
+		WriteWait:     10 * time.Second,
+		PongWait:      60 * time.Second,
+		PingPeriod:    54 * time.Second,
+	}
+}
+
+// Client is a WebSocket connection of a user together with its subscriptions.
+// All writes go through a single writer goroutine fed by a bounded queue, so producers never
+// block on a slow connection and the connection never sees concurrent writers.
+type Client struct {
+	UserID uint
+	Role   string
+	// Auth holds the claims of the access token the connection was opened with
+	Auth   *model.Auth
+	conn   Conn
+	config ClientConfig
+	log    *logrus.Logger
+	// send is the bounded queue drained by the writer goroutine
+	send chan []byte
+	// done is closed when the client is closed
+	done      chan struct{}
+	closeOnce sync.Once
+	startOnce sync.Once
+	writer    sync.WaitGroup
+	// skipSeq drops queued messages up to this seq, they were already replayed
+	skipSeq uint64
+	// mu guards unsubscribed, adminFeed and adminFeedCheckedAt
+	mu           sync.RWMutex
+	unsubscribed map[string]bool
+	adminFeed    *AdminFeedFilter
+	// adminFeedCheckedAt is when the permission to receive the admin feed was last checked
+	adminFeedCheckedAt time.Time
+}
+
+// NewClient creates a new Client of the authenticated user subscribed to every event type.
+func NewClient(auth *model.Auth, conn Conn, config ClientConfig, log *logrus.Logger) *Client {
+	return &Client{
+		UserID:       *auth.UserID,
+		Role:         auth.Role,
+		Auth:         auth,
+		conn:         conn,
+		config:       config,
+		log:          log,
+		send:         make(chan []byte, config.SendQueueSize),
+		done:         make(chan struct{}),
+		unsubscribed: make(map[string]bool),
+	}
+}
+
+// Start starts the writer goroutine, messages queued before are written first.
+func (c *Client) Start() {
+	c.startOnce.Do(func() {
+		c.writer.Add(1)
+		go c.writePump()
+	})
+}
+
+// skipThrough makes the writer drop queued messages with a seq up to seq, it must be called before Start.
+func (c *Client) skipThrough(seq uint64) {
+	c.skipSeq = seq
+}
+
+// Send queues a message for the client without blocking.
+// A client whose queue is full is closed as a slow consumer and false is returned.
+func (c *Client) Send(message []byte) bool {
+	select {
+	case <-c.done:
+		return false
+	default:
+	}
+
+	select {
+	case c.send <- message:
+		return true
+	default:
+		c.log.Warnf("WebSocket send queue of user ID %d is full, closing slow connection", c.UserID)
+		c.Close()
+		return false
+	}
+}
+
+// Close stops the writer goroutine and closes the connection, which also ends the reader.
+func (c *Client) Close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		_ = c.conn.Close()
+	})
+}
+
+// Done returns a channel that is closed when the client is closed.
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Wait blocks until the writer goroutine has stopped, the connection must not be used afterwards.
+func (c *Client) Wait() {
+	c.writer.Wait()
+}
+
+// PrepareRead sets the read deadline and extends it whenever the peer answers a ping.
+func (c *Client) PrepareRead() error {
+	c.conn.SetPongHandler(func(string) error {
+		return c.ExtendReadDeadline()
+	})
+	return c.ExtendReadDeadline()
+}
+
+// ExtendReadDeadline gives the peer another PongWait to send the next pong or message.
+func (c *Client) ExtendReadDeadline() error {
+	return c.conn.SetReadDeadline(time.Now().Add(c.config.PongWait))
+}
+
+// write writes a message directly within the write deadline.
+// It must only be used before Start or by the writer goroutine.
+func (c *Client) write(message []byte) error {
+	if err := c.conn.SetWriteDeadline(time.Now().Add(c.config.WriteWait)); err != nil {
+		return err
+	}
+	return c.conn.WriteMessage(websocket.TextMessage, message)
+}
+
+// writePump writes queued messages and periodic pings until the client is closed or a write fails.
+func (c *Client) writePump() {
+	ticker := time.NewTicker(c.config.PingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.Close()
+		c.writer.Done()
+	}()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case message := <-c.send:
+			if seq, ok := messageSeq(message); ok && seq <= c.skipSeq {
+				continue
+			}
+			if err := c.write(message); err != nil {
+				c.log.Warnf("Failed to send message to user ID %d: %v", c.UserID, err)
+				return
+			}
+		case <-ticker.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(c.config.WriteWait)); err != nil {
+				c.log.Warnf("Failed to ping user ID %d: %v", c.UserID, err)
+				return
+			}
+		}
+	}
+}
+
+// Subscribe enables delivery of the given event types.
+func (c *Client) Subscribe(eventTypes ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, eventType := range eventTypes {
+		delete(c.unsubscribed, eventType)
+	}
+}
+
+// Unsubscribe disables delivery of the given event types.
+func (c *Client) Unsubscribe(eventTypes ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, eventType := range eventTypes {
+		c.unsubscribed[eventType] = true
+	}
+}
+
+// Accepts reports whether a message of the given type is delivered to the client.
+func (c *Client) Accepts(messageType string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return !c.unsubscribed[messageType]
+}
+
+// Subscriptions returns the event types the client is subscribed to.
+func (c *Client) Subscriptions() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return slices.DeleteFunc(slices.Clone(SubscribableEventTypes), func(eventType string) bool {
+		return c.unsubscribed[eventType]
+	})
+}
+
+// SetAdminFeed subscribes the client to the admin feed with the given filter, nil unsubscribes it.
+// The permission was checked by the caller, so the next check is due from now on.
+func (c *Client) SetAdminFeed(filter *AdminFeedFilter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.adminFeed = filter
+	c.adminFeedCheckedAt = time.Now()
+}
+
+// adminFeedCheckDue reports whether the admin feed permission was last checked longer than interval ago,
+// and if so counts it as checked now so concurrent deliveries do not check it again.
+func (c *Client) adminFeedCheckDue(interval time.Duration) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if now.Sub(c.adminFeedCheckedAt) < interval {
+		return false
+	}
+	c.adminFeedCheckedAt = now
+	return true
+}
+
+// AcceptsAdminFeed reports whether an admin feed transaction is delivered to the client.
+func (c *Client) AcceptsAdminFeed(notification *model.AdminTransactionNotification) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.adminFeed != nil && c.adminFeed.Matches(notification)
+}
@@ -0,0 +1,184 @@
+package websocket
+
+import (
+	"backend/internal/entity"
+	"backend/internal/model"
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// HandleCommand runs a command sent by a client and returns the response to write back.
+func (h *Handler) HandleCommand(ctx context.Context, client *Client, data []byte) *model.WebSocketResponse {
+	command := new(model.WebSocketCommand)
+	if err := json.Unmarshal(data, command); err != nil {
+		h.Log.Warnf("Invalid WebSocket command from user ID %d: %v", client.UserID, err)
+		return commandError(command, fiber.NewError(fiber.StatusBadRequest, "Invalid command format"))
+	}
+
+	// Validate command
+	if err := h.Validate.Struct(command); err != nil {
+		h.Log.Warnf("Validation error: %v", err)
+		return commandError(command, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	var (
+		payload interface{}
+		err     error
+	)
+	switch command.Type {
+	case "get_balance":
+		payload, err = h.getBalance(ctx, client)
+	case "ack":
+		payload, err = h.ack(ctx, client, command.Payload)
+	case "subscribe":
+		payload, err = h.subscribe(client, command.Payload, client.Subscribe)
+	case "unsubscribe":
+		payload, err = h.subscribe(client, command.Payload, client.Unsubscribe)
+	case "subscribe_admin_feed":
+		payload, err = h.subscribeAdminFeed(client, command.Payload)
+	case "unsubscribe_admin_feed":
+		payload, err = h.unsubscribeAdminFeed(client)
+	case "ping":
+		payload = &model.PingCommandResponse{Time: time.Now()}
+	default:
+		err = fiber.NewError(fiber.StatusBadRequest, "Unknown command type")
+	}
+	if err != nil {
+		return commandError(command, err)
+	}
+
+	return &model.WebSocketResponse{
+		ID:      command.ID,
+		Type:    "response",
+		Command: command.Type,
+		Payload: payload,
+	}
+}
+
+// getBalance returns the wallet of the connected user.
+func (h *Handler) getBalance(ctx context.Context, client *Client) (*model.WalletResponse, error) {
+	wallet, err := h.WalletUseCase.GetByUserID(ctx, client.UserID)
+	if err != nil {
+		h.Log.Warnf("WalletUseCase.GetByUserID error: %v", err)
+		return nil, err
+	}
+	return wallet, nil
+}
+
+// ack records that the connected user received the notifications up to the given seq.
+func (h *Handler) ack(ctx context.Context, client *Client, data json.RawMessage) (*model.AckCommandResponse, error) {
+	request := new(model.AckCommandRequest)
+	if err := h.decodePayload(data, request); err != nil {
+		return nil, err
+	}
+
+	acked, err := h.Hub.Ack(ctx, client.UserID, request.Seq)
+	if err != nil {
+		if errors.Is(err, ErrAckAhead) || errors.Is(err, ErrEventLogDisabled) {
+			return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		h.Log.Errorf("Hub.Ack error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return &model.AckCommandResponse{Seq: acked}, nil
+}
+
+// subscribe applies a subscription change to the client and returns its subscriptions.
+func (h *Handler) subscribe(client *Client, data json.RawMessage, apply func(eventTypes ...string)) (*model.SubscriptionCommandResponse, error) {
+	request := new(model.SubscriptionCommandRequest)
+	if err := h.decodePayload(data, request); err != nil {
+		return nil, err
+	}
+
+	apply(request.EventTypes...)
+	return &model.SubscriptionCommandResponse{EventTypes: client.Subscriptions()}, nil
+}
+
+// subscribeAdminFeed streams every matching transaction system-wide to a super admin connection.
+// Subscribing again replaces the filters.
+func (h *Handler) subscribeAdminFeed(client *Client, data json.RawMessage) (*model.AdminFeedCommandResponse, error) {
+	if !entity.RoleHasPermission(client.Role, entity.PermissionAdminFeedSubscribe) {
+		h.Log.Warnf("Unauthorized admin feed subscription by user ID: %d", client.UserID)
+		return nil, fiber.NewError(fiber.StatusForbidden, "Only super admin can subscribe to the admin feed")
+	}
+	// Checked again here, the feed exposes every transaction in the system
+	if client.Auth.MissingTwoFactor(h.TwoFactorRoles) {
+		h.Log.Warnf("Admin feed subscription without two-factor authentication by user ID: %d", client.UserID)
+		return nil, fiber.NewError(fiber.StatusForbidden, "Two-factor authentication is required to subscribe to the admin feed")
+	}
+
+	// Filters are optional, an empty payload streams everything
+	request := new(model.AdminFeedCommandRequest)
+	if len(data) > 0 {
+		if err := h.decodePayload(data, request); err != nil {
+			return nil, err
+		}
+	}
+	if request.MinAmount.IsNegative() {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Minimum amount cannot be negative")
+	}
+
+	transactionTypes := request.TransactionTypes
+	if transactionTypes == nil {
+		transactionTypes = []string{}
+	}
+
+	client.SetAdminFeed(&AdminFeedFilter{
+		MinAmount:        request.MinAmount,
+		TransactionTypes: transactionTypes,
+	})
+	h.Log.Infof("User ID %d subscribed to the admin feed", client.UserID)
+
+	return &model.AdminFeedCommandResponse{
+		MinAmount:        request.MinAmount,
+		TransactionTypes: transactionTypes,
+	}, nil
+}
+
+// AuthorizeAdminFeed checks that the user of a connection still has the permission to receive the admin feed,
+// the Hub runs it for subscribers while it fans the feed out.
+func (h *Handler) AuthorizeAdminFeed(ctx context.Context, client *Client) error {
+	return h.UserUseCase.CheckPermission(ctx, client.UserID, entity.PermissionAdminFeedSubscribe)
+}
+
+// unsubscribeAdminFeed stops the admin feed of a connection.
+func (h *Handler) unsubscribeAdminFeed(client *Client) (interface{}, error) {
+	client.SetAdminFeed(nil)
+	return nil, nil
+}
+
+// decodePayload parses and validates a command payload.
+func (h *Handler) decodePayload(data json.RawMessage, request interface{}) error {
+	if len(data) == 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "Payload is required")
+	}
+	if err := json.Unmarshal(data, request); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid payload format")
+	}
+	if err := h.Validate.Struct(request); err != nil {
+		h.Log.Warnf("Validation error: %v", err)
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return nil
+}
+
+// commandError builds the error response for a failed command.
+func commandError(command *model.WebSocketCommand, err error) *model.WebSocketResponse {
+	e := fiber.ErrInternalServerError
+	errors.As(err, &e)
+
+	return &model.WebSocketResponse{
+		ID:      command.ID,
+		Type:    "error",
+		Command: command.Type,
+		Error: &model.WebSocketError{
+			Code:    e.Code,
+			Message: e.Message,
+		},
+	}
+}
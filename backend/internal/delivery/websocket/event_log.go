@@ -0,0 +1,246 @@
+package websocket
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrAckAhead is returned when a client acknowledges a sequence number that was not handed out yet.
+	ErrAckAhead = errors.New("acknowledged seq is ahead of the event log")
+	// ErrEventLogDisabled is returned when acknowledging events on a Hub without an event log.
+	ErrEventLogDisabled = errors.New("event log is disabled")
+	// errMessageNotObject is returned when a message without fields is appended to the event log.
+	errMessageNotObject = errors.New("websocket message must be a non-empty JSON object")
+)
+
+// LoggedEvent is a message kept in the event log of a user.
+type LoggedEvent struct {
+	Seq     uint64
+	Message []byte
+}
+
+// EventLog assigns per-user sequence numbers to messages and keeps the latest ones for replay.
+type EventLog interface {
+	// Append stores a JSON object message under the next sequence number of the user
+	// and returns the message with a leading "seq" field.
+	Append(ctx context.Context, userID uint, message []byte) ([]byte, error)
+	// AppendOnce appends a message identified by key, such as an outbox event that is retried.
+	// While the key is remembered, at least as long as its event is retained, appending it again
+	// returns the message logged the first time instead of numbering it anew.
+	AppendOnce(ctx context.Context, userID uint, key string, message []byte) ([]byte, error)
+	// Since returns the retained messages of the user after seq, oldest first,
+	// together with the latest sequence number handed out for the user.
+	Since(ctx context.Context, userID uint, seq uint64) ([]LoggedEvent, uint64, error)
+	// Ack moves the acknowledged sequence number of the user forward to seq and returns it.
+	Ack(ctx context.Context, userID uint, seq uint64) (uint64, error)
+	// Acked returns the acknowledged sequence number of the user, ok is false when nothing was acknowledged.
+	Acked(ctx context.Context, userID uint) (seq uint64, ok bool, err error)
+}
+
+// withSeq returns message with a leading "seq" field.
+func withSeq(message []byte, seq uint64) []byte {
+	result := make([]byte, 0, len(message)+24)
+	result = append(result, `{"seq":`...)
+	result = strconv.AppendUint(result, seq, 10)
+	result = append(result, ',')
+	return append(result, message[1:]...)
+}
+
+// messageSeq returns the seq field withSeq put in front of message, ok is false for messages without one.
+func messageSeq(message []byte) (uint64, bool) {
+	rest, ok := bytes.CutPrefix(message, []byte(`{"seq":`))
+	if !ok {
+		return 0, false
+	}
+	end := bytes.IndexByte(rest, ',')
+	if end < 0 {
+		return 0, false
+	}
+	seq, err := strconv.ParseUint(string(rest[:end]), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return seq, true
+}
+
+// checkMessage makes sure the seq field can be spliced into message.
+func checkMessage(message []byte) error {
+	if !bytes.HasPrefix(message, []byte(`{"`)) {
+		return errMessageNotObject
+	}
+	return nil
+}
+
+// replayComplete reports whether events hold everything logged after since.
+// A since ahead of latest means the sequence was reset, so the client has to resync as well.
+func replayComplete(since, latest uint64, events []LoggedEvent) bool {
+	if since > latest {
+		return false
+	}
+	if since == latest {
+		return true
+	}
+	return len(events) > 0 && events[0].Seq == since+1
+}
+
+// memoryUserLog holds the sequence counter, acknowledged sequence number and retained events of one user.
+// keys maps the keys of retained events appended with AppendOnce to their seq.
+type memoryUserLog struct {
+	seq       uint64
+	acked     *uint64
+	events    []LoggedEvent
+	keys      map[string]uint64
+	expiresAt time.Time
+}
+
+// MemoryEventLog is an EventLog kept in process memory, for single-node setups.
+// Like RedisEventLog, the log of a user expires ttl after its last new or acknowledged event,
+// expired logs are swept out at most once per ttl.
+type MemoryEventLog struct {
+	size      int
+	ttl       time.Duration
+	mu        sync.Mutex
+	users     map[uint]*memoryUserLog
+	nextSweep time.Time
+}
+
+// NewMemoryEventLog creates a new MemoryEventLog retaining size events per user for ttl.
+func NewMemoryEventLog(size int, ttl time.Duration) *MemoryEventLog {
+	return &MemoryEventLog{
+		size:      size,
+		ttl:       ttl,
+		users:     make(map[uint]*memoryUserLog),
+		nextSweep: time.Now().Add(ttl),
+	}
+}
+
+// userLog returns the log of a user, nil when there is none or it expired. The caller holds l.mu.
+func (l *MemoryEventLog) userLog(userID uint, now time.Time) *memoryUserLog {
+	userLog := l.users[userID]
+	if userLog != nil && !now.Before(userLog.expiresAt) {
+		delete(l.users, userID)
+		return nil
+	}
+	return userLog
+}
+
+// sweep removes the expired logs of all users once per ttl. The caller holds l.mu.
+func (l *MemoryEventLog) sweep(now time.Time) {
+	if now.Before(l.nextSweep) {
+		return
+	}
+	for userID, userLog := range l.users {
+		if !now.Before(userLog.expiresAt) {
+			delete(l.users, userID)
+		}
+	}
+	l.nextSweep = now.Add(l.ttl)
+}
+
+// Append stores a message under the next sequence number of the user.
+func (l *MemoryEventLog) Append(ctx context.Context, userID uint, message []byte) ([]byte, error) {
+	return l.AppendOnce(ctx, userID, "", message)
+}
+
+// AppendOnce stores a message under the next sequence number of the user unless its key is retained already.
+// An empty key is never remembered.
+func (l *MemoryEventLog) AppendOnce(ctx context.Context, userID uint, key string, message []byte) ([]byte, error) {
+	if err := checkMessage(message); err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	userLog := l.userLog(userID, now)
+	if userLog == nil {
+		userLog = &memoryUserLog{keys: make(map[string]uint64)}
+		l.users[userID] = userLog
+	}
+
+	if seq, ok := userLog.keys[key]; ok {
+		for _, event := range userLog.events {
+			if event.Seq == seq {
+				return event.Message, nil
+			}
+		}
+	}
+
+	userLog.expiresAt = now.Add(l.ttl)
+	userLog.seq++
+	logged := withSeq(message, userLog.seq)
+	userLog.events = append(userLog.events, LoggedEvent{Seq: userLog.seq, Message: logged})
+	if key != "" {
+		userLog.keys[key] = userLog.seq
+	}
+
+	// Drop the oldest events beyond the retained size, together with their keys
+	if overflow := len(userLog.events) - l.size; overflow > 0 {
+		userLog.events = append([]LoggedEvent(nil), userLog.events[overflow:]...)
+		for key, seq := range userLog.keys {
+			if seq < userLog.events[0].Seq {
+				delete(userLog.keys, key)
+			}
+		}
+	}
+
+	return logged, nil
+}
+
+// Since returns the retained messages of the user after seq.
+func (l *MemoryEventLog) Since(ctx context.Context, userID uint, seq uint64) ([]LoggedEvent, uint64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	userLog := l.userLog(userID, time.Now())
+	if userLog == nil {
+		return nil, 0, nil
+	}
+
+	var events []LoggedEvent
+	for _, event := range userLog.events {
+		if event.Seq > seq {
+			events = append(events, event)
+		}
+	}
+
+	return events, userLog.seq, nil
+}
+
+// Ack moves the acknowledged sequence number of the user forward to seq.
+func (l *MemoryEventLog) Ack(ctx context.Context, userID uint, seq uint64) (uint64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	userLog := l.userLog(userID, now)
+	if userLog == nil || seq > userLog.seq {
+		return 0, ErrAckAhead
+	}
+
+	if userLog.acked == nil || seq > *userLog.acked {
+		userLog.acked = &seq
+		userLog.expiresAt = now.Add(l.ttl)
+	}
+	return *userLog.acked, nil
+}
+
+// Acked returns the acknowledged sequence number of the user.
+func (l *MemoryEventLog) Acked(ctx context.Context, userID uint) (uint64, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	userLog := l.userLog(userID, time.Now())
+	if userLog == nil || userLog.acked == nil {
+		return 0, false, nil
+	}
+	return *userLog.acked, true, nil
+}
@@ -2,6 +2,7 @@ package websocket
 
 import (
 	"backend/internal/model"
+	"context"
 	"encoding/json"
 	"time"
 
@@ -9,27 +10,53 @@ import (
 )
 
 // NotifierInterface defines the interface for notification operations.
+// eventKey identifies a notification across delivery retries, so a retried notification keeps its seq.
 type NotifierInterface interface {
-	NotifyTransaction(userID uint, notification *model.TransactionNotification) error
-	NotifyWalletUpdate(userID uint, notification *model.WalletUpdateNotification) error
+	NotifyTransaction(userID uint, eventKey string, notification *model.TransactionNotification) error
+	NotifyWalletUpdate(userID uint, eventKey string, notification *model.WalletUpdateNotification) error
+	NotifyAdminTransaction(notification *model.AdminTransactionNotification) error
+}
+
+// NotificationInboxInterface stores notifications in the inbox of a user.
+// Storing the same type and reference twice must return the notification stored first.
+type NotificationInboxInterface interface {
+	Store(ctx context.Context, userID uint, notificationType string, referenceID uint, payload interface{}) (*model.NotificationResponse, error)
 }
 
 // Notifier sends notifications to users via WebSocket.
+// With an Inbox, transaction notifications are also kept for users who are offline.
 type Notifier struct {
-	Hub *Hub
-	Log *logrus.Logger
+	Hub   *Hub
+	Inbox NotificationInboxInterface
+	Log   *logrus.Logger
 }
 
-// NewNotifier creates a new Notifier instance.
+// NewNotifier creates a new Notifier instance that only pushes live notifications.
 func NewNotifier(hub *Hub, log *logrus.Logger) *Notifier {
+	return NewNotifierWithInbox(hub, nil, log)
+}
+
+// NewNotifierWithInbox creates a new Notifier instance that stores transaction notifications in inbox before pushing them.
+func NewNotifierWithInbox(hub *Hub, inbox NotificationInboxInterface, log *logrus.Logger) *Notifier {
 	return &Notifier{
-		Hub: hub,
-		Log: log,
+		Hub:   hub,
+		Inbox: inbox,
+		Log:   log,
 	}
 }
 
-// NotifyTransaction sends a transaction notification to a user.
-func (n *Notifier) NotifyTransaction(userID uint, notification *model.TransactionNotification) error {
+// NotifyTransaction stores a transaction notification in the inbox of a user and sends it live.
+// The live message carries the inbox ID so the client can mark it read.
+func (n *Notifier) NotifyTransaction(userID uint, eventKey string, notification *model.TransactionNotification) error {
+	if n.Inbox != nil {
+		stored, err := n.Inbox.Store(context.Background(), userID, "transaction", notification.TransactionID, notification)
+		if err != nil {
+			n.Log.Errorf("Failed to store transaction notification for user ID %d: %v", userID, err)
+			return err
+		}
+		notification.NotificationID = stored.ID
+	}
+
 	message := model.WebSocketMessage{
 		Type:    "transaction",
 		Payload: notification,
@@ -41,7 +68,7 @@ func (n *Notifier) NotifyTransaction(userID uint, notification *model.Transactio
 		return err
 	}
 
-	if err := n.Hub.BroadcastToUser(userID, data); err != nil {
+	if err := n.Hub.BroadcastEventToUser(userID, eventKey, data); err != nil {
 		n.Log.Warnf("Failed to send transaction notification to user ID %d: %v", userID, err)
 		return err
 	}
@@ -51,7 +78,7 @@ func (n *Notifier) NotifyTransaction(userID uint, notification *model.Transactio
 }
 
 // NotifyWalletUpdate sends a wallet update notification to a user.
-func (n *Notifier) NotifyWalletUpdate(userID uint, notification *model.WalletUpdateNotification) error {
+func (n *Notifier) NotifyWalletUpdate(userID uint, eventKey string, notification *model.WalletUpdateNotification) error {
 	if notification.UpdatedAt.IsZero() {
 		notification.UpdatedAt = time.Now()
 	}
@@ -67,7 +94,7 @@ func (n *Notifier) NotifyWalletUpdate(userID uint, notification *model.WalletUpd
 		return err
 	}
 
-	if err := n.Hub.BroadcastToUser(userID, data); err != nil {
+	if err := n.Hub.BroadcastEventToUser(userID, eventKey, data); err != nil {
 		n.Log.Warnf("Failed to send wallet update notification to user ID %d: %v", userID, err)
 		return err
 	}
@@ -75,3 +102,25 @@ func (n *Notifier) NotifyWalletUpdate(userID uint, notification *model.WalletUpd
 	n.Log.Infof("Wallet update notification sent to user ID: %d", userID)
 	return nil
 }
+
+// NotifyAdminTransaction streams a system-wide transaction to admin feed subscribers.
+func (n *Notifier) NotifyAdminTransaction(notification *model.AdminTransactionNotification) error {
+	message := model.WebSocketMessage{
+		Type:    "admin_transaction",
+		Payload: notification,
+	}
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		n.Log.Errorf("Failed to marshal admin transaction notification: %v", err)
+		return err
+	}
+
+	if err := n.Hub.BroadcastToAdminFeed(data); err != nil {
+		n.Log.Warnf("Failed to send admin transaction notification for transaction ID %d: %v", notification.TransactionID, err)
+		return err
+	}
+
+	n.Log.Debugf("Admin transaction notification sent for transaction ID: %d", notification.TransactionID)
+	return nil
+}
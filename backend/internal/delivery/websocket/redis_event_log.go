@@ -0,0 +1,174 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisAppendScript increments the sequence of a user and stores the message with its seq field
+// in one step, so readers never see a sequence number without its event.
+// With a third key the logged message is remembered under it for the ttl and returned
+// instead of appending the message again.
+var redisAppendScript = redis.NewScript(`
+if KEYS[3] then
+	local logged = redis.call('GET', KEYS[3])
+	if logged then
+		return logged
+	end
+end
+local seq = redis.call('INCR', KEYS[1])
+local message = '{"seq":' .. seq .. ',' .. string.sub(ARGV[1], 2)
+redis.call('ZADD', KEYS[2], seq, message)
+redis.call('ZREMRANGEBYRANK', KEYS[2], 0, -tonumber(ARGV[2]) - 1)
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+redis.call('PEXPIRE', KEYS[2], ARGV[3])
+if KEYS[3] then
+	redis.call('SET', KEYS[3], message, 'PX', ARGV[3])
+end
+return message
+`)
+
+// redisAckScript moves the acknowledged sequence number of a user forward, never past the latest one.
+// It returns -1 when seq is ahead of the sequence counter.
+var redisAckScript = redis.NewScript(`
+local latest = tonumber(redis.call('GET', KEYS[1]) or '0')
+local seq = tonumber(ARGV[1])
+if seq > latest then
+	return -1
+end
+local acked = tonumber(redis.call('GET', KEYS[2]) or '-1')
+if seq > acked then
+	acked = seq
+	redis.call('SET', KEYS[2], acked, 'PX', ARGV[2])
+end
+return acked
+`)
+
+// RedisEventLog is an EventLog shared by all instances through Redis.
+// Logs of users without new events expire after ttl.
+type RedisEventLog struct {
+	Client *redis.Client
+	Size   int
+	TTL    time.Duration
+}
+
+// NewRedisEventLog creates a new RedisEventLog retaining size events per user.
+func NewRedisEventLog(client *redis.Client, size int, ttl time.Duration) *RedisEventLog {
+	return &RedisEventLog{
+		Client: client,
+		Size:   size,
+		TTL:    ttl,
+	}
+}
+
+// Append stores a message under the next sequence number of the user.
+func (l *RedisEventLog) Append(ctx context.Context, userID uint, message []byte) ([]byte, error) {
+	if err := checkMessage(message); err != nil {
+		return nil, err
+	}
+
+	return l.append(ctx, []string{redisSeqKey(userID), redisEventsKey(userID)}, message)
+}
+
+// AppendOnce stores a message under the next sequence number of the user unless its key was logged
+// within the ttl. An empty key is never remembered.
+func (l *RedisEventLog) AppendOnce(ctx context.Context, userID uint, key string, message []byte) ([]byte, error) {
+	if key == "" {
+		return l.Append(ctx, userID, message)
+	}
+	if err := checkMessage(message); err != nil {
+		return nil, err
+	}
+
+	return l.append(ctx, []string{redisSeqKey(userID), redisEventsKey(userID), redisLoggedKey(userID, key)}, message)
+}
+
+// append runs redisAppendScript for the given keys.
+func (l *RedisEventLog) append(ctx context.Context, keys []string, message []byte) ([]byte, error) {
+	logged, err := redisAppendScript.Run(ctx, l.Client, keys, message, l.Size, l.TTL.Milliseconds()).Text()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(logged), nil
+}
+
+// Since returns the retained messages of the user after seq.
+func (l *RedisEventLog) Since(ctx context.Context, userID uint, seq uint64) ([]LoggedEvent, uint64, error) {
+	var (
+		latestCmd *redis.StringCmd
+		eventsCmd *redis.ZSliceCmd
+	)
+	_, err := l.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		latestCmd = pipe.Get(ctx, redisSeqKey(userID))
+		eventsCmd = pipe.ZRangeByScoreWithScores(ctx, redisEventsKey(userID), &redis.ZRangeBy{
+			Min: "(" + strconv.FormatUint(seq, 10),
+			Max: "+inf",
+		})
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, 0, err
+	}
+
+	latest, err := latestCmd.Uint64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, 0, err
+	}
+
+	members := eventsCmd.Val()
+	events := make([]LoggedEvent, len(members))
+	for i, member := range members {
+		events[i] = LoggedEvent{
+			Seq:     uint64(member.Score),
+			Message: []byte(member.Member.(string)),
+		}
+	}
+
+	return events, latest, nil
+}
+
+// Ack moves the acknowledged sequence number of the user forward to seq.
+func (l *RedisEventLog) Ack(ctx context.Context, userID uint, seq uint64) (uint64, error) {
+	keys := []string{redisSeqKey(userID), redisAckedKey(userID)}
+	acked, err := redisAckScript.Run(ctx, l.Client, keys, seq, l.TTL.Milliseconds()).Int64()
+	if err != nil {
+		return 0, err
+	}
+	if acked < 0 {
+		return 0, ErrAckAhead
+	}
+	return uint64(acked), nil
+}
+
+// Acked returns the acknowledged sequence number of the user.
+func (l *RedisEventLog) Acked(ctx context.Context, userID uint) (uint64, bool, error) {
+	acked, err := l.Client.Get(ctx, redisAckedKey(userID)).Uint64()
+	if errors.Is(err, redis.Nil) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return acked, true, nil
+}
+
+func redisSeqKey(userID uint) string {
+	return fmt.Sprintf("websocket:seq:%d", userID)
+}
+
+func redisEventsKey(userID uint) string {
+	return fmt.Sprintf("websocket:events:%d", userID)
+}
+
+func redisAckedKey(userID uint) string {
+	return fmt.Sprintf("websocket:acked:%d", userID)
+}
+
+func redisLoggedKey(userID uint, key string) string {
+	return fmt.Sprintf("websocket:logged:%d:%s", userID, key)
+}
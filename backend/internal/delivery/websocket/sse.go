@@ -0,0 +1,200 @@
+package websocket
+
+import (
+	"backend/internal/model"
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// SSEHandler streams the Hub messages of a user as Server-Sent Events, for clients that cannot use WebSockets.
+// SSE clients are registered in the same Hub, so they get exactly the messages a WebSocket connection gets.
+type SSEHandler struct {
+	Hub          *Hub
+	Log          *logrus.Logger
+	ClientConfig ClientConfig
+}
+
+// NewSSEHandler creates a new SSEHandler.
+func NewSSEHandler(hub *Hub, log *logrus.Logger, clientConfig ClientConfig) *SSEHandler {
+	return &SSEHandler{
+		Hub:          hub,
+		Log:          log,
+		ClientConfig: clientConfig,
+	}
+}
+
+// Stream serves the event stream of the authenticated user.
+// Missed events are replayed after the Last-Event-ID header, or the last_event_id query parameter.
+func (h *SSEHandler) Stream(c *fiber.Ctx) error {
+	auth, ok := c.Locals("auth").(*model.Auth)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	var since *uint64
+	lastEventID := c.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	if lastEventID != "" {
+		seq, err := strconv.ParseUint(lastEventID, 10, 64)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid Last-Event-ID")
+		}
+		since = &seq
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	// Keep reverse proxies such as nginx from buffering the stream
+	c.Set("X-Accel-Buffering", "no")
+
+	userID := *auth.UserID
+	netConn := c.Context().Conn()
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		conn := newSSEConn(w, netConn)
+		client := NewClient(auth, conn, h.ClientConfig, h.Log)
+
+		// The writer is only valid until this function returns, so the client writer has to be stopped first
+		defer func() {
+			h.Hub.Unregister(client)
+			client.Close()
+			client.Wait()
+		}()
+
+		// Tell the browser how long to wait before reconnecting
+		if err := conn.SetWriteDeadline(time.Now().Add(h.ClientConfig.WriteWait)); err != nil {
+			return
+		}
+		if err := conn.writeRaw("retry: 3000\n\n"); err != nil {
+			return
+		}
+
+		if err := h.Hub.Connect(context.Background(), client, since); err != nil {
+			h.Log.Warnf("Failed to replay events to SSE client of user ID %d: %v", userID, err)
+			return
+		}
+
+		h.Log.Infof("SSE connection established for user ID: %d", userID)
+
+		// Disconnects surface as failed writes, at the latest on the next heartbeat
+		<-client.Done()
+
+		h.Log.Infof("SSE connection closed for user ID: %d", userID)
+	})
+
+	return nil
+}
+
+// errSSEClosed is returned when writing to a closed event stream.
+var errSSEClosed = errors.New("sse connection closed")
+
+// sseConn adapts a streamed HTTP response to the Conn used by a Client.
+// Text messages become events whose id is the message seq, pings become comments.
+// Write deadlines are set on the underlying connection, so a stalled client fails the write instead of blocking it.
+type sseConn struct {
+	// mu serializes writes to w
+	mu      sync.Mutex
+	w       *bufio.Writer
+	netConn deadlineConn
+	closed  atomic.Bool
+}
+
+// deadlineConn is the part of the underlying network connection an sseConn needs.
+type deadlineConn interface {
+	SetWriteDeadline(t time.Time) error
+}
+
+// newSSEConn creates an sseConn writing to w, netConn may be nil when the connection is unknown.
+func newSSEConn(w *bufio.Writer, netConn deadlineConn) *sseConn {
+	return &sseConn{w: w, netConn: netConn}
+}
+
+// WriteMessage writes a message as an event and flushes it to the client.
+func (c *sseConn) WriteMessage(messageType int, data []byte) error {
+	var envelope struct {
+		Seq uint64 `json:"seq"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return err
+	}
+
+	event := make([]byte, 0, len(data)+32)
+	if envelope.Seq > 0 {
+		event = append(event, "id: "...)
+		event = strconv.AppendUint(event, envelope.Seq, 10)
+		event = append(event, '\n')
+	}
+	event = append(event, "data: "...)
+	event = append(event, data...)
+	event = append(event, "\n\n"...)
+
+	return c.writeRaw(string(event))
+}
+
+// WriteControl writes a heartbeat comment for pings within deadline, other control messages are ignored.
+func (c *sseConn) WriteControl(messageType int, data []byte, deadline time.Time) error {
+	if messageType != websocket.PingMessage {
+		return nil
+	}
+	if err := c.SetWriteDeadline(deadline); err != nil {
+		return err
+	}
+	return c.writeRaw(": ping\n\n")
+}
+
+// SetWriteDeadline sets the write deadline of the underlying connection.
+func (c *sseConn) SetWriteDeadline(t time.Time) error {
+	if c.closed.Load() {
+		return errSSEClosed
+	}
+	if c.netConn == nil {
+		return nil
+	}
+	return c.netConn.SetWriteDeadline(t)
+}
+
+// SetReadDeadline is a no-op, SSE clients do not send anything.
+func (c *sseConn) SetReadDeadline(t time.Time) error {
+	return nil
+}
+
+// SetPongHandler is a no-op, SSE has no pongs.
+func (c *sseConn) SetPongHandler(h func(appData string) error) {}
+
+// Close stops further writes without waiting for a write in progress, the response ends when the stream writer returns.
+// A write blocked on a stalled client is failed right away by moving the write deadline into the past.
+func (c *sseConn) Close() error {
+	if !c.closed.CompareAndSwap(false, true) {
+		return nil
+	}
+	if c.netConn != nil {
+		return c.netConn.SetWriteDeadline(time.Now())
+	}
+	return nil
+}
+
+// writeRaw writes and flushes a chunk of the stream.
+func (c *sseConn) writeRaw(chunk string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed.Load() {
+		return errSSEClosed
+	}
+	if _, err := c.w.WriteString(chunk); err != nil {
+		return err
+	}
+	return c.w.Flush()
+}
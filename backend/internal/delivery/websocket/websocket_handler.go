@@ -1,27 +1,44 @@
 package websocket
 
 import (
+	"backend/internal/model"
+	"backend/internal/usecase"
 	"backend/internal/util"
+	"context"
+	"encoding/json"
+	"strconv"
 	"strings"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
 	"github.com/sirupsen/logrus"
 )
 
 // Handler handles WebSocket connections.
+// Sessions of a role in TwoFactorRoles that were not started with two-factor authentication are rejected.
 type Handler struct {
-	Hub       *Hub
-	TokenUtil *util.TokenUtil
-	Log       *logrus.Logger
+	Hub            *Hub
+	TokenUtil      *util.TokenUtil
+	UserUseCase    usecase.UserUseCaseInterface
+	WalletUseCase  usecase.WalletUseCaseInterface
+	Validate       *validator.Validate
+	Log            *logrus.Logger
+	ClientConfig   ClientConfig
+	TwoFactorRoles []string
 }
 
 // NewHandler creates a new WebSocket Handler.
-func NewHandler(hub *Hub, tokenUtil *util.TokenUtil, log *logrus.Logger) *Handler {
+func NewHandler(hub *Hub, tokenUtil *util.TokenUtil, userUseCase usecase.UserUseCaseInterface, walletUseCase usecase.WalletUseCaseInterface, validate *validator.Validate, log *logrus.Logger, clientConfig ClientConfig, twoFactorRoles []string) *Handler {
 	return &Handler{
-		Hub:       hub,
-		TokenUtil: tokenUtil,
-		Log:       log,
+		Hub:            hub,
+		TokenUtil:      tokenUtil,
+		UserUseCase:    userUseCase,
+		WalletUseCase:  walletUseCase,
+		Validate:       validate,
+		Log:            log,
+		ClientConfig:   clientConfig,
+		TwoFactorRoles: twoFactorRoles,
 	}
 }
 
@@ -51,6 +68,26 @@ func (h *Handler) UpgradeMiddleware() fiber.Handler {
 				return fiber.ErrUnauthorized
 			}
 
+			// Reject accounts that were deleted or disabled after the token was issued
+			if err := h.UserUseCase.CheckActive(c.UserContext(), *auth.UserID); err != nil {
+				return err
+			}
+
+			// Apply the same two-factor requirement as the HTTP routes
+			if auth.MissingTwoFactor(h.TwoFactorRoles) {
+				h.Log.Warnf("User ID %d with role %s has no two-factor authentication, WebSocket connection denied", *auth.UserID, auth.Role)
+				return fiber.NewError(fiber.StatusForbidden, "Two-factor authentication is required for your role, enable it at /users/me/2fa")
+			}
+
+			// Resume from the last sequence number the client received
+			if since := c.Query("since"); since != "" {
+				seq, err := strconv.ParseUint(since, 10, 64)
+				if err != nil {
+					return fiber.NewError(fiber.StatusBadRequest, "Invalid since parameter")
+				}
+				c.Locals("since", seq)
+			}
+
 			// Store auth in locals for the WebSocket handler
 			c.Locals("auth", auth)
 			c.Locals("userID", *auth.UserID)
@@ -64,20 +101,42 @@ func (h *Handler) UpgradeMiddleware() fiber.Handler {
 // HandleConnection handles WebSocket connections.
 func (h *Handler) HandleConnection() fiber.Handler {
 	return websocket.New(func(c *websocket.Conn) {
-		// Get user ID from locals
-		userID, ok := c.Locals("userID").(uint)
+		// Get user from locals
+		auth, ok := c.Locals("auth").(*model.Auth)
 		if !ok {
-			h.Log.Warn("Failed to get user ID from locals")
+			h.Log.Warn("Failed to get auth from locals")
+			return
+		}
+		userID := *auth.UserID
+
+		client := NewClient(auth, c, h.ClientConfig, h.Log)
+
+		// The connection is released once this handler returns, so the writer has to be stopped first
+		defer func() {
+			h.Hub.Unregister(client)
+			client.Close()
+			client.Wait()
+		}()
+
+		// Peers that stop answering pings are dropped once the read deadline passes
+		if err := client.PrepareRead(); err != nil {
+			h.Log.Warnf("Failed to set read deadline for user ID %d: %v", userID, err)
 			return
 		}
 
-		// Register connection
-		h.Hub.Register(userID, c)
-		defer h.Hub.Unregister(userID, c)
+		// Register connection, replaying missed events first
+		var since *uint64
+		if seq, ok := c.Locals("since").(uint64); ok {
+			since = &seq
+		}
+		if err := h.Hub.Connect(context.Background(), client, since); err != nil {
+			h.Log.Warnf("Failed to replay events to user ID %d: %v", userID, err)
+			return
+		}
 
 		h.Log.Infof("WebSocket connection established for user ID: %d", userID)
 
-		// Keep connection alive and handle messages
+		// Handle messages until the connection is closed, evicted or times out
 		for {
 			messageType, message, err := c.ReadMessage()
 			if err != nil {
@@ -87,17 +146,25 @@ func (h *Handler) HandleConnection() fiber.Handler {
 				break
 			}
 
-			// Handle ping/pong for heartbeat
-			if messageType == websocket.PingMessage {
-				if err := c.WriteMessage(websocket.PongMessage, nil); err != nil {
-					h.Log.Warnf("Failed to send pong to user ID %d: %v", userID, err)
-					break
-				}
+			// Any message shows the peer is alive
+			if err := client.ExtendReadDeadline(); err != nil {
+				break
+			}
+
+			if messageType != websocket.TextMessage {
 				continue
 			}
 
-			// Log received messages (for debugging)
+			// Run the command and reply with the same correlation ID
 			h.Log.Debugf("Received message from user ID %d: %s", userID, string(message))
+			response, err := json.Marshal(h.HandleCommand(context.Background(), client, message))
+			if err != nil {
+				h.Log.Errorf("Failed to marshal WebSocket response: %v", err)
+				continue
+			}
+			if !client.Send(response) {
+				break
+			}
 		}
 
 		h.Log.Infof("WebSocket connection closed for user ID: %d", userID)
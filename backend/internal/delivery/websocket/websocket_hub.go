@@ -1,88 +1,363 @@
 package websocket
 
 import (
+	"backend/internal/entity"
+	"backend/internal/model"
+	"context"
+	"encoding/json"
+	"slices"
 	"sync"
+	"time"
 
-	"github.com/gofiber/contrib/websocket"
 	"github.com/sirupsen/logrus"
 )
 
-// Hub maintains the set of active WebSocket connections and broadcasts messages to users.
+// brokerRetryDelay is the wait before subscribing again after the broker subscription failed.
+const brokerRetryDelay = time.Second
+
+// AdminFeedAuthorizer checks that the user of a connection may still receive the admin feed.
+type AdminFeedAuthorizer func(ctx context.Context, client *Client) error
+
+// Hub maintains the set of active WebSocket clients and broadcasts messages to users.
 type Hub struct {
-	// connections maps user ID to their WebSocket connections
-	connections map[uint][]*websocket.Conn
+	// clients maps user ID to their WebSocket clients
+	clients map[uint][]*Client
 	// mutex for thread-safe operations
 	mu sync.RWMutex
+	// broker fans messages out to all instances, nil keeps delivery in memory
+	broker Broker
+	// eventLog numbers messages per user and keeps them for replay, nil disables replay
+	eventLog EventLog
+	// authorizeAdminFeed re-checks admin feed subscribers once per adminFeedRecheck, nil only checks the role
+	authorizeAdminFeed AdminFeedAuthorizer
+	adminFeedRecheck   time.Duration
 	// logger
 	log *logrus.Logger
 }
 
-// NewHub creates a new WebSocket Hub.
-func NewHub(log *logrus.Logger) *Hub {
+// NewHub creates a new in-memory WebSocket Hub for single-node setups.
+func NewHub(log *logrus.Logger, eventLog EventLog) *Hub {
 	return &Hub{
-		connections: make(map[uint][]*websocket.Conn),
-		log:         log,
+		clients:  make(map[uint][]*Client),
+		eventLog: eventLog,
+		log:      log,
+	}
+}
+
+// NewHubWithBroker creates a new WebSocket Hub that publishes messages through a broker,
+// so users connected to any instance receive them. Run must be started to deliver them locally.
+// The event log has to be shared by all instances as well.
+func NewHubWithBroker(log *logrus.Logger, broker Broker, eventLog EventLog) *Hub {
+	hub := NewHub(log, eventLog)
+	hub.broker = broker
+	return hub
+}
+
+// SetAdminFeedAuthorizer makes the Hub re-check admin feed subscribers with authorize at most once per interval,
+// so a user who lost the permission stops receiving the feed even when the connection was not closed.
+// It must be called before the Hub is used.
+func (h *Hub) SetAdminFeedAuthorizer(authorize AdminFeedAuthorizer, interval time.Duration) {
+	h.authorizeAdminFeed = authorize
+	h.adminFeedRecheck = interval
+}
+
+// Run delivers messages received from the broker to local clients until ctx is cancelled.
+// It returns immediately for an in-memory Hub.
+func (h *Hub) Run(ctx context.Context) {
+	if h.broker == nil {
+		return
+	}
+
+	for {
+		err := h.broker.Subscribe(ctx, func(message *BrokerMessage) {
+			if message.Disconnect != nil {
+				h.disconnectLocal(message.Disconnect)
+				return
+			}
+			if message.AdminFeed {
+				h.deliverAdminFeed(message.Message)
+				return
+			}
+			h.deliverLocal(message.UserID, message.Message)
+		})
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(brokerRetryDelay):
+			h.log.Warnf("WebSocket broker subscription ended, resubscribing: %v", err)
+		}
+	}
+}
+
+// Register adds a new client for a user and starts its writer.
+func (h *Hub) Register(client *Client) {
+	h.register(client)
+	client.Start()
+}
+
+// Connect registers a new client. When since is given the events after it are replayed first,
+// otherwise the events after the acknowledged sequence number of the user, if the user acknowledged any.
+// The client stays registered when the replay fails, callers unregister it as any closed client.
+func (h *Hub) Connect(ctx context.Context, client *Client, since *uint64) error {
+	if h.eventLog == nil {
+		h.Register(client)
+		return nil
+	}
+
+	if since == nil {
+		acked, ok, err := h.eventLog.Acked(ctx, client.UserID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			h.Register(client)
+			return nil
+		}
+		since = &acked
+	}
+
+	return h.resume(ctx, client, *since)
+}
+
+// Ack records that the user received the events up to seq and returns the acknowledged sequence number.
+func (h *Hub) Ack(ctx context.Context, userID uint, seq uint64) (uint64, error) {
+	if h.eventLog == nil {
+		return 0, ErrEventLogDisabled
 	}
+	return h.eventLog.Ack(ctx, userID, seq)
 }
 
-// Register adds a new connection for a user.
-func (h *Hub) Register(userID uint, conn *websocket.Conn) {
+// resume registers a new client, then replays the events a user missed after since to it.
+// When the missed events are no longer retained a resync_required message is sent instead.
+// Registering first leaves no gap between replay and live delivery: live messages queue up until
+// the writer starts, and those the replay already wrote are dropped by their seq.
+// The lock is only held to register, reading the log and writing to the connection happen outside it.
+func (h *Hub) resume(ctx context.Context, client *Client, since uint64) error {
+	h.register(client)
+
+	last, err := h.replay(ctx, client, since)
+	if err != nil {
+		return err
+	}
+
+	client.skipThrough(last)
+	client.Start()
+	return nil
+}
+
+// register adds a client for a user, its writer is started by the caller.
+func (h *Hub) register(client *Client) {
 	h.mu.Lock()
-	defer h.mu.Unlock()
+	h.clients[client.UserID] = append(h.clients[client.UserID], client)
+	count := len(h.clients[client.UserID])
+	h.mu.Unlock()
+
+	h.log.Infof("WebSocket connection registered for user ID: %d, total connections: %d", client.UserID, count)
+}
+
+// replay writes the logged events of a user after since to the client and returns the last sequence number sent.
+// It writes directly, so it must run before the writer of the client is started.
+func (h *Hub) replay(ctx context.Context, client *Client, since uint64) (uint64, error) {
+	events, latest, err := h.eventLog.Since(ctx, client.UserID, since)
+	if err != nil {
+		return 0, err
+	}
+
+	if !replayComplete(since, latest, events) {
+		h.log.Infof("Events after seq %d are no longer retained for user ID: %d, requesting resync", since, client.UserID)
+
+		data, err := json.Marshal(model.WebSocketMessage{
+			Type: "resync_required",
+			Payload: &model.ResyncRequiredNotification{
+				Since:     since,
+				LatestSeq: latest,
+			},
+		})
+		if err != nil {
+			return 0, err
+		}
+		return latest, client.write(data)
+	}
+
+	for _, event := range events {
+		if err := client.write(event.Message); err != nil {
+			return 0, err
+		}
+		since = event.Seq
+	}
 
-	h.connections[userID] = append(h.connections[userID], conn)
-	h.log.Infof("WebSocket connection registered for user ID: %d, total connections: %d", userID, len(h.connections[userID]))
+	return since, nil
 }
 
-// Unregister removes a connection for a user.
-func (h *Hub) Unregister(userID uint, conn *websocket.Conn) {
+// Unregister removes a client of a user.
+func (h *Hub) Unregister(client *Client) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
-	conns := h.connections[userID]
-	for i, c := range conns {
-		if c == conn {
-			// Remove connection from slice
-			h.connections[userID] = append(conns[:i], conns[i+1:]...)
+	userID := client.UserID
+	clients := h.clients[userID]
+	for i, c := range clients {
+		if c == client {
+			// Remove client from slice
+			h.clients[userID] = append(clients[:i], clients[i+1:]...)
 			break
 		}
 	}
 
 	// Clean up empty user entries
-	if len(h.connections[userID]) == 0 {
-		delete(h.connections, userID)
+	if len(h.clients[userID]) == 0 {
+		delete(h.clients, userID)
 	}
 
 	h.log.Infof("WebSocket connection unregistered for user ID: %d", userID)
 }
 
-// BroadcastToUser sends a message to all connections of a specific user.
+// BroadcastToUser sends a message to all clients of a specific user.
+// With an event log the message gets the next sequence number of the user and is kept for replay.
+// With a broker the message is published to every instance instead of written directly.
 func (h *Hub) BroadcastToUser(userID uint, message []byte) error {
+	return h.BroadcastEventToUser(userID, "", message)
+}
+
+// BroadcastEventToUser sends a message identified by eventKey to all clients of a specific user.
+// A message broadcast again after a failed publish keeps the sequence number it was logged with first,
+// so clients that got it already drop it by seq. An empty eventKey behaves like BroadcastToUser.
+func (h *Hub) BroadcastEventToUser(userID uint, eventKey string, message []byte) error {
+	if h.eventLog != nil {
+		logged, err := h.eventLog.AppendOnce(context.Background(), userID, eventKey, message)
+		if err != nil {
+			return err
+		}
+		message = logged
+	}
+
+	if h.broker != nil {
+		return h.broker.Publish(context.Background(), &BrokerMessage{UserID: userID, Message: message})
+	}
+	h.deliverLocal(userID, message)
+	return nil
+}
+
+// Disconnect closes the selected connections of a user on every instance, so they stop receiving events
+// and have to reconnect with a valid token.
+func (h *Hub) Disconnect(request *model.DisconnectRequest) error {
+	if h.broker != nil {
+		return h.broker.Publish(context.Background(), &BrokerMessage{UserID: request.UserID, Disconnect: request})
+	}
+	h.disconnectLocal(request)
+	return nil
+}
+
+// disconnectLocal closes the selected clients of a user on this instance.
+// Closing ends the reader of the connection, which unregisters the client.
+func (h *Hub) disconnectLocal(request *model.DisconnectRequest) {
 	h.mu.RLock()
-	conns := h.connections[userID]
+	clients := slices.DeleteFunc(slices.Clone(h.clients[request.UserID]), func(client *Client) bool {
+		sessionID := client.Auth.FamilyID
+		return (request.SessionID != "" && sessionID != request.SessionID) ||
+			(request.KeepSessionID != "" && sessionID == request.KeepSessionID)
+	})
 	h.mu.RUnlock()
 
-	if len(conns) == 0 {
-		h.log.Debugf("No active connections for user ID: %d", userID)
-		return nil
+	for _, client := range clients {
+		client.Close()
+	}
+	if len(clients) > 0 {
+		h.log.Infof("Closed %d connections of user ID: %d", len(clients), request.UserID)
 	}
+}
 
-	var lastErr error
-	for _, conn := range conns {
-		if err := conn.WriteMessage(websocket.TextMessage, message); err != nil {
-			h.log.Warnf("Failed to send message to user ID %d: %v", userID, err)
-			lastErr = err
+// BroadcastToAdminFeed sends a system-wide transaction message to every client subscribed to the admin feed.
+// Admin feed messages are not numbered or kept for replay.
+func (h *Hub) BroadcastToAdminFeed(message []byte) error {
+	if h.broker != nil {
+		return h.broker.Publish(context.Background(), &BrokerMessage{AdminFeed: true, Message: message})
+	}
+	h.deliverAdminFeed(message)
+	return nil
+}
+
+// deliverAdminFeed queues an admin feed message for the local clients whose filter matches it.
+func (h *Hub) deliverAdminFeed(message []byte) {
+	var envelope struct {
+		Payload model.AdminTransactionNotification `json:"payload"`
+	}
+	if err := json.Unmarshal(message, &envelope); err != nil {
+		h.log.Warnf("Dropping invalid admin feed message: %v", err)
+		return
+	}
+
+	h.mu.RLock()
+	var clients []*Client
+	for _, userClients := range h.clients {
+		for _, client := range userClients {
+			if client.AcceptsAdminFeed(&envelope.Payload) {
+				clients = append(clients, client)
+			}
 		}
 	}
+	h.mu.RUnlock()
+
+	for _, client := range clients {
+		if h.adminFeedAllowed(client) {
+			client.Send(message)
+		}
+	}
+}
 
-	return lastErr
+// adminFeedAllowed checks the permission of an admin feed subscriber at fan-out, the role of the connection
+// every time and the current role and account state through authorizeAdminFeed when a check is due.
+// Subscribers that lost the permission are unsubscribed.
+func (h *Hub) adminFeedAllowed(client *Client) bool {
+	if !entity.RoleHasPermission(client.Role, entity.PermissionAdminFeedSubscribe) {
+		h.log.Warnf("Dropping admin feed subscription of user ID %d with role %s", client.UserID, client.Role)
+		client.SetAdminFeed(nil)
+		return false
+	}
+	if h.authorizeAdminFeed == nil || !client.adminFeedCheckDue(h.adminFeedRecheck) {
+		return true
+	}
+	if err := h.authorizeAdminFeed(context.Background(), client); err != nil {
+		h.log.Warnf("Dropping admin feed subscription of user ID %d: %v", client.UserID, err)
+		client.SetAdminFeed(nil)
+		return false
+	}
+	return true
+}
+
+// deliverLocal queues a message for the clients of a user on this instance that subscribed to its type.
+// It never blocks on a connection, clients that cannot keep up are closed and catch up through replay.
+func (h *Hub) deliverLocal(userID uint, message []byte) {
+	h.mu.RLock()
+	clients := slices.Clone(h.clients[userID])
+	h.mu.RUnlock()
+
+	if len(clients) == 0 {
+		h.log.Debugf("No active connections for user ID: %d", userID)
+		return
+	}
+
+	var envelope struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(message, &envelope); err != nil {
+		h.log.Warnf("Dropping invalid WebSocket message for user ID %d: %v", userID, err)
+		return
+	}
+
+	for _, client := range clients {
+		if client.Accepts(envelope.Type) {
+			client.Send(message)
+		}
+	}
 }
 
 // GetConnectionCount returns the number of active connections for a user.
 func (h *Hub) GetConnectionCount(userID uint) int {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
-	return len(h.connections[userID])
+	return len(h.clients[userID])
 }
 
 // GetTotalConnections returns the total number of active connections.
@@ -91,8 +366,8 @@ func (h *Hub) GetTotalConnections() int {
 	defer h.mu.RUnlock()
 
 	total := 0
-	for _, conns := range h.connections {
-		total += len(conns)
+	for _, clients := range h.clients {
+		total += len(clients)
 	}
 	return total
 }
@@ -0,0 +1,33 @@
+package entity
+
+import "time"
+
+// Actions recorded in the audit log, named resource.action.
+const (
+	AuditActionTransactionsView    = "transactions.view"
+	AuditActionWalletMutationsView = "wallet_mutations.view"
+)
+
+// Kinds of record an audited action is about.
+const (
+	AuditTargetUser   = "user"
+	AuditTargetWallet = "wallet"
+)
+
+// AuditLog records that a staff member accessed data of another user.
+// Entries are never updated or deleted, and outlive the users they mention.
+type AuditLog struct {
+	ID          uint      `gorm:"column:id;primaryKey;autoIncrement"`
+	ActorUserID uint      `gorm:"column:actor_user_id;not null"`
+	ActorRole   string    `gorm:"column:actor_role;type:varchar(20);not null"`
+	Action      string    `gorm:"column:action;type:varchar(50);not null"`
+	TargetType  string    `gorm:"column:target_type;type:varchar(20);not null"`
+	TargetID    uint      `gorm:"column:target_id;not null"`
+	IPAddress   string    `gorm:"column:ip_address;type:varchar(45);not null;default:''"`
+	UserAgent   string    `gorm:"column:user_agent;type:varchar(255);not null;default:''"`
+	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime;not null"`
+}
+
+func (a *AuditLog) TableName() string {
+	return "audit_logs"
+}
@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+// IdempotencyKey stores the outcome of a money movement request identified by a client supplied key.
+type IdempotencyKey struct {
+	ID            uint      `gorm:"column:id;primaryKey;autoIncrement"`
+	UserID        uint      `gorm:"column:user_id;not null"`
+	Key           string    `gorm:"column:idempotency_key;type:varchar(255);not null"`
+	RequestHash   string    `gorm:"column:request_hash;type:char(64);not null"`
+	TransactionID uint      `gorm:"column:transaction_id;not null"`
+	CreatedAt     time.Time `gorm:"column:created_at;autoCreateTime;not null"`
+
+	// Relations
+	User        *User        `gorm:"foreignKey:UserID;references:ID"`
+	Transaction *Transaction `gorm:"foreignKey:TransactionID;references:ID"`
+}
+
+func (ik *IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
@@ -0,0 +1,27 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Notification is an entry in a user's in-app inbox.
+// ReferenceID is the ID of the record the notification is about, such as a transaction,
+// and together with UserID and Type keeps a notification from being stored twice.
+type Notification struct {
+	ID          uint            `gorm:"column:id;primaryKey;autoIncrement"`
+	UserID      uint            `gorm:"column:user_id;not null"`
+	Type        string          `gorm:"column:type;type:varchar(50);not null"`
+	ReferenceID uint            `gorm:"column:reference_id;not null"`
+	Payload     json.RawMessage `gorm:"column:payload;type:json;not null"`
+	ReadAt      *time.Time      `gorm:"column:read_at"`
+	CreatedAt   time.Time       `gorm:"column:created_at;autoCreateTime;not null"`
+	UpdatedAt   time.Time       `gorm:"column:updated_at;autoUpdateTime;not null"`
+
+	// Relations
+	User *User `gorm:"foreignKey:UserID;references:ID"`
+}
+
+func (n *Notification) TableName() string {
+	return "notifications"
+}
@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OutboxEventType represents the kind of notification carried by an outbox event
+type OutboxEventType string
+
+const (
+	OutboxEventTypeTransaction  OutboxEventType = "transaction"
+	OutboxEventTypeWalletUpdate OutboxEventType = "wallet_update"
+	// OutboxEventTypeAdminTransaction is streamed to the admin feed, its user is the one who performed the transaction
+	OutboxEventTypeAdminTransaction OutboxEventType = "admin_transaction"
+)
+
+// OutboxEventStatus represents the delivery status of an outbox event
+type OutboxEventStatus string
+
+const (
+	OutboxEventStatusPending OutboxEventStatus = "pending"
+	OutboxEventStatusSent    OutboxEventStatus = "sent"
+	OutboxEventStatusFailed  OutboxEventStatus = "failed"
+)
+
+// OutboxEvent is a notification stored in the same database transaction as the change it describes,
+// delivered afterwards by the outbox dispatcher.
+type OutboxEvent struct {
+	ID            uint              `gorm:"column:id;primaryKey;autoIncrement"`
+	UserID        uint              `gorm:"column:user_id;not null"`
+	EventType     OutboxEventType   `gorm:"column:event_type;type:varchar(50);not null"`
+	Payload       json.RawMessage   `gorm:"column:payload;type:json;not null"`
+	Status        OutboxEventStatus `gorm:"column:status;type:enum('pending','sent','failed');not null;default:'pending'"`
+	Attempts      int               `gorm:"column:attempts;not null;default:0"`
+	LastError     *string           `gorm:"column:last_error;type:text"`
+	NextAttemptAt time.Time         `gorm:"column:next_attempt_at;not null"`
+	SentAt        *time.Time        `gorm:"column:sent_at"`
+	CreatedAt     time.Time         `gorm:"column:created_at;autoCreateTime;not null"`
+	UpdatedAt     time.Time         `gorm:"column:updated_at;autoUpdateTime;not null"`
+
+	// Relations
+	User *User `gorm:"foreignKey:UserID;references:ID"`
+}
+
+func (o *OutboxEvent) TableName() string {
+	return "outbox_events"
+}
@@ -0,0 +1,87 @@
+package entity
+
+import "slices"
+
+// Roles a user can have, the values of User.Role.
+const (
+	RoleSuperAdmin = "super_admin"
+	RoleAdmin      = "admin"
+	RoleUser       = "user"
+)
+
+// Permission is an action that only some roles may perform, named resource:action.
+type Permission string
+
+const (
+	PermissionTopUpCreate         Permission = "topup:create"
+	PermissionTreasuryTransfer    Permission = "treasury:transfer"
+	PermissionTransactionsSettle  Permission = "transactions:settle"
+	PermissionTransactionsReverse Permission = "transactions:reverse"
+	PermissionReconciliationRead  Permission = "reconciliation:read"
+	PermissionTransactionsRead    Permission = "transactions:read"
+	PermissionWalletMutationsRead Permission = "wallet_mutations:read"
+	PermissionUsersRead           Permission = "users:read"
+	PermissionUsersManageRoles    Permission = "users:manage_roles"
+	PermissionUsersDisable        Permission = "users:disable"
+	PermissionUsersLogout         Permission = "users:logout"
+	PermissionUsersResetPassword  Permission = "users:reset_password"
+	PermissionUsersUnlock         Permission = "users:unlock"
+	PermissionUsersManageAdmins   Permission = "users:manage_admins"
+	PermissionAdminFeedSubscribe  Permission = "admin_feed:subscribe"
+	PermissionWebhooksAllEvents   Permission = "webhooks:all_events"
+	PermissionMetricsRead         Permission = "metrics:read"
+)
+
+// RolePermissions maps every role to the permissions it grants.
+// Regular users only act on their own account and need none.
+// Admins look after users and the system but cannot create or move money on behalf of others.
+var RolePermissions = map[string][]Permission{
+	RoleSuperAdmin: {
+		PermissionTopUpCreate,
+		PermissionTreasuryTransfer,
+		PermissionTransactionsSettle,
+		PermissionTransactionsReverse,
+		PermissionReconciliationRead,
+		PermissionTransactionsRead,
+		PermissionWalletMutationsRead,
+		PermissionUsersRead,
+		PermissionUsersManageRoles,
+		PermissionUsersDisable,
+		PermissionUsersLogout,
+		PermissionUsersResetPassword,
+		PermissionUsersUnlock,
+		PermissionUsersManageAdmins,
+		PermissionAdminFeedSubscribe,
+		PermissionWebhooksAllEvents,
+		PermissionMetricsRead,
+	},
+	RoleAdmin: {
+		PermissionReconciliationRead,
+		PermissionTransactionsRead,
+		PermissionWalletMutationsRead,
+		PermissionUsersRead,
+		PermissionUsersDisable,
+		PermissionUsersLogout,
+		PermissionUsersResetPassword,
+		PermissionUsersUnlock,
+		PermissionMetricsRead,
+	},
+	RoleUser: {},
+}
+
+// RoleHasPermission reports whether a role grants a permission, unknown roles grant nothing.
+func RoleHasPermission(role string, permission Permission) bool {
+	return slices.Contains(RolePermissions[role], permission)
+}
+
+// RolesWithPermission returns the roles that grant a permission, sorted for stable queries.
+func RolesWithPermission(permission Permission) []string {
+	var roles []string
+	for role, permissions := range RolePermissions {
+		if slices.Contains(permissions, permission) {
+			roles = append(roles, role)
+		}
+	}
+	slices.Sort(roles)
+	return roles
+}
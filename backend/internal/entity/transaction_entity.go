@@ -13,6 +13,7 @@ const (
 	TransactionTypeTopUp    TransactionType = "top_up"
 	TransactionTypeTransfer TransactionType = "transfer"
 	TransactionTypeWithdraw TransactionType = "withdraw"
+	TransactionTypeReversal TransactionType = "reversal"
 )
 
 // TransactionStatus represents the status of transaction
@@ -24,22 +25,27 @@ const (
 	TransactionStatusFailed    TransactionStatus = "failed"
 )
 
+// LegacyIssue marks a transaction a super admin sent before the treasury existed:
+// it credited the recipient without debiting the sender, the treasury opening balance covers the money.
 type Transaction struct {
-	ID                uint              `gorm:"column:id;primaryKey;autoIncrement"`
-	Type              TransactionType   `gorm:"column:type;type:enum('top_up','transfer','withdraw');not null"`
-	Amount            decimal.Decimal   `gorm:"column:amount;type:decimal(20,2);not null"`
-	FromWalletID      *uint             `gorm:"column:from_wallet_id"`
-	ToWalletID        uint              `gorm:"column:to_wallet_id;not null"`
-	PerformedByUserID uint              `gorm:"column:performed_by_user_id;not null"`
-	Status            TransactionStatus `gorm:"column:status;type:enum('pending','completed','failed');not null;default:'pending'"`
-	Description       *string           `gorm:"column:description;type:varchar(255)"`
-	CreatedAt         time.Time         `gorm:"column:created_at;autoCreateTime;not null"`
-	UpdatedAt         time.Time         `gorm:"column:updated_at;autoUpdateTime;not null"`
+	ID                    uint              `gorm:"column:id;primaryKey;autoIncrement"`
+	Type                  TransactionType   `gorm:"column:type;type:enum('top_up','transfer','withdraw','reversal');not null"`
+	Amount                decimal.Decimal   `gorm:"column:amount;type:decimal(20,2);not null"`
+	FromWalletID          *uint             `gorm:"column:from_wallet_id"`
+	ToWalletID            *uint             `gorm:"column:to_wallet_id"`
+	PerformedByUserID     uint              `gorm:"column:performed_by_user_id;not null"`
+	ReversedTransactionID *uint             `gorm:"column:reversed_transaction_id"`
+	LegacyIssue           bool              `gorm:"column:legacy_issue;not null;default:false"`
+	Status                TransactionStatus `gorm:"column:status;type:enum('pending','completed','failed');not null;default:'pending'"`
+	Description           *string           `gorm:"column:description;type:varchar(255)"`
+	CreatedAt             time.Time         `gorm:"column:created_at;autoCreateTime;not null"`
+	UpdatedAt             time.Time         `gorm:"column:updated_at;autoUpdateTime;not null"`
 
 	// Relations
-	FromWallet      *Wallet `gorm:"foreignKey:FromWalletID;references:ID"`
-	ToWallet        *Wallet `gorm:"foreignKey:ToWalletID;references:ID"`
-	PerformedByUser *User   `gorm:"foreignKey:PerformedByUserID;references:ID"`
+	FromWallet          *Wallet      `gorm:"foreignKey:FromWalletID;references:ID"`
+	ToWallet            *Wallet      `gorm:"foreignKey:ToWalletID;references:ID"`
+	PerformedByUser     *User        `gorm:"foreignKey:PerformedByUserID;references:ID"`
+	ReversedTransaction *Transaction `gorm:"foreignKey:ReversedTransactionID;references:ID"`
 }
 
 func (t *Transaction) TableName() string {
@@ -2,15 +2,43 @@ package entity
 
 import "time"
 
+// User is an account of the application.
+// TransactionPIN is the bcrypt hash of the PIN that authorizes outgoing money, empty until the user sets one.
+// TOTPSecret is the encrypted TOTP secret, set during enrollment and only in effect once TOTPEnabledAt is set.
+// TOTPLastStep is the last accepted TOTP time step, a code is never accepted twice.
+// DisabledAt is set while an admin has disabled the account, it can then neither log in nor use existing sessions.
+// System is set on accounts the application owns, like the owner of the treasury wallet.
+// Nobody can log in as them and admins can neither list nor manage them.
 type User struct {
-	ID        uint      `gorm:"column:id;primaryKey;autoIncrement"`
-	Username  string    `gorm:"column:username;type:varchar(100);uniqueIndex;not null"`
-	Password  string    `gorm:"column:password;type:varchar(255);not null"`
-	Role      string    `gorm:"column:role;type:enum('super_admin','admin','user');not null;default:'user'"`
-	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime;not null"`
-	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime;not null"`
+	ID             uint       `gorm:"column:id;primaryKey;autoIncrement"`
+	Username       string     `gorm:"column:username;type:varchar(100);uniqueIndex;not null"`
+	Password       string     `gorm:"column:password;type:varchar(255);not null"`
+	TransactionPIN string     `gorm:"column:transaction_pin;type:varchar(255);not null;default:''"`
+	Role           string     `gorm:"column:role;type:enum('super_admin','admin','user');not null;default:'user'"`
+	System         bool       `gorm:"column:is_system;not null;default:false"`
+	TOTPSecret     string     `gorm:"column:totp_secret;type:varchar(255);not null;default:''"`
+	TOTPEnabledAt  *time.Time `gorm:"column:totp_enabled_at"`
+	TOTPLastStep   int64      `gorm:"column:totp_last_step;not null;default:0"`
+	DisabledAt     *time.Time `gorm:"column:disabled_at"`
+	CreatedAt      time.Time  `gorm:"column:created_at;autoCreateTime;not null"`
+	UpdatedAt      time.Time  `gorm:"column:updated_at;autoUpdateTime;not null"`
 }
 
 func (u *User) TableName() string {
 	return "users"
 }
+
+// TwoFactorEnabled reports whether the user confirmed TOTP enrollment.
+func (u *User) TwoFactorEnabled() bool {
+	return u.TOTPEnabledAt != nil
+}
+
+// TransactionPINSet reports whether the user set a transaction PIN.
+func (u *User) TransactionPINSet() bool {
+	return u.TransactionPIN != ""
+}
+
+// Disabled reports whether an admin disabled the account.
+func (u *User) Disabled() bool {
+	return u.DisabledAt != nil
+}
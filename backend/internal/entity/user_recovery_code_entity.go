@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+// UserRecoveryCode is a one-time code that replaces a TOTP code when the authenticator is lost.
+// Only the SHA-256 of the code is stored.
+type UserRecoveryCode struct {
+	ID        uint       `gorm:"column:id;primaryKey;autoIncrement"`
+	UserID    uint       `gorm:"column:user_id;not null"`
+	CodeHash  string     `gorm:"column:code_hash;type:char(64);not null"`
+	UsedAt    *time.Time `gorm:"column:used_at"`
+	CreatedAt time.Time  `gorm:"column:created_at;autoCreateTime;not null"`
+
+	// Relations
+	User *User `gorm:"foreignKey:UserID;references:ID"`
+}
+
+func (c *UserRecoveryCode) TableName() string {
+	return "user_recovery_codes"
+}
@@ -6,12 +6,23 @@ import (
 	"github.com/shopspring/decimal"
 )
 
+// WalletType represents the type of wallet
+type WalletType string
+
+const (
+	WalletTypeUser     WalletType = "user"
+	WalletTypeTreasury WalletType = "treasury"
+)
+
 type Wallet struct {
-	ID        uint            `gorm:"column:id;primaryKey;autoIncrement"`
-	UserID    uint            `gorm:"column:user_id;uniqueIndex;not null"`
-	Balance   decimal.Decimal `gorm:"column:balance;type:decimal(20,2);not null;default:0.00"`
-	CreatedAt time.Time       `gorm:"column:created_at;autoCreateTime;not null"`
-	UpdatedAt time.Time       `gorm:"column:updated_at;autoUpdateTime;not null"`
+	ID          uint            `gorm:"column:id;primaryKey;autoIncrement"`
+	UserID      uint            `gorm:"column:user_id;uniqueIndex;not null"`
+	Type        WalletType      `gorm:"column:type;type:enum('user','treasury');not null;default:'user'"`
+	Balance     decimal.Decimal `gorm:"column:balance;type:decimal(20,2);not null;default:0.00"`
+	HeldBalance decimal.Decimal `gorm:"column:held_balance;type:decimal(20,2);not null;default:0.00"`
+	Version     uint64          `gorm:"column:version;not null;default:0"`
+	CreatedAt   time.Time       `gorm:"column:created_at;autoCreateTime;not null"`
+	UpdatedAt   time.Time       `gorm:"column:updated_at;autoUpdateTime;not null"`
 
 	// Relations
 	User *User `gorm:"foreignKey:UserID;references:ID"`
@@ -20,3 +31,8 @@ type Wallet struct {
 func (w *Wallet) TableName() string {
 	return "wallets"
 }
+
+// AvailableBalance returns the balance that is not held by pending withdrawals.
+func (w *Wallet) AvailableBalance() decimal.Decimal {
+	return w.Balance.Sub(w.HeldBalance)
+}
@@ -0,0 +1,60 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookDeliveryStatus represents the delivery status of a webhook delivery
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryStatusSucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryStatusFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookEndpoint is a URL registered by a user to receive events over HTTP.
+// EventTypes holds the subscribed outbox event types as a comma separated MySQL SET.
+type WebhookEndpoint struct {
+	ID         uint      `gorm:"column:id;primaryKey;autoIncrement"`
+	UserID     uint      `gorm:"column:user_id;not null"`
+	URL        string    `gorm:"column:url;type:varchar(2048);not null"`
+	Secret     string    `gorm:"column:secret;type:varchar(64);not null"`
+	EventTypes string    `gorm:"column:event_types;type:set('transaction','wallet_update');not null"`
+	IsActive   bool      `gorm:"column:is_active;not null;default:true"`
+	CreatedAt  time.Time `gorm:"column:created_at;autoCreateTime;not null"`
+	UpdatedAt  time.Time `gorm:"column:updated_at;autoUpdateTime;not null"`
+
+	// Relations
+	User *User `gorm:"foreignKey:UserID;references:ID"`
+}
+
+func (w *WebhookEndpoint) TableName() string {
+	return "webhook_endpoints"
+}
+
+// WebhookDelivery is a single event sent, or to be sent, to a webhook endpoint.
+type WebhookDelivery struct {
+	ID                uint                  `gorm:"column:id;primaryKey;autoIncrement"`
+	WebhookEndpointID uint                  `gorm:"column:webhook_endpoint_id;not null"`
+	OutboxEventID     uint                  `gorm:"column:outbox_event_id;not null"`
+	EventType         OutboxEventType       `gorm:"column:event_type;type:varchar(50);not null"`
+	Payload           json.RawMessage       `gorm:"column:payload;type:json;not null"`
+	Status            WebhookDeliveryStatus `gorm:"column:status;type:enum('pending','succeeded','failed');not null;default:'pending'"`
+	Attempts          int                   `gorm:"column:attempts;not null;default:0"`
+	ResponseStatus    *int                  `gorm:"column:response_status"`
+	LastError         *string               `gorm:"column:last_error;type:text"`
+	NextAttemptAt     time.Time             `gorm:"column:next_attempt_at;not null"`
+	DeliveredAt       *time.Time            `gorm:"column:delivered_at"`
+	CreatedAt         time.Time             `gorm:"column:created_at;autoCreateTime;not null"`
+	UpdatedAt         time.Time             `gorm:"column:updated_at;autoUpdateTime;not null"`
+
+	// Relations
+	WebhookEndpoint *WebhookEndpoint `gorm:"foreignKey:WebhookEndpointID;references:ID"`
+	OutboxEvent     *OutboxEvent     `gorm:"foreignKey:OutboxEventID;references:ID"`
+}
+
+func (w *WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
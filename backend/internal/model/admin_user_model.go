@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+// AdminUserSearchRequest represents the filters and paging of the admin user list.
+// Query matches part of the username, an empty Role or nil Disabled does not filter.
+type AdminUserSearchRequest struct {
+	Query    string `json:"query" validate:"omitempty,max=100,alphanum"`
+	Role     string `json:"role" validate:"omitempty,oneof=super_admin admin user"`
+	Disabled *bool  `json:"disabled"`
+	Page     int    `json:"page" validate:"min=1"`
+	Limit    int    `json:"limit" validate:"min=1,max=100"`
+}
+
+// AdminUserResponse represents a user as seen by admins.
+type AdminUserResponse struct {
+	ID                uint       `json:"id"`
+	Username          string     `json:"username"`
+	Role              string     `json:"role"`
+	TwoFactorEnabled  bool       `json:"two_factor_enabled"`
+	TransactionPINSet bool       `json:"transaction_pin_set"`
+	DisabledAt        *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// AdminUserListResponse represents the response payload for the admin user list.
+type AdminUserListResponse struct {
+	Users []AdminUserResponse `json:"users"`
+	Total int64               `json:"total"`
+	Page  int                 `json:"page"`
+	Limit int                 `json:"limit"`
+}
+
+// AdminUserDetailResponse represents a user with their wallet and number of active sessions.
+type AdminUserDetailResponse struct {
+	AdminUserResponse
+	Wallet         *WalletResponse `json:"wallet,omitempty"`
+	ActiveSessions int             `json:"active_sessions"`
+}
+
+// ChangeRoleRequest represents the payload for changing the role of a user.
+type ChangeRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=super_admin admin user"`
+}
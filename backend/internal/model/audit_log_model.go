@@ -0,0 +1,12 @@
+package model
+
+// AuditLogRequest describes an access to record in the audit log.
+type AuditLogRequest struct {
+	ActorUserID uint   `validate:"required"`
+	ActorRole   string `validate:"required,max=20"`
+	Action      string `validate:"required,max=50"`
+	TargetType  string `validate:"required,max=20"`
+	TargetID    uint   `validate:"required"`
+	IPAddress   string `validate:"max=45"`
+	UserAgent   string
+}
@@ -1,10 +1,34 @@
 package model
 
-import "github.com/golang-jwt/jwt/v5"
+import (
+	"slices"
+	"time"
 
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Auth holds the claims of an access token.
+// FamilyID links the token to the refresh token family it was issued with.
+// TwoFactor is set when the user has two-factor authentication enabled, so the session passed it.
 type Auth struct {
-	UserID   *uint  `json:"user_id"`
-	Username string `json:"username"`
-	Role     string `json:"role"`
+	UserID    *uint  `json:"user_id"`
+	Username  string `json:"username"`
+	Role      string `json:"role"`
+	FamilyID  string `json:"fid,omitempty"`
+	TwoFactor bool   `json:"tfa,omitempty"`
 	jwt.RegisteredClaims
 }
+
+// MissingTwoFactor reports whether the role of the session requires two-factor authentication
+// but the session was not started with it.
+func (a *Auth) MissingTwoFactor(requiredRoles []string) bool {
+	return slices.Contains(requiredRoles, a.Role) && !a.TwoFactor
+}
+
+// TokenPair is a short-lived access token together with the refresh token that renews it.
+type TokenPair struct {
+	AccessToken           string
+	AccessTokenExpiresAt  time.Time
+	RefreshToken          string
+	RefreshTokenExpiresAt time.Time
+}
@@ -0,0 +1,26 @@
+package converter
+
+import (
+	"backend/internal/entity"
+	"backend/internal/model"
+)
+
+func NotificationToNotificationResponse(notification *entity.Notification) *model.NotificationResponse {
+	return &model.NotificationResponse{
+		ID:          notification.ID,
+		Type:        notification.Type,
+		ReferenceID: notification.ReferenceID,
+		Payload:     notification.Payload,
+		IsRead:      notification.ReadAt != nil,
+		ReadAt:      notification.ReadAt,
+		CreatedAt:   notification.CreatedAt,
+	}
+}
+
+func NotificationsToNotificationResponses(notifications []entity.Notification) []model.NotificationResponse {
+	responses := make([]model.NotificationResponse, len(notifications))
+	for i, notification := range notifications {
+		responses[i] = *NotificationToNotificationResponse(&notification)
+	}
+	return responses
+}
@@ -7,15 +7,16 @@ import (
 
 func TransactionToTransactionResponse(transaction *entity.Transaction) *model.TransactionResponse {
 	return &model.TransactionResponse{
-		ID:                transaction.ID,
-		Type:              string(transaction.Type),
-		Amount:            transaction.Amount,
-		FromWalletID:      transaction.FromWalletID,
-		ToWalletID:        transaction.ToWalletID,
-		PerformedByUserID: transaction.PerformedByUserID,
-		Status:            string(transaction.Status),
-		Description:       transaction.Description,
-		CreatedAt:         transaction.CreatedAt,
+		ID:                    transaction.ID,
+		Type:                  string(transaction.Type),
+		Amount:                transaction.Amount,
+		FromWalletID:          transaction.FromWalletID,
+		ToWalletID:            transaction.ToWalletID,
+		PerformedByUserID:     transaction.PerformedByUserID,
+		ReversedTransactionID: transaction.ReversedTransactionID,
+		Status:                string(transaction.Status),
+		Description:           transaction.Description,
+		CreatedAt:             transaction.CreatedAt,
 	}
 }
 
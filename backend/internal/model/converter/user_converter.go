@@ -5,10 +5,34 @@ import (
 	"backend/internal/model"
 )
 
-func UserToUserResponse(user *entity.User, token string) *model.UserResponse {
+func UserToUserResponse(user *entity.User, tokens *model.TokenPair) *model.UserResponse {
 	return &model.UserResponse{
-		ID:       user.ID,
-		Username: user.Username,
-		Token:    token,
+		ID:                    user.ID,
+		Username:              user.Username,
+		Token:                 tokens.AccessToken,
+		TokenExpiresAt:        tokens.AccessTokenExpiresAt,
+		RefreshToken:          tokens.RefreshToken,
+		RefreshTokenExpiresAt: tokens.RefreshTokenExpiresAt,
 	}
 }
+
+func UserToAdminUserResponse(user *entity.User) *model.AdminUserResponse {
+	return &model.AdminUserResponse{
+		ID:                user.ID,
+		Username:          user.Username,
+		Role:              user.Role,
+		TwoFactorEnabled:  user.TwoFactorEnabled(),
+		TransactionPINSet: user.TransactionPINSet(),
+		DisabledAt:        user.DisabledAt,
+		CreatedAt:         user.CreatedAt,
+		UpdatedAt:         user.UpdatedAt,
+	}
+}
+
+func UsersToAdminUserResponses(users []entity.User) []model.AdminUserResponse {
+	responses := make([]model.AdminUserResponse, len(users))
+	for i, user := range users {
+		responses[i] = *UserToAdminUserResponse(&user)
+	}
+	return responses
+}
@@ -7,8 +7,9 @@ import (
 
 func WalletToWalletResponse(wallet *entity.Wallet) *model.WalletResponse {
 	return &model.WalletResponse{
-		ID:      wallet.ID,
-		UserID:  wallet.UserID,
-		Balance: wallet.Balance,
+		ID:          wallet.ID,
+		UserID:      wallet.UserID,
+		Balance:     wallet.Balance,
+		HeldBalance: wallet.HeldBalance,
 	}
 }
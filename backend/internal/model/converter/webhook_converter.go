@@ -0,0 +1,54 @@
+package converter
+
+import (
+	"backend/internal/entity"
+	"backend/internal/model"
+	"strings"
+)
+
+func WebhookEndpointToWebhookResponse(endpoint *entity.WebhookEndpoint) *model.WebhookResponse {
+	eventTypes := []string{}
+	if endpoint.EventTypes != "" {
+		eventTypes = strings.Split(endpoint.EventTypes, ",")
+	}
+	return &model.WebhookResponse{
+		ID:         endpoint.ID,
+		URL:        endpoint.URL,
+		EventTypes: eventTypes,
+		IsActive:   endpoint.IsActive,
+		CreatedAt:  endpoint.CreatedAt,
+	}
+}
+
+func WebhookEndpointsToWebhookResponses(endpoints []entity.WebhookEndpoint) []model.WebhookResponse {
+	responses := make([]model.WebhookResponse, len(endpoints))
+	for i, endpoint := range endpoints {
+		responses[i] = *WebhookEndpointToWebhookResponse(&endpoint)
+	}
+	return responses
+}
+
+func WebhookDeliveryToWebhookDeliveryResponse(delivery *entity.WebhookDelivery) *model.WebhookDeliveryResponse {
+	return &model.WebhookDeliveryResponse{
+		ID:             delivery.ID,
+		WebhookID:      delivery.WebhookEndpointID,
+		EventID:        delivery.OutboxEventID,
+		EventType:      string(delivery.EventType),
+		Payload:        delivery.Payload,
+		Status:         string(delivery.Status),
+		Attempts:       delivery.Attempts,
+		ResponseStatus: delivery.ResponseStatus,
+		LastError:      delivery.LastError,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		DeliveredAt:    delivery.DeliveredAt,
+		CreatedAt:      delivery.CreatedAt,
+	}
+}
+
+func WebhookDeliveriesToWebhookDeliveryResponses(deliveries []entity.WebhookDelivery) []model.WebhookDeliveryResponse {
+	responses := make([]model.WebhookDeliveryResponse, len(deliveries))
+	for i, delivery := range deliveries {
+		responses[i] = *WebhookDeliveryToWebhookDeliveryResponse(&delivery)
+	}
+	return responses
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// NotificationResponse represents the response payload for an inbox notification.
+// Payload holds the notification as it was pushed over the WebSocket.
+type NotificationResponse struct {
+	ID          uint            `json:"id"`
+	Type        string          `json:"type"`
+	ReferenceID uint            `json:"reference_id"`
+	Payload     json.RawMessage `json:"payload"`
+	IsRead      bool            `json:"is_read"`
+	ReadAt      *time.Time      `json:"read_at,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// NotificationListResponse represents the response payload for the notification inbox.
+type NotificationListResponse struct {
+	Notifications []NotificationResponse `json:"notifications"`
+	Total         int64                  `json:"total"`
+	Page          int                    `json:"page"`
+	Limit         int                    `json:"limit"`
+}
+
+// UnreadNotificationCountResponse represents the response payload for the unread notification count.
+type UnreadNotificationCountResponse struct {
+	UnreadCount int64 `json:"unread_count"`
+}
+
+// MarkNotificationsRequest represents the request payload for marking notifications read or unread in bulk.
+// Either IDs or All must be set.
+type MarkNotificationsRequest struct {
+	IDs []uint `json:"ids" validate:"required_without=All,max=100,dive,gt=0"`
+	All bool   `json:"all"`
+}
+
+// MarkNotificationsResponse represents the response payload for a bulk read or unread update.
+type MarkNotificationsResponse struct {
+	Updated     int64 `json:"updated"`
+	UnreadCount int64 `json:"unread_count"`
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Reconciliation discrepancy codes.
+const (
+	DiscrepancyBalanceMismatch     = "balance_mismatch"
+	DiscrepancyBrokenChain         = "broken_chain"
+	DiscrepancyMutationArithmetic  = "mutation_arithmetic"
+	DiscrepancyDebitMismatch       = "debit_mismatch"
+	DiscrepancyCreditMismatch      = "credit_mismatch"
+	DiscrepancyUnexpectedMutations = "unexpected_mutations"
+)
+
+// ReconciliationReport represents the result of a ledger integrity check.
+type ReconciliationReport struct {
+	GeneratedAt         time.Time                   `json:"generated_at"`
+	Consistent          bool                        `json:"consistent"`
+	WalletsChecked      int                         `json:"wallets_checked"`
+	TransactionsChecked int                         `json:"transactions_checked"`
+	TotalBalance        decimal.Decimal             `json:"total_balance"`
+	Wallets             []WalletReconciliation      `json:"wallets"`
+	Transactions        []TransactionReconciliation `json:"transactions"`
+}
+
+// WalletReconciliation represents the discrepancies found for a single wallet.
+type WalletReconciliation struct {
+	WalletID           uint                        `json:"wallet_id"`
+	UserID             uint                        `json:"user_id"`
+	Balance            decimal.Decimal             `json:"balance"`
+	LatestBalanceAfter *decimal.Decimal            `json:"latest_balance_after"`
+	Discrepancies      []ReconciliationDiscrepancy `json:"discrepancies"`
+}
+
+// TransactionReconciliation represents the discrepancies found for a single transaction.
+type TransactionReconciliation struct {
+	TransactionID uint                        `json:"transaction_id"`
+	Type          string                      `json:"type"`
+	Status        string                      `json:"status"`
+	Amount        decimal.Decimal             `json:"amount"`
+	Discrepancies []ReconciliationDiscrepancy `json:"discrepancies"`
+}
+
+// ReconciliationDiscrepancy represents a single ledger inconsistency.
+type ReconciliationDiscrepancy struct {
+	Code          string          `json:"code"`
+	MutationID    *uint           `json:"mutation_id,omitempty"`
+	TransactionID *uint           `json:"transaction_id,omitempty"`
+	Expected      decimal.Decimal `json:"expected"`
+	Actual        decimal.Decimal `json:"actual"`
+	Message       string          `json:"message"`
+}
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// SessionInfo describes the device a session is started from.
+type SessionInfo struct {
+	DeviceName string
+	UserAgent  string
+	IPAddress  string
+}
+
+// SessionResponse represents the response payload for an active session.
+// ID is the refresh token family of the session, Current marks the session of the request.
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	Device     string    `json:"device"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	Current    bool      `json:"current"`
+}
+
+// RevokeSessionsResponse represents the response payload for revoking the other sessions of a user.
+type RevokeSessionsResponse struct {
+	Revoked int `json:"revoked"`
+}
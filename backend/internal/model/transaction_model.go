@@ -8,29 +8,57 @@ import (
 
 // TopUpRequest represents the request payload for top-up operation (super admin only).
 type TopUpRequest struct {
-	ToUserID    uint            `json:"to_user_id" validate:"required"`
-	Amount      decimal.Decimal `json:"amount" validate:"required"`
-	Description string          `json:"description"`
+	ToUserID       uint            `json:"to_user_id" validate:"required"`
+	Amount         decimal.Decimal `json:"amount" validate:"required"`
+	Description    string          `json:"description"`
+	IdempotencyKey string          `json:"-" validate:"max=255"`
 }
 
 // TransferRequest represents the request payload for transfer operation.
+// PIN is the transaction PIN of the sender.
 type TransferRequest struct {
-	ToUserID    uint            `json:"to_user_id" validate:"required"`
-	Amount      decimal.Decimal `json:"amount" validate:"required"`
-	Description string          `json:"description"`
+	ToUserID       uint            `json:"to_user_id" validate:"required"`
+	Amount         decimal.Decimal `json:"amount" validate:"required"`
+	Description    string          `json:"description"`
+	PIN            string          `json:"pin" validate:"required,numeric,len=6"`
+	IdempotencyKey string          `json:"-" validate:"max=255"`
+}
+
+// WithdrawRequest represents the request payload for withdraw operation.
+// PIN is the transaction PIN of the user.
+type WithdrawRequest struct {
+	Amount         decimal.Decimal `json:"amount" validate:"required"`
+	Description    string          `json:"description"`
+	PIN            string          `json:"pin" validate:"required,numeric,len=6"`
+	IdempotencyKey string          `json:"-" validate:"max=255"`
+}
+
+// SettleWithdrawRequest represents the request payload for settling a pending withdraw (super admin only).
+type SettleWithdrawRequest struct {
+	TransactionID uint   `json:"-" validate:"required"`
+	Status        string `json:"status" validate:"required,oneof=completed failed"`
+}
+
+// ReverseTransactionRequest represents the request payload for reversing a transaction (super admin only).
+// A zero amount reverses whatever is left of the original transaction.
+type ReverseTransactionRequest struct {
+	TransactionID uint            `json:"-" validate:"required"`
+	Amount        decimal.Decimal `json:"amount"`
+	Description   string          `json:"description"`
 }
 
 // TransactionResponse represents the response payload for transaction-related operations.
 type TransactionResponse struct {
-	ID                uint            `json:"id"`
-	Type              string          `json:"type"`
-	Amount            decimal.Decimal `json:"amount"`
-	FromWalletID      *uint           `json:"from_wallet_id,omitempty"`
-	ToWalletID        uint            `json:"to_wallet_id"`
-	PerformedByUserID uint            `json:"performed_by_user_id"`
-	Status            string          `json:"status"`
-	Description       *string         `json:"description,omitempty"`
-	CreatedAt         time.Time       `json:"created_at"`
+	ID                    uint            `json:"id"`
+	Type                  string          `json:"type"`
+	Amount                decimal.Decimal `json:"amount"`
+	FromWalletID          *uint           `json:"from_wallet_id,omitempty"`
+	ToWalletID            *uint           `json:"to_wallet_id,omitempty"`
+	PerformedByUserID     uint            `json:"performed_by_user_id"`
+	ReversedTransactionID *uint           `json:"reversed_transaction_id,omitempty"`
+	Status                string          `json:"status"`
+	Description           *string         `json:"description,omitempty"`
+	CreatedAt             time.Time       `json:"created_at"`
 }
 
 // TransactionListRequest represents the request for listing transactions.
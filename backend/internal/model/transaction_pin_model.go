@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// TransactionPINRequest represents the payload for setting a transaction PIN, or resetting a forgotten one,
+// confirmed with the account password.
+type TransactionPINRequest struct {
+	Password string `json:"password" validate:"required,max=255"`
+	PIN      string `json:"pin" validate:"required,numeric,len=6"`
+}
+
+// ChangeTransactionPINRequest represents the payload for changing a transaction PIN with the current one.
+type ChangeTransactionPINRequest struct {
+	CurrentPIN string `json:"current_pin" validate:"required,numeric,len=6"`
+	NewPIN     string `json:"new_pin" validate:"required,numeric,len=6,nefield=CurrentPIN"`
+}
+
+// TransactionPINStatusResponse represents whether the current user set a transaction PIN
+// and, after too many wrong PINs, until when it is locked.
+type TransactionPINStatusResponse struct {
+	IsSet       bool       `json:"is_set"`
+	LockedUntil *time.Time `json:"locked_until,omitempty"`
+}
@@ -0,0 +1,44 @@
+package model
+
+import "time"
+
+// TwoFactorStatusResponse represents the two-factor authentication state of the current user.
+// Required is set for roles that may not use the API without two-factor authentication.
+type TwoFactorStatusResponse struct {
+	Enabled                bool       `json:"enabled"`
+	EnabledAt              *time.Time `json:"enabled_at,omitempty"`
+	Required               bool       `json:"required"`
+	RecoveryCodesRemaining int64      `json:"recovery_codes_remaining"`
+}
+
+// TwoFactorSetupResponse represents a new TOTP secret waiting to be confirmed.
+// ProvisioningURI is the otpauth:// URI to show as a QR code, Secret is for manual entry.
+type TwoFactorSetupResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// TwoFactorCodeRequest represents the payload carrying a code from the authenticator app.
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required,numeric,len=6"`
+}
+
+// TwoFactorDisableRequest represents the payload for turning two-factor authentication off.
+// Code is a code from the authenticator app or a recovery code.
+type TwoFactorDisableRequest struct {
+	Password string `json:"password" validate:"required,max=255"`
+	Code     string `json:"code" validate:"required,max=32"`
+}
+
+// RecoveryCodesResponse represents newly generated recovery codes, they are only returned once.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// TwoFactorLoginRequest represents the payload for the second login step.
+// Code is a code from the authenticator app or a recovery code.
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required,max=255"`
+	Code           string `json:"code" validate:"required,max=32"`
+	IPAddress      string `json:"-"`
+}
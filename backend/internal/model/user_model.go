@@ -1,17 +1,29 @@
 package model
 
-import "github.com/shopspring/decimal"
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
 
 // UserRegistrationRequest represents the payload for user registration.
+// DeviceName optionally labels the session, UserAgent and IPAddress are filled in from the request.
 type UserRegistrationRequest struct {
-	Username string `json:"username" validate:"required,min=3,max=100,alphanum"`
-	Password string `json:"password" validate:"required,min=8,max=255"`
+	Username   string `json:"username" validate:"required,min=3,max=100,alphanum"`
+	Password   string `json:"password" validate:"required,min=8,max=255"`
+	DeviceName string `json:"device_name" validate:"max=100"`
+	UserAgent  string `json:"-"`
+	IPAddress  string `json:"-"`
 }
 
 // UserLoginRequest represents the payload for user login.
+// DeviceName optionally labels the session, UserAgent and IPAddress are filled in from the request.
 type UserLoginRequest struct {
-	Username string `json:"username" validate:"required,min=3,max=100,alphanum"`
-	Password string `json:"password" validate:"required,min=8,max=255"`
+	Username   string `json:"username" validate:"required,min=3,max=100,alphanum"`
+	Password   string `json:"password" validate:"required,min=8,max=255"`
+	DeviceName string `json:"device_name" validate:"max=100"`
+	UserAgent  string `json:"-"`
+	IPAddress  string `json:"-"`
 }
 
 // VerifyUserRequest represents the payload for user verification in auth middleware.
@@ -19,19 +31,62 @@ type VerifyUserRequest struct {
 	Token string `validate:"required,max=255"`
 }
 
-// UserResponse represents the response payload for user-related operations. (e.g., registration, login)
+// RefreshTokenRequest represents the payload for rotating a token pair.
+// The refresh token may also come from the refresh_token cookie.
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required,max=255"`
+	IPAddress    string `json:"-"`
+}
+
+// ChangePasswordRequest represents the payload for changing the password of the authenticated user.
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required,max=255"`
+	NewPassword     string `json:"new_password" validate:"required,min=8,max=255,nefield=CurrentPassword"`
+}
+
+// ResetPasswordRequest represents the payload for redeeming a password reset token.
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required,max=255"`
+	NewPassword string `json:"new_password" validate:"required,min=8,max=255"`
+}
+
+// PasswordResetTokenResponse represents the response payload for an issued password reset token.
+// The token is only returned once, the admin hands it to the user out of band.
+type PasswordResetTokenResponse struct {
+	UserID    uint      `json:"user_id"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// UserResponse represents the response payload for user-related operations. (e.g., registration, login, refresh)
+// Token is the short-lived access token, RefreshToken renews it through POST /users/refresh.
 type UserResponse struct {
-	ID       uint   `json:"id"`
-	Username string `json:"username"`
-	Token    string `json:"token"`
+	ID                    uint      `json:"id"`
+	Username              string    `json:"username"`
+	Token                 string    `json:"token"`
+	TokenExpiresAt        time.Time `json:"token_expires_at"`
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
+}
+
+// LoginResponse represents the response payload for the password step of a login.
+// Users without two-factor authentication get their tokens right away in the embedded UserResponse.
+// Otherwise UserResponse is nil and ChallengeToken completes the login through POST /users/login/2fa.
+type LoginResponse struct {
+	*UserResponse
+	TwoFactorRequired  bool       `json:"two_factor_required"`
+	ChallengeToken     string     `json:"challenge_token,omitempty"`
+	ChallengeExpiresAt *time.Time `json:"challenge_expires_at,omitempty"`
 }
 
 // UserProfileResponse represents the response payload for user profile with wallet information.
 type UserProfileResponse struct {
-	ID       uint                   `json:"id"`
-	Username string                 `json:"username"`
-	Role     string                 `json:"role"`
-	Wallet   *UserProfileWalletInfo `json:"wallet,omitempty"`
+	ID                uint                   `json:"id"`
+	Username          string                 `json:"username"`
+	Role              string                 `json:"role"`
+	TwoFactorEnabled  bool                   `json:"two_factor_enabled"`
+	TransactionPINSet bool                   `json:"transaction_pin_set"`
+	Wallet            *UserProfileWalletInfo `json:"wallet,omitempty"`
 }
 
 // UserProfileWalletInfo represents wallet information in user profile response.
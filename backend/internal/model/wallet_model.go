@@ -4,9 +4,10 @@ import "github.com/shopspring/decimal"
 
 // WalletResponse represents the response payload for wallet-related operations.
 type WalletResponse struct {
-	ID      uint            `json:"id"`
-	UserID  uint            `json:"user_id"`
-	Balance decimal.Decimal `json:"balance"`
+	ID          uint            `json:"id"`
+	UserID      uint            `json:"user_id"`
+	Balance     decimal.Decimal `json:"balance"`
+	HeldBalance decimal.Decimal `json:"held_balance"`
 }
 
 // GetWalletRequest represents the request for getting wallet information.
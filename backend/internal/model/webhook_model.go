@@ -0,0 +1,57 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookEvent is the request body posted to webhook endpoints.
+// ID identifies the event and stays the same across retries and redeliveries.
+type WebhookEvent struct {
+	ID        uint            `json:"id"`
+	Type      string          `json:"type"`
+	UserID    uint            `json:"user_id"`
+	CreatedAt time.Time       `json:"created_at"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// CreateWebhookRequest represents the request payload for registering a webhook endpoint.
+type CreateWebhookRequest struct {
+	URL        string   `json:"url" validate:"required,https_url,max=2048"`
+	EventTypes []string `json:"event_types" validate:"required,min=1,dive,oneof=transaction wallet_update"`
+}
+
+// WebhookResponse represents the response payload for a webhook endpoint.
+// Secret is only returned when the endpoint is created.
+type WebhookResponse struct {
+	ID         uint      `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	IsActive   bool      `json:"is_active"`
+	Secret     string    `json:"secret,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// WebhookDeliveryResponse represents the response payload for a webhook delivery.
+type WebhookDeliveryResponse struct {
+	ID             uint            `json:"id"`
+	WebhookID      uint            `json:"webhook_id"`
+	EventID        uint            `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	ResponseStatus *int            `json:"response_status,omitempty"`
+	LastError      *string         `json:"last_error,omitempty"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// WebhookDeliveryListResponse represents the response payload for webhook delivery list.
+type WebhookDeliveryListResponse struct {
+	Deliveries []WebhookDeliveryResponse `json:"deliveries"`
+	Total      int64                     `json:"total"`
+	Page       int                       `json:"page"`
+	Limit      int                       `json:"limit"`
+}
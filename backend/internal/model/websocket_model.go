@@ -1,15 +1,55 @@
 package model
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
 
 // WebSocketMessage represents a generic WebSocket message.
+// Seq is the per-user sequence number, set by the Hub on notifications kept for replay.
 type WebSocketMessage struct {
+	Seq     uint64      `json:"seq,omitempty"`
 	Type    string      `json:"type"`
 	Payload interface{} `json:"payload"`
 }
 
+// DisconnectRequest selects the open WebSocket and SSE connections of a user to close,
+// for example after the user was disabled or a session was revoked.
+// SessionID only selects the connections opened with that session, an empty SessionID selects every session.
+// KeepSessionID leaves the connections of that session open.
+type DisconnectRequest struct {
+	UserID        uint   `json:"user_id"`
+	SessionID     string `json:"session_id,omitempty"`
+	KeepSessionID string `json:"keep_session_id,omitempty"`
+}
+
+// AdminTransactionNotification represents a system-wide transaction streamed to the admin feed.
+type AdminTransactionNotification struct {
+	TransactionID       uint    `json:"transaction_id"`
+	TransactionType     string  `json:"transaction_type"`
+	Amount              string  `json:"amount"`
+	FromUserID          *uint   `json:"from_user_id,omitempty"`
+	FromUsername        *string `json:"from_username,omitempty"`
+	ToUserID            uint    `json:"to_user_id"`
+	ToUsername          string  `json:"to_username"`
+	PerformedByUserID   uint    `json:"performed_by_user_id"`
+	PerformedByUsername string  `json:"performed_by_username"`
+	Description         *string `json:"description,omitempty"`
+	CreatedAt           string  `json:"created_at"`
+}
+
+// ResyncRequiredNotification tells a reconnecting client that missed events are no longer retained.
+type ResyncRequiredNotification struct {
+	Since     uint64 `json:"since"`
+	LatestSeq uint64 `json:"latest_seq"`
+}
+
 // TransactionNotification represents a notification for transaction events.
+// NotificationID is the inbox entry of the notification, set when it is pushed to the user.
 type TransactionNotification struct {
+	NotificationID    uint    `json:"notification_id,omitempty"`
 	TransactionID     uint    `json:"transaction_id"`
 	TransactionType   string  `json:"transaction_type"`
 	Amount            string  `json:"amount"`
@@ -30,3 +70,64 @@ type WalletUpdateNotification struct {
 	Amount        string    `json:"amount"`
 	UpdatedAt     time.Time `json:"updated_at"`
 }
+
+// WebSocketCommand represents a command sent by a client over the WebSocket.
+// The ID is echoed in the response so the client can correlate them.
+type WebSocketCommand struct {
+	ID      string          `json:"id" validate:"required,max=64"`
+	Type    string          `json:"type" validate:"required"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// WebSocketResponse represents the response to a WebSocketCommand.
+// Type is "response" on success and "error" on failure.
+type WebSocketResponse struct {
+	ID      string          `json:"id"`
+	Type    string          `json:"type"`
+	Command string          `json:"command"`
+	Payload interface{}     `json:"payload,omitempty"`
+	Error   *WebSocketError `json:"error,omitempty"`
+}
+
+// WebSocketError describes why a WebSocketCommand failed.
+type WebSocketError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// AckCommandRequest acknowledges the notifications up to and including Seq.
+type AckCommandRequest struct {
+	Seq uint64 `json:"seq" validate:"required"`
+}
+
+// AckCommandResponse represents the acknowledged sequence number of the user.
+type AckCommandResponse struct {
+	Seq uint64 `json:"seq"`
+}
+
+// SubscriptionCommandRequest represents the event types to subscribe to or unsubscribe from.
+type SubscriptionCommandRequest struct {
+	EventTypes []string `json:"event_types" validate:"required,min=1,dive,oneof=transaction wallet_update"`
+}
+
+// SubscriptionCommandResponse represents the event types a connection is subscribed to.
+type SubscriptionCommandResponse struct {
+	EventTypes []string `json:"event_types"`
+}
+
+// PingCommandResponse represents the reply to a ping command.
+type PingCommandResponse struct {
+	Time time.Time `json:"time"`
+}
+
+// AdminFeedCommandRequest represents the filters of an admin feed subscription.
+type AdminFeedCommandRequest struct {
+	MinAmount        decimal.Decimal `json:"min_amount"`
+	TransactionTypes []string        `json:"transaction_types" validate:"omitempty,dive,oneof=top_up transfer"`
+}
+
+// AdminFeedCommandResponse represents the active admin feed filters.
+type AdminFeedCommandResponse struct {
+	MinAmount        decimal.Decimal `json:"min_amount"`
+	TransactionTypes []string        `json:"transaction_types"`
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"backend/internal/entity"
+	"errors"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type IdempotencyKeyRepository struct {
+	Repository[entity.IdempotencyKey]
+	Log *logrus.Logger
+}
+
+func NewIdempotencyKeyRepository(log *logrus.Logger) *IdempotencyKeyRepository {
+	return &IdempotencyKeyRepository{
+		Log: log,
+	}
+}
+
+// FindByUserIDAndKey finds the stored idempotency key of a user.
+func (r *IdempotencyKeyRepository) FindByUserIDAndKey(db *gorm.DB, userID uint, key string) (*entity.IdempotencyKey, error) {
+	var idempotencyKey entity.IdempotencyKey
+	err := db.Where("user_id = ? AND idempotency_key = ?", userID, key).First(&idempotencyKey).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &idempotencyKey, err
+}
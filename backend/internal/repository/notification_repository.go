@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"backend/internal/entity"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationRepository struct {
+	Repository[entity.Notification]
+	Log *logrus.Logger
+}
+
+func NewNotificationRepository(log *logrus.Logger) *NotificationRepository {
+	return &NotificationRepository{
+		Log: log,
+	}
+}
+
+// CreateOrFind inserts a notification, or loads the one already stored for the same user, type and reference.
+func (r *NotificationRepository) CreateOrFind(db *gorm.DB, notification *entity.Notification) error {
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(notification).Error; err != nil {
+		return err
+	}
+	if notification.ID != 0 {
+		return nil
+	}
+	return db.Where("user_id = ? AND type = ? AND reference_id = ?", notification.UserID, notification.Type, notification.ReferenceID).
+		Take(notification).Error
+}
+
+// FindByUserID returns the notifications of a user, newest first.
+func (r *NotificationRepository) FindByUserID(db *gorm.DB, userID uint, unreadOnly bool, page, limit int) ([]entity.Notification, int64, error) {
+	var notifications []entity.Notification
+	var total int64
+
+	query := db.Model(&entity.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	err = query.Order("id DESC").Offset(offset).Limit(limit).Find(&notifications).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return notifications, total, nil
+}
+
+// FindByIDAndUserID finds a notification of a user.
+func (r *NotificationRepository) FindByIDAndUserID(db *gorm.DB, id uint, userID uint) (*entity.Notification, error) {
+	var notification entity.Notification
+	err := db.Where("id = ? AND user_id = ?", id, userID).First(&notification).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &notification, err
+}
+
+// CountUnread returns how many notifications of a user are unread.
+func (r *NotificationRepository) CountUnread(db *gorm.DB, userID uint) (int64, error) {
+	var count int64
+	err := db.Model(&entity.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
+
+// SetReadAt marks notifications of a user read at readAt, or unread when readAt is nil.
+// Without ids every notification of the user is updated. Notifications already in the
+// requested state are left untouched, the number of changed rows is returned.
+func (r *NotificationRepository) SetReadAt(db *gorm.DB, userID uint, ids []uint, readAt *time.Time) (int64, error) {
+	query := db.Model(&entity.Notification{}).Where("user_id = ?", userID)
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+	if readAt != nil {
+		query = query.Where("read_at IS NULL")
+	} else {
+		query = query.Where("read_at IS NOT NULL")
+	}
+
+	result := query.Update("read_at", readAt)
+	return result.RowsAffected, result.Error
+}
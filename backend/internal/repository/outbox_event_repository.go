@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"backend/internal/entity"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OutboxEventRepository struct {
+	Repository[entity.OutboxEvent]
+	Log *logrus.Logger
+}
+
+func NewOutboxEventRepository(log *logrus.Logger) *OutboxEventRepository {
+	return &OutboxEventRepository{
+		Log: log,
+	}
+}
+
+// ClaimDue locks up to limit pending events that are due for delivery, oldest first, and pushes their next attempt
+// to leaseUntil, so no other dispatcher picks them up while they are in flight.
+// Rows locked by another dispatcher are skipped so several instances can run side by side.
+func (r *OutboxEventRepository) ClaimDue(db *gorm.DB, now time.Time, leaseUntil time.Time, limit int) ([]entity.OutboxEvent, error) {
+	var events []entity.OutboxEvent
+	err := db.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("status = ? AND next_attempt_at <= ?", entity.OutboxEventStatusPending, now).
+		Order("id ASC").
+		Limit(limit).
+		Find(&events).Error
+	if err != nil || len(events) == 0 {
+		return events, err
+	}
+
+	ids := make([]uint, len(events))
+	for i, event := range events {
+		ids[i] = event.ID
+	}
+	if err := db.Model(&entity.OutboxEvent{}).Where("id IN ?", ids).Update("next_attempt_at", leaseUntil).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// MarkSent records a successful delivery.
+func (r *OutboxEventRepository) MarkSent(db *gorm.DB, id uint, sentAt time.Time) error {
+	return db.Model(&entity.OutboxEvent{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":   entity.OutboxEventStatusSent,
+		"attempts": gorm.Expr("attempts + 1"),
+		"sent_at":  sentAt,
+	}).Error
+}
+
+// MarkAttemptFailed records a failed delivery and when to try again.
+// The event is given up on by passing the failed status.
+func (r *OutboxEventRepository) MarkAttemptFailed(db *gorm.DB, id uint, status entity.OutboxEventStatus, lastError string, nextAttemptAt time.Time) error {
+	return db.Model(&entity.OutboxEvent{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":          status,
+		"attempts":        gorm.Expr("attempts + 1"),
+		"last_error":      lastError,
+		"next_attempt_at": nextAttemptAt,
+	}).Error
+}
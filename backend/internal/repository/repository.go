@@ -1,6 +1,18 @@
 package repository
 
-import "gorm.io/gorm"
+import (
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+// MySQL error numbers the application reacts to.
+const (
+	mysqlErrDuplicateEntry   = 1062
+	mysqlErrLockWaitTimeout  = 1205
+	mysqlErrDeadlockDetected = 1213
+)
 
 // Repository is a generic repository struct for handling data operations.
 // Can access methods if needed.
@@ -29,3 +41,22 @@ func (r *Repository[T]) CountById(db *gorm.DB, id any) (int, error) {
 func (r *Repository[T]) FindByID(db *gorm.DB, entity *T, id any) error {
 	return db.Where("id = ?", id).Take(entity).Error
 }
+
+// IsDuplicateKeyError reports whether err is a unique constraint violation.
+func IsDuplicateKeyError(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry
+}
+
+// IsRetryableError reports whether err is a transient concurrency failure,
+// after which the whole database transaction can safely be run again.
+func IsRetryableError(err error) bool {
+	if errors.Is(err, ErrVersionConflict) {
+		return true
+	}
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return false
+	}
+	return mysqlErr.Number == mysqlErrDeadlockDetected || mysqlErr.Number == mysqlErrLockWaitTimeout
+}
@@ -2,9 +2,12 @@ package repository
 
 import (
 	"backend/internal/entity"
+	"errors"
 
+	"github.com/shopspring/decimal"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TransactionRepository struct {
@@ -59,3 +62,39 @@ func (r *TransactionRepository) FindByUserID(db *gorm.DB, userID uint, page, lim
 
 	return transactions, total, nil
 }
+
+// UpdateStatus moves a transaction from one status to another.
+// It returns the number of affected rows, which is zero when the transaction is no longer in the expected status.
+func (r *TransactionRepository) UpdateStatus(db *gorm.DB, transactionID uint, from entity.TransactionStatus, to entity.TransactionStatus) (int64, error) {
+	result := db.Model(&entity.Transaction{}).
+		Where("id = ? AND status = ?", transactionID, from).
+		Update("status", to)
+	return result.RowsAffected, result.Error
+}
+
+// LockForUpdate loads a transaction and locks its row until the surrounding database transaction ends.
+func (r *TransactionRepository) LockForUpdate(db *gorm.DB, transactionID uint) (*entity.Transaction, error) {
+	var transaction entity.Transaction
+	err := db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", transactionID).First(&transaction).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &transaction, err
+}
+
+// SumReversedAmount returns the total amount already reversed for a transaction.
+func (r *TransactionRepository) SumReversedAmount(db *gorm.DB, transactionID uint) (decimal.Decimal, error) {
+	var total decimal.Decimal
+	err := db.Model(&entity.Transaction{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("reversed_transaction_id = ? AND type = ? AND status = ?", transactionID, entity.TransactionTypeReversal, entity.TransactionStatusCompleted).
+		Row().Scan(&total)
+	return total, err
+}
+
+// FindPageAfterID returns up to limit transactions with an ID above afterID, ordered by ID.
+func (r *TransactionRepository) FindPageAfterID(db *gorm.DB, afterID uint, limit int) ([]entity.Transaction, error) {
+	var transactions []entity.Transaction
+	err := db.Where("id > ?", afterID).Order("id ASC").Limit(limit).Find(&transactions).Error
+	return transactions, err
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"backend/internal/entity"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type UserRecoveryCodeRepository struct {
+	Repository[entity.UserRecoveryCode]
+	Log *logrus.Logger
+}
+
+func NewUserRecoveryCodeRepository(log *logrus.Logger) *UserRecoveryCodeRepository {
+	return &UserRecoveryCodeRepository{
+		Log: log,
+	}
+}
+
+// ReplaceByUserID deletes the recovery codes of a user and stores new ones from their hashes.
+func (r *UserRecoveryCodeRepository) ReplaceByUserID(db *gorm.DB, userID uint, codeHashes []string) error {
+	if err := r.DeleteByUserID(db, userID); err != nil {
+		return err
+	}
+
+	codes := make([]entity.UserRecoveryCode, len(codeHashes))
+	for i, codeHash := range codeHashes {
+		codes[i] = entity.UserRecoveryCode{UserID: userID, CodeHash: codeHash}
+	}
+	return db.Create(&codes).Error
+}
+
+// DeleteByUserID deletes every recovery code of a user.
+func (r *UserRecoveryCodeRepository) DeleteByUserID(db *gorm.DB, userID uint) error {
+	return db.Where("user_id = ?", userID).Delete(&entity.UserRecoveryCode{}).Error
+}
+
+// Use marks an unused recovery code of a user as used and reports whether there was one.
+// The conditional update makes a code usable only once, even by concurrent requests.
+func (r *UserRecoveryCodeRepository) Use(db *gorm.DB, userID uint, codeHash string, usedAt time.Time) (bool, error) {
+	result := db.Model(&entity.UserRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", usedAt)
+	return result.RowsAffected == 1, result.Error
+}
+
+// CountUnused counts the recovery codes of a user that were not used yet.
+func (r *UserRecoveryCodeRepository) CountUnused(db *gorm.DB, userID uint) (int64, error) {
+	var count int64
+	err := db.Model(&entity.UserRecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
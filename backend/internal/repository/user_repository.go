@@ -3,6 +3,7 @@ package repository
 import (
 	"backend/internal/entity"
 	"errors"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
@@ -40,3 +41,104 @@ func (r *UserRepository) FindByID(db *gorm.DB, id uint) (*entity.User, error) {
 
 	return &user, err
 }
+
+// Search returns the users whose username contains query, optionally of one role and disabled or not, by ID.
+// An empty query, role or nil disabled does not filter. System accounts are never returned.
+func (r *UserRepository) Search(db *gorm.DB, query string, role string, disabled *bool, page, limit int) ([]entity.User, int64, error) {
+	var users []entity.User
+	var total int64
+
+	q := db.Model(&entity.User{}).Where("is_system = ?", false)
+	if query != "" {
+		q = q.Where("username LIKE ?", "%"+query+"%")
+	}
+	if role != "" {
+		q = q.Where("role = ?", role)
+	}
+	if disabled != nil {
+		if *disabled {
+			q = q.Where("disabled_at IS NOT NULL")
+		} else {
+			q = q.Where("disabled_at IS NULL")
+		}
+	}
+
+	err := q.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	err = q.Order("id ASC").Offset(offset).Limit(limit).Find(&users).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+// IsDisabled reports whether a user exists and whether it is disabled, with a lookup light enough for every request.
+func (r *UserRepository) IsDisabled(db *gorm.DB, id uint) (exists bool, disabled bool, err error) {
+	var user entity.User
+	err = db.Select("id", "disabled_at").Where("id = ?", id).Take(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, err
+	}
+	return true, user.Disabled(), nil
+}
+
+// UpdateRole changes the role of a user.
+func (r *UserRepository) UpdateRole(db *gorm.DB, id uint, role string) error {
+	return db.Model(&entity.User{}).Where("id = ?", id).Update("role", role).Error
+}
+
+// UpdateDisabledAt disables a user from disabledAt, or enables it again when disabledAt is nil.
+func (r *UserRepository) UpdateDisabledAt(db *gorm.DB, id uint, disabledAt *time.Time) error {
+	return db.Model(&entity.User{}).Where("id = ?", id).Update("disabled_at", disabledAt).Error
+}
+
+// UpdatePassword replaces the password hash of a user.
+func (r *UserRepository) UpdatePassword(db *gorm.DB, id uint, passwordHash string) error {
+	return db.Model(&entity.User{}).Where("id = ?", id).Update("password", passwordHash).Error
+}
+
+// UpdateTransactionPIN replaces the transaction PIN hash of a user.
+func (r *UserRepository) UpdateTransactionPIN(db *gorm.DB, id uint, pinHash string) error {
+	return db.Model(&entity.User{}).Where("id = ?", id).Update("transaction_pin", pinHash).Error
+}
+
+// UpdateTOTPSecret stores a pending TOTP secret for a user, two-factor authentication stays disabled until confirmed.
+func (r *UserRepository) UpdateTOTPSecret(db *gorm.DB, id uint, encryptedSecret string) error {
+	return db.Model(&entity.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"totp_secret":     encryptedSecret,
+		"totp_enabled_at": nil,
+		"totp_last_step":  0,
+	}).Error
+}
+
+// EnableTOTP turns on two-factor authentication for a user and records the step of the confirming code.
+func (r *UserRepository) EnableTOTP(db *gorm.DB, id uint, enabledAt time.Time, step int64) error {
+	return db.Model(&entity.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"totp_enabled_at": enabledAt,
+		"totp_last_step":  step,
+	}).Error
+}
+
+// DisableTOTP turns off two-factor authentication for a user and forgets the secret.
+func (r *UserRepository) DisableTOTP(db *gorm.DB, id uint) error {
+	return db.Model(&entity.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"totp_secret":     "",
+		"totp_enabled_at": nil,
+		"totp_last_step":  0,
+	}).Error
+}
+
+// UseTOTPStep records a TOTP time step as used and reports whether it was newer than the last one.
+// A code can only be redeemed once, even by concurrent requests.
+func (r *UserRepository) UseTOTPStep(db *gorm.DB, id uint, step int64) (bool, error) {
+	result := db.Model(&entity.User{}).Where("id = ? AND totp_last_step < ?", id, step).Update("totp_last_step", step)
+	return result.RowsAffected == 1, result.Error
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"backend/internal/entity"
 
+	"github.com/shopspring/decimal"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
@@ -43,3 +44,37 @@ func (r *WalletMutationRepository) FindByTransactionID(db *gorm.DB, transactionI
 	err := db.Where("transaction_id = ?", transactionID).Find(&mutations).Error
 	return mutations, err
 }
+
+// FindChainsAfter returns up to limit mutations of the given wallets ordered by wallet and booking order,
+// starting after mutation afterID of wallet afterWalletID. Pages through the full mutation chains of the wallets.
+func (r *WalletMutationRepository) FindChainsAfter(db *gorm.DB, walletIDs []uint, afterWalletID uint, afterID uint, limit int) ([]entity.WalletMutation, error) {
+	var mutations []entity.WalletMutation
+	err := db.Where("wallet_id IN ?", walletIDs).
+		Where("wallet_id > ? OR (wallet_id = ? AND id > ?)", afterWalletID, afterWalletID, afterID).
+		Order("wallet_id ASC, id ASC").
+		Limit(limit).
+		Find(&mutations).Error
+	return mutations, err
+}
+
+// TransactionMutationTotal holds the summed debit and credit mutations of a transaction.
+type TransactionMutationTotal struct {
+	TransactionID uint
+	Debit         decimal.Decimal
+	Credit        decimal.Decimal
+}
+
+// SumByTransactionIDs returns the summed debit and credit mutations of the given transactions.
+// Transactions without mutations are left out.
+func (r *WalletMutationRepository) SumByTransactionIDs(db *gorm.DB, transactionIDs []uint) ([]TransactionMutationTotal, error) {
+	var totals []TransactionMutationTotal
+	err := db.Model(&entity.WalletMutation{}).
+		Select("transaction_id, "+
+			"COALESCE(SUM(CASE WHEN type = ? THEN amount ELSE 0 END), 0) AS debit, "+
+			"COALESCE(SUM(CASE WHEN type = ? THEN amount ELSE 0 END), 0) AS credit",
+			entity.MutationTypeDebit, entity.MutationTypeCredit).
+		Where("transaction_id IN ?", transactionIDs).
+		Group("transaction_id").
+		Scan(&totals).Error
+	return totals, err
+}
@@ -4,10 +4,15 @@ import (
 	"backend/internal/entity"
 	"errors"
 
+	"github.com/shopspring/decimal"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrVersionConflict is returned when a wallet was modified after it was read.
+var ErrVersionConflict = errors.New("wallet version conflict")
+
 type WalletRepository struct {
 	Repository[entity.Wallet]
 	Log *logrus.Logger
@@ -28,15 +33,67 @@ func (r *WalletRepository) FindByUserID(db *gorm.DB, userID uint) (*entity.Walle
 	return &wallet, err
 }
 
-func (r *WalletRepository) UpdateBalance(db *gorm.DB, walletID uint, newBalance interface{}) error {
-	return db.Model(&entity.Wallet{}).Where("id = ?", walletID).Update("balance", newBalance).Error
+// FindTreasury finds the system treasury wallet that issues all money.
+func (r *WalletRepository) FindTreasury(db *gorm.DB) (*entity.Wallet, error) {
+	var wallet entity.Wallet
+	err := db.Where("type = ?", entity.WalletTypeTreasury).First(&wallet).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &wallet, err
+}
+
+// UpdateBalance sets the wallet balance if the wallet still has the version it was loaded with.
+// It returns ErrVersionConflict when another writer changed the wallet in the meantime.
+func (r *WalletRepository) UpdateBalance(db *gorm.DB, wallet *entity.Wallet, newBalance decimal.Decimal) error {
+	if err := r.compareAndSwap(db, wallet, "balance", newBalance); err != nil {
+		return err
+	}
+	wallet.Balance = newBalance
+	return nil
+}
+
+// UpdateHeldBalance sets the amount of the wallet balance held by pending withdrawals.
+// Like UpdateBalance it is guarded by the wallet version.
+func (r *WalletRepository) UpdateHeldBalance(db *gorm.DB, wallet *entity.Wallet, newHeldBalance decimal.Decimal) error {
+	if err := r.compareAndSwap(db, wallet, "held_balance", newHeldBalance); err != nil {
+		return err
+	}
+	wallet.HeldBalance = newHeldBalance
+	return nil
 }
 
+// compareAndSwap updates a single wallet column and bumps the version, only when the stored version matches.
+func (r *WalletRepository) compareAndSwap(db *gorm.DB, wallet *entity.Wallet, column string, value decimal.Decimal) error {
+	result := db.Model(&entity.Wallet{}).
+		Where("id = ? AND version = ?", wallet.ID, wallet.Version).
+		Updates(map[string]interface{}{
+			column:    value,
+			"version": gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+	wallet.Version++
+	return nil
+}
+
+// LockForUpdate loads a wallet with SELECT ... FOR UPDATE, holding the row lock until the surrounding database transaction ends.
 func (r *WalletRepository) LockForUpdate(db *gorm.DB, walletID uint) (*entity.Wallet, error) {
 	var wallet entity.Wallet
-	err := db.Set("gorm:query_option", "FOR UPDATE").Where("id = ?", walletID).First(&wallet).Error
+	err := db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", walletID).First(&wallet).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
 	return &wallet, err
 }
+
+// FindPageAfterID returns up to limit wallets with an ID above afterID, ordered by ID.
+func (r *WalletRepository) FindPageAfterID(db *gorm.DB, afterID uint, limit int) ([]entity.Wallet, error) {
+	var wallets []entity.Wallet
+	err := db.Where("id > ?", afterID).Order("id ASC").Limit(limit).Find(&wallets).Error
+	return wallets, err
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"backend/internal/entity"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WebhookDeliveryRepository struct {
+	Repository[entity.WebhookDelivery]
+	Log *logrus.Logger
+}
+
+func NewWebhookDeliveryRepository(log *logrus.Logger) *WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{
+		Log: log,
+	}
+}
+
+// CreateIgnoringDuplicates inserts deliveries, skipping those already queued for the same endpoint and event.
+func (r *WebhookDeliveryRepository) CreateIgnoringDuplicates(db *gorm.DB, deliveries []entity.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&deliveries).Error
+}
+
+// ClaimDue locks up to limit pending deliveries that are due and pushes their next attempt to leaseUntil,
+// so no other sender picks them up while they are in flight. Endpoints are preloaded.
+func (r *WebhookDeliveryRepository) ClaimDue(db *gorm.DB, now time.Time, leaseUntil time.Time, limit int) ([]entity.WebhookDelivery, error) {
+	var deliveries []entity.WebhookDelivery
+	err := db.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("status = ? AND next_attempt_at <= ?", entity.WebhookDeliveryStatusPending, now).
+		Order("id ASC").
+		Limit(limit).
+		Find(&deliveries).Error
+	if err != nil || len(deliveries) == 0 {
+		return deliveries, err
+	}
+
+	ids := make([]uint, len(deliveries))
+	for i, delivery := range deliveries {
+		ids[i] = delivery.ID
+	}
+	if err := db.Model(&entity.WebhookDelivery{}).Where("id IN ?", ids).Update("next_attempt_at", leaseUntil).Error; err != nil {
+		return nil, err
+	}
+
+	err = db.Preload("WebhookEndpoint").Where("id IN ?", ids).Order("id ASC").Find(&deliveries).Error
+	return deliveries, err
+}
+
+// MarkSucceeded records a successful delivery.
+func (r *WebhookDeliveryRepository) MarkSucceeded(db *gorm.DB, id uint, responseStatus int, deliveredAt time.Time) error {
+	return db.Model(&entity.WebhookDelivery{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":          entity.WebhookDeliveryStatusSucceeded,
+		"attempts":        gorm.Expr("attempts + 1"),
+		"response_status": responseStatus,
+		"last_error":      nil,
+		"delivered_at":    deliveredAt,
+	}).Error
+}
+
+// MarkAttemptFailed records a failed delivery and when to try again.
+// The delivery is given up on by passing the failed status.
+func (r *WebhookDeliveryRepository) MarkAttemptFailed(db *gorm.DB, id uint, status entity.WebhookDeliveryStatus, responseStatus *int, lastError string, nextAttemptAt time.Time) error {
+	return db.Model(&entity.WebhookDelivery{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":          status,
+		"attempts":        gorm.Expr("attempts + 1"),
+		"response_status": responseStatus,
+		"last_error":      lastError,
+		"next_attempt_at": nextAttemptAt,
+	}).Error
+}
+
+// FindByEndpointID returns the delivery log of an endpoint, newest first.
+func (r *WebhookDeliveryRepository) FindByEndpointID(db *gorm.DB, endpointID uint, page, limit int) ([]entity.WebhookDelivery, int64, error) {
+	var deliveries []entity.WebhookDelivery
+	var total int64
+
+	query := db.Model(&entity.WebhookDelivery{}).Where("webhook_endpoint_id = ?", endpointID)
+
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	err = query.Order("id DESC").Offset(offset).Limit(limit).Find(&deliveries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return deliveries, total, nil
+}
+
+// FindByIDAndEndpointID finds a delivery of an endpoint.
+func (r *WebhookDeliveryRepository) FindByIDAndEndpointID(db *gorm.DB, id uint, endpointID uint) (*entity.WebhookDelivery, error) {
+	var delivery entity.WebhookDelivery
+	err := db.Where("id = ? AND webhook_endpoint_id = ?", id, endpointID).First(&delivery).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &delivery, err
+}
+
+// ResetForRedelivery queues a delivery again with a fresh retry budget.
+func (r *WebhookDeliveryRepository) ResetForRedelivery(db *gorm.DB, id uint, now time.Time) error {
+	return db.Model(&entity.WebhookDelivery{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":          entity.WebhookDeliveryStatusPending,
+		"attempts":        0,
+		"next_attempt_at": now,
+	}).Error
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"backend/internal/entity"
+	"errors"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type WebhookEndpointRepository struct {
+	Repository[entity.WebhookEndpoint]
+	Log *logrus.Logger
+}
+
+func NewWebhookEndpointRepository(log *logrus.Logger) *WebhookEndpointRepository {
+	return &WebhookEndpointRepository{
+		Log: log,
+	}
+}
+
+// FindByUserID returns all webhook endpoints registered by a user.
+func (r *WebhookEndpointRepository) FindByUserID(db *gorm.DB, userID uint) ([]entity.WebhookEndpoint, error) {
+	var endpoints []entity.WebhookEndpoint
+	err := db.Where("user_id = ?", userID).Order("id ASC").Find(&endpoints).Error
+	return endpoints, err
+}
+
+// FindByIDAndUserID finds a webhook endpoint owned by a user.
+func (r *WebhookEndpointRepository) FindByIDAndUserID(db *gorm.DB, id uint, userID uint) (*entity.WebhookEndpoint, error) {
+	var endpoint entity.WebhookEndpoint
+	err := db.Where("id = ? AND user_id = ?", id, userID).First(&endpoint).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &endpoint, err
+}
+
+// FindSubscribed returns the active endpoints that should receive an event of the given type for a user:
+// the user's own endpoints and those registered by accounts allowed to receive every user's events.
+// Endpoints of disabled accounts receive nothing.
+func (r *WebhookEndpointRepository) FindSubscribed(db *gorm.DB, userID uint, eventType entity.OutboxEventType) ([]entity.WebhookEndpoint, error) {
+	var endpoints []entity.WebhookEndpoint
+	allEvents := db.Model(&entity.User{}).Select("id").Where("role IN ?", entity.RolesWithPermission(entity.PermissionWebhooksAllEvents))
+	activeUsers := db.Model(&entity.User{}).Select("id").Where("disabled_at IS NULL")
+	err := db.Where("is_active = ? AND FIND_IN_SET(?, event_types) > 0", true, eventType).
+		Where("user_id = ? OR user_id IN (?)", userID, allEvents).
+		Where("user_id IN (?)", activeUsers).
+		Order("id ASC").
+		Find(&endpoints).Error
+	return endpoints, err
+}
@@ -0,0 +1,233 @@
+package usecase
+
+import (
+	"backend/internal/entity"
+	"backend/internal/model"
+	"backend/internal/model/converter"
+	"backend/internal/repository"
+	"backend/internal/util"
+	"context"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// AdminUserUseCase lets admins look after accounts. The routes check the permission of each action,
+// acting on an account other than a regular user also needs entity.PermissionUsersManageAdmins.
+type AdminUserUseCase struct {
+	DB               *gorm.DB
+	Log              *logrus.Logger
+	Validate         *validator.Validate
+	UserRepository   *repository.UserRepository
+	WalletRepository *repository.WalletRepository
+	TokenUtil        *util.TokenUtil
+	Connections      ConnectionCloserInterface
+}
+
+func NewAdminUserUseCase(db *gorm.DB, log *logrus.Logger, validate *validator.Validate, userRepo *repository.UserRepository, walletRepo *repository.WalletRepository, tokenUtil *util.TokenUtil, connections ConnectionCloserInterface) *AdminUserUseCase {
+	return &AdminUserUseCase{
+		DB:               db,
+		Log:              log,
+		Validate:         validate,
+		UserRepository:   userRepo,
+		WalletRepository: walletRepo,
+		TokenUtil:        tokenUtil,
+		Connections:      connections,
+	}
+}
+
+// Search lists users matching the filters of the request, by ID.
+func (uc *AdminUserUseCase) Search(ctx context.Context, request *model.AdminUserSearchRequest) (*model.AdminUserListResponse, error) {
+	if err := uc.Validate.Struct(request); err != nil {
+		uc.Log.Warnf("Validation error: %v", err)
+		return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	users, total, err := uc.UserRepository.Search(uc.DB.WithContext(ctx), request.Query, request.Role, request.Disabled, request.Page, request.Limit)
+	if err != nil {
+		uc.Log.Errorf("Search error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return &model.AdminUserListResponse{
+		Users: converter.UsersToAdminUserResponses(users),
+		Total: total,
+		Page:  request.Page,
+		Limit: request.Limit,
+	}, nil
+}
+
+// Get returns a user with their wallet and number of active sessions.
+func (uc *AdminUserUseCase) Get(ctx context.Context, userID uint) (*model.AdminUserDetailResponse, error) {
+	db := uc.DB.WithContext(ctx)
+	user, err := uc.findUser(db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	wallet, err := uc.WalletRepository.FindByUserID(db, user.ID)
+	if err != nil {
+		uc.Log.Errorf("FindByUserID error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	sessions, err := uc.TokenUtil.ListSessions(ctx, user.ID)
+	if err != nil {
+		uc.Log.Errorf("ListSessions error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	response := &model.AdminUserDetailResponse{
+		AdminUserResponse: *converter.UserToAdminUserResponse(user),
+		ActiveSessions:    len(sessions),
+	}
+	if wallet != nil {
+		response.Wallet = converter.WalletToWalletResponse(wallet)
+	}
+	return response, nil
+}
+
+// ChangeRole gives a user another role. Admins cannot change their own role, so the last super admin
+// cannot lock everyone out. The sessions of the user are revoked, access tokens carry the old role.
+func (uc *AdminUserUseCase) ChangeRole(ctx context.Context, auth *model.Auth, userID uint, request *model.ChangeRoleRequest) (*model.AdminUserResponse, error) {
+	if err := uc.Validate.Struct(request); err != nil {
+		uc.Log.Warnf("Validation error: %v", err)
+		return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if userID == *auth.UserID {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "You cannot change your own role")
+	}
+
+	db := uc.DB.WithContext(ctx)
+	user, err := uc.findUser(db, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == request.Role {
+		return converter.UserToAdminUserResponse(user), nil
+	}
+
+	if err := uc.UserRepository.UpdateRole(db, user.ID, request.Role); err != nil {
+		uc.Log.Errorf("UpdateRole error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	uc.Log.Infof("Role of user ID %d changed from %s to %s by user ID: %d", user.ID, user.Role, request.Role, *auth.UserID)
+	user.Role = request.Role
+
+	if _, err := uc.revokeSessions(ctx, user.ID); err != nil {
+		return nil, err
+	}
+	return converter.UserToAdminUserResponse(user), nil
+}
+
+// Disable disables an account and ends its sessions, the auth middleware rejects it from then on.
+func (uc *AdminUserUseCase) Disable(ctx context.Context, auth *model.Auth, userID uint) (*model.AdminUserResponse, error) {
+	if userID == *auth.UserID {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "You cannot disable your own account")
+	}
+
+	db := uc.DB.WithContext(ctx)
+	user, err := uc.findManagedUser(db, auth, userID, "disable")
+	if err != nil {
+		return nil, err
+	}
+	if user.Disabled() {
+		return nil, fiber.NewError(fiber.StatusConflict, "User is already disabled")
+	}
+
+	disabledAt := time.Now()
+	if err := uc.UserRepository.UpdateDisabledAt(db, user.ID, &disabledAt); err != nil {
+		uc.Log.Errorf("UpdateDisabledAt error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	user.DisabledAt = &disabledAt
+	uc.Log.Infof("User ID %d disabled by user ID: %d", user.ID, *auth.UserID)
+
+	if _, err := uc.revokeSessions(ctx, user.ID); err != nil {
+		return nil, err
+	}
+	return converter.UserToAdminUserResponse(user), nil
+}
+
+// Enable lets a disabled account log in again.
+func (uc *AdminUserUseCase) Enable(ctx context.Context, auth *model.Auth, userID uint) (*model.AdminUserResponse, error) {
+	db := uc.DB.WithContext(ctx)
+	user, err := uc.findManagedUser(db, auth, userID, "enable")
+	if err != nil {
+		return nil, err
+	}
+	if !user.Disabled() {
+		return nil, fiber.NewError(fiber.StatusConflict, "User is not disabled")
+	}
+
+	if err := uc.UserRepository.UpdateDisabledAt(db, user.ID, nil); err != nil {
+		uc.Log.Errorf("UpdateDisabledAt error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	user.DisabledAt = nil
+	uc.Log.Infof("User ID %d enabled by user ID: %d", user.ID, *auth.UserID)
+
+	return converter.UserToAdminUserResponse(user), nil
+}
+
+// ForceLogout ends every session of a user, the user has to log in again.
+func (uc *AdminUserUseCase) ForceLogout(ctx context.Context, auth *model.Auth, userID uint) (*model.RevokeSessionsResponse, error) {
+	user, err := uc.findManagedUser(uc.DB.WithContext(ctx), auth, userID, "log out")
+	if err != nil {
+		return nil, err
+	}
+
+	revoked, err := uc.revokeSessions(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	uc.Log.Infof("%d sessions of user ID %d revoked by user ID: %d", revoked, user.ID, *auth.UserID)
+	return &model.RevokeSessionsResponse{Revoked: revoked}, nil
+}
+
+// findUser loads a user an admin asked for, system accounts are refused.
+func (uc *AdminUserUseCase) findUser(db *gorm.DB, userID uint) (*entity.User, error) {
+	user, err := uc.UserRepository.FindByID(db, userID)
+	if err != nil {
+		uc.Log.Errorf("FindByID error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	if user == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "User not found")
+	}
+	if user.System {
+		return nil, fiber.NewError(fiber.StatusForbidden, "System accounts cannot be managed")
+	}
+	return user, nil
+}
+
+// findManagedUser loads a user the admin wants to act on, only roles with entity.PermissionUsersManageAdmins
+// may act on accounts other than regular users.
+func (uc *AdminUserUseCase) findManagedUser(db *gorm.DB, auth *model.Auth, userID uint, action string) (*entity.User, error) {
+	user, err := uc.findUser(db, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role != entity.RoleUser && !entity.RoleHasPermission(auth.Role, entity.PermissionUsersManageAdmins) {
+		uc.Log.Warnf("Admin ID %d attempted to %s %s ID %d", *auth.UserID, action, user.Role, user.ID)
+		return nil, fiber.NewError(fiber.StatusForbidden, "Only super admins can "+action+" an admin")
+	}
+	return user, nil
+}
+
+// revokeSessions ends every session of a user and closes their open real-time connections,
+// it returns how many sessions there were.
+func (uc *AdminUserUseCase) revokeSessions(ctx context.Context, userID uint) (int, error) {
+	revoked, err := uc.TokenUtil.RevokeOtherSessions(ctx, userID, "")
+	if err != nil {
+		uc.Log.Errorf("RevokeOtherSessions error: %v", err)
+		return 0, fiber.ErrInternalServerError
+	}
+	if err := closeConnections(uc.Log, uc.Connections, &model.DisconnectRequest{UserID: userID}); err != nil {
+		return 0, err
+	}
+	return revoked, nil
+}
@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"backend/internal/entity"
+	"backend/internal/model"
+	"backend/internal/repository"
+	"context"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// maxAuditUserAgentLength is the size of the user_agent column, longer User-Agents are cut off.
+const maxAuditUserAgentLength = 255
+
+// AuditLogUseCase records accesses of staff to data of other users.
+type AuditLogUseCase struct {
+	DB                 *gorm.DB
+	Log                *logrus.Logger
+	Validate           *validator.Validate
+	AuditLogRepository *repository.AuditLogRepository
+}
+
+func NewAuditLogUseCase(db *gorm.DB, log *logrus.Logger, validate *validator.Validate, auditLogRepo *repository.AuditLogRepository) *AuditLogUseCase {
+	return &AuditLogUseCase{
+		DB:                 db,
+		Log:                log,
+		Validate:           validate,
+		AuditLogRepository: auditLogRepo,
+	}
+}
+
+// Record stores an audit log entry and writes it to the application log as well.
+func (uc *AuditLogUseCase) Record(ctx context.Context, request *model.AuditLogRequest) error {
+	if err := uc.Validate.Struct(request); err != nil {
+		uc.Log.Warnf("Validation error: %v", err)
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	userAgent := request.UserAgent
+	if len(userAgent) > maxAuditUserAgentLength {
+		userAgent = userAgent[:maxAuditUserAgentLength]
+	}
+
+	auditLog := &entity.AuditLog{
+		ActorUserID: request.ActorUserID,
+		ActorRole:   request.ActorRole,
+		Action:      request.Action,
+		TargetType:  request.TargetType,
+		TargetID:    request.TargetID,
+		IPAddress:   request.IPAddress,
+		UserAgent:   userAgent,
+	}
+	if err := uc.AuditLogRepository.Create(uc.DB.WithContext(ctx), auditLog); err != nil {
+		uc.Log.Errorf("AuditLog creation error: %v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	uc.Log.Infof("Audit log %d: user ID %d (%s) %s %s ID %d from IP: %s", auditLog.ID, request.ActorUserID, request.ActorRole, request.Action, request.TargetType, request.TargetID, request.IPAddress)
+	return nil
+}
@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Machine-readable error codes for failures a client has to tell apart, such as the kinds of lockout.
+const (
+	ErrorCodeLoginThrottled       = "login_throttled"
+	ErrorCodeAccountLocked        = "account_locked"
+	ErrorCodeIPLocked             = "ip_locked"
+	ErrorCodeAccountDisabled      = "account_disabled"
+	ErrorCodeTransactionPINLocked = "transaction_pin_locked"
+	ErrorCodePasswordLocked       = "password_locked"
+	ErrorCodeTwoFactorLocked      = "two_factor_locked"
+)
+
+// CodedError is a fiber.Error with a machine-readable code and, for throttling, how long to wait
+// before trying again. The error handler adds both to the response.
+type CodedError struct {
+	Err        *fiber.Error
+	ErrorCode  string
+	RetryAfter time.Duration
+}
+
+// NewCodedError creates a CodedError with an HTTP status, error code and message.
+func NewCodedError(status int, errorCode string, message string, retryAfter time.Duration) *CodedError {
+	return &CodedError{
+		Err:        fiber.NewError(status, message),
+		ErrorCode:  errorCode,
+		RetryAfter: retryAfter,
+	}
+}
+
+func (e *CodedError) Error() string {
+	return e.Err.Message
+}
+
+// Unwrap exposes the fiber.Error, so handlers that only know fiber errors still use its status.
+func (e *CodedError) Unwrap() error {
+	return e.Err
+}
+
+// errAccountDisabled is returned to disabled accounts by login, refresh and the auth middleware.
+func errAccountDisabled() *CodedError {
+	return NewCodedError(fiber.StatusForbidden, ErrorCodeAccountDisabled, "Account is disabled, contact support", 0)
+}
+
+// AsCodedError finds a CodedError in err's chain.
+func AsCodedError(err error) (*CodedError, bool) {
+	var codedErr *CodedError
+	ok := errors.As(err, &codedErr)
+	return codedErr, ok
+}
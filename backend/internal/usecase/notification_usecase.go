@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"backend/internal/entity"
+	"backend/internal/model"
+	"backend/internal/model/converter"
+	"backend/internal/repository"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type NotificationUseCase struct {
+	DB                     *gorm.DB
+	Log                    *logrus.Logger
+	Validate               *validator.Validate
+	NotificationRepository *repository.NotificationRepository
+}
+
+func NewNotificationUseCase(
+	db *gorm.DB,
+	log *logrus.Logger,
+	validate *validator.Validate,
+	notificationRepo *repository.NotificationRepository,
+) *NotificationUseCase {
+	return &NotificationUseCase{
+		DB:                     db,
+		Log:                    log,
+		Validate:               validate,
+		NotificationRepository: notificationRepo,
+	}
+}
+
+// Store saves a notification to the inbox of a user.
+// Storing the same type and reference again returns the notification already saved,
+// so a retried delivery does not show up twice.
+func (uc *NotificationUseCase) Store(ctx context.Context, userID uint, notificationType string, referenceID uint, payload interface{}) (*model.NotificationResponse, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	notification := &entity.Notification{
+		UserID:      userID,
+		Type:        notificationType,
+		ReferenceID: referenceID,
+		Payload:     data,
+	}
+	if err := uc.NotificationRepository.CreateOrFind(uc.DB.WithContext(ctx), notification); err != nil {
+		return nil, err
+	}
+
+	return converter.NotificationToNotificationResponse(notification), nil
+}
+
+// List returns the inbox of the authenticated user, newest first.
+func (uc *NotificationUseCase) List(ctx context.Context, auth *model.Auth, unreadOnly bool, page, limit int) (*model.NotificationListResponse, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	notifications, total, err := uc.NotificationRepository.FindByUserID(uc.DB.WithContext(ctx), *auth.UserID, unreadOnly, page, limit)
+	if err != nil {
+		uc.Log.Errorf("FindByUserID error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return &model.NotificationListResponse{
+		Notifications: converter.NotificationsToNotificationResponses(notifications),
+		Total:         total,
+		Page:          page,
+		Limit:         limit,
+	}, nil
+}
+
+// CountUnread returns how many notifications of the authenticated user are unread.
+func (uc *NotificationUseCase) CountUnread(ctx context.Context, auth *model.Auth) (*model.UnreadNotificationCountResponse, error) {
+	count, err := uc.NotificationRepository.CountUnread(uc.DB.WithContext(ctx), *auth.UserID)
+	if err != nil {
+		uc.Log.Errorf("CountUnread error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return &model.UnreadNotificationCountResponse{UnreadCount: count}, nil
+}
+
+// Mark marks a notification of the authenticated user read or unread.
+func (uc *NotificationUseCase) Mark(ctx context.Context, auth *model.Auth, notificationID uint, read bool) (*model.NotificationResponse, error) {
+	db := uc.DB.WithContext(ctx)
+	notification, err := uc.NotificationRepository.FindByIDAndUserID(db, notificationID, *auth.UserID)
+	if err != nil {
+		uc.Log.Errorf("FindByIDAndUserID error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	if notification == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Notification not found")
+	}
+
+	// Keep the first read time when a read notification is marked read again
+	if read == (notification.ReadAt != nil) {
+		return converter.NotificationToNotificationResponse(notification), nil
+	}
+
+	readAt := readTime(read)
+	if _, err := uc.NotificationRepository.SetReadAt(db, *auth.UserID, []uint{notification.ID}, readAt); err != nil {
+		uc.Log.Errorf("SetReadAt error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	notification.ReadAt = readAt
+	return converter.NotificationToNotificationResponse(notification), nil
+}
+
+// MarkBulk marks the given notifications, or all of them, of the authenticated user read or unread.
+// IDs of notifications that belong to someone else are ignored.
+func (uc *NotificationUseCase) MarkBulk(ctx context.Context, auth *model.Auth, request *model.MarkNotificationsRequest, read bool) (*model.MarkNotificationsResponse, error) {
+	// Validate request
+	if err := uc.Validate.Struct(request); err != nil {
+		uc.Log.Warnf("Validation error: %v", err)
+		return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	// The validator accepts an empty list, which the repository would read as every notification
+	if !request.All && len(request.IDs) == 0 {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "ids must not be empty unless all is set")
+	}
+
+	ids := request.IDs
+	if request.All {
+		ids = nil
+	}
+
+	db := uc.DB.WithContext(ctx)
+	updated, err := uc.NotificationRepository.SetReadAt(db, *auth.UserID, ids, readTime(read))
+	if err != nil {
+		uc.Log.Errorf("SetReadAt error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	count, err := uc.NotificationRepository.CountUnread(db, *auth.UserID)
+	if err != nil {
+		uc.Log.Errorf("CountUnread error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return &model.MarkNotificationsResponse{
+		Updated:     updated,
+		UnreadCount: count,
+	}, nil
+}
+
+// readTime returns the read_at value for marking a notification read, or nil for unread.
+func readTime(read bool) *time.Time {
+	if !read {
+		return nil
+	}
+	now := time.Now()
+	return &now
+}
@@ -0,0 +1,326 @@
+package usecase
+
+import (
+	"backend/internal/entity"
+	"backend/internal/model"
+	"backend/internal/repository"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// reconciliationPageSize is the number of rows the reconciliation reads per query.
+const reconciliationPageSize = 500
+
+type ReconciliationUseCase struct {
+	DB                       *gorm.DB
+	Log                      *logrus.Logger
+	Validate                 *validator.Validate
+	WalletRepository         *repository.WalletRepository
+	WalletMutationRepository *repository.WalletMutationRepository
+	TransactionRepository    *repository.TransactionRepository
+}
+
+func NewReconciliationUseCase(
+	db *gorm.DB,
+	log *logrus.Logger,
+	validate *validator.Validate,
+	walletRepo *repository.WalletRepository,
+	walletMutationRepo *repository.WalletMutationRepository,
+	transactionRepo *repository.TransactionRepository,
+) *ReconciliationUseCase {
+	return &ReconciliationUseCase{
+		DB:                       db,
+		Log:                      log,
+		Validate:                 validate,
+		WalletRepository:         walletRepo,
+		WalletMutationRepository: walletMutationRepo,
+		TransactionRepository:    transactionRepo,
+	}
+}
+
+// GetReport runs the reconciliation on behalf of an admin, the route requires entity.PermissionReconciliationRead.
+func (uc *ReconciliationUseCase) GetReport(ctx context.Context, auth *model.Auth) (*model.ReconciliationReport, error) {
+	report, err := uc.Run(ctx)
+	if err != nil {
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return report, nil
+}
+
+// Run checks the ledger and reports every discrepancy found.
+// For each wallet the balance must equal the latest balance_after and the mutation chain must be unbroken.
+// For each completed transaction the sender must be debited and the recipient credited its amount.
+// The ledger is read from one consistent snapshot in pages of reconciliationPageSize rows,
+// so money moved while the report runs does not show up as a discrepancy.
+func (uc *ReconciliationUseCase) Run(ctx context.Context) (*model.ReconciliationReport, error) {
+	report := &model.ReconciliationReport{
+		GeneratedAt:  time.Now(),
+		TotalBalance: decimal.Zero,
+		Wallets:      []model.WalletReconciliation{},
+		Transactions: []model.TransactionReconciliation{},
+	}
+
+	err := uc.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := uc.reconcileWallets(tx, report); err != nil {
+			return err
+		}
+		return uc.reconcileTransactions(tx, report)
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+
+	report.Consistent = len(report.Wallets) == 0 && len(report.Transactions) == 0 && report.TotalBalance.IsZero()
+
+	if report.Consistent {
+		uc.Log.Infof("Reconciliation finished: %d wallets and %d transactions consistent", report.WalletsChecked, report.TransactionsChecked)
+	} else {
+		uc.Log.Warnf("Reconciliation finished: %d wallets and %d transactions with discrepancies, total balance %s",
+			len(report.Wallets), len(report.Transactions), report.TotalBalance.String())
+	}
+
+	return report, nil
+}
+
+// reconcileWallets checks the wallets page by page, walking the mutation chains of each page.
+func (uc *ReconciliationUseCase) reconcileWallets(tx *gorm.DB, report *model.ReconciliationReport) error {
+	var afterID uint
+	for {
+		wallets, err := uc.WalletRepository.FindPageAfterID(tx, afterID, reconciliationPageSize)
+		if err != nil {
+			uc.Log.Errorf("FindPageAfterID wallets error: %v", err)
+			return err
+		}
+		if len(wallets) == 0 {
+			return nil
+		}
+
+		walletIDs := make([]uint, len(wallets))
+		chains := make(map[uint]*walletChain, len(wallets))
+		for i := range wallets {
+			walletIDs[i] = wallets[i].ID
+			chains[wallets[i].ID] = newWalletChain(&wallets[i])
+		}
+
+		var afterWalletID, afterMutationID uint
+		for {
+			mutations, err := uc.WalletMutationRepository.FindChainsAfter(tx, walletIDs, afterWalletID, afterMutationID, reconciliationPageSize)
+			if err != nil {
+				uc.Log.Errorf("FindChainsAfter error: %v", err)
+				return err
+			}
+			for i := range mutations {
+				chains[mutations[i].WalletID].add(&mutations[i])
+			}
+			if len(mutations) < reconciliationPageSize {
+				break
+			}
+			last := mutations[len(mutations)-1]
+			afterWalletID, afterMutationID = last.WalletID, last.ID
+		}
+
+		for i := range wallets {
+			report.WalletsChecked++
+			report.TotalBalance = report.TotalBalance.Add(wallets[i].Balance)
+
+			if result := chains[wallets[i].ID].finish(); len(result.Discrepancies) > 0 {
+				report.Wallets = append(report.Wallets, *result)
+			}
+		}
+
+		if len(wallets) < reconciliationPageSize {
+			return nil
+		}
+		afterID = wallets[len(wallets)-1].ID
+	}
+}
+
+// reconcileTransactions checks the transactions page by page against their summed mutations.
+func (uc *ReconciliationUseCase) reconcileTransactions(tx *gorm.DB, report *model.ReconciliationReport) error {
+	var afterID uint
+	for {
+		transactions, err := uc.TransactionRepository.FindPageAfterID(tx, afterID, reconciliationPageSize)
+		if err != nil {
+			uc.Log.Errorf("FindPageAfterID transactions error: %v", err)
+			return err
+		}
+		if len(transactions) == 0 {
+			return nil
+		}
+
+		transactionIDs := make([]uint, len(transactions))
+		for i := range transactions {
+			transactionIDs[i] = transactions[i].ID
+		}
+
+		totals, err := uc.WalletMutationRepository.SumByTransactionIDs(tx, transactionIDs)
+		if err != nil {
+			uc.Log.Errorf("SumByTransactionIDs error: %v", err)
+			return err
+		}
+
+		totalsByTransaction := make(map[uint]repository.TransactionMutationTotal, len(totals))
+		for _, total := range totals {
+			totalsByTransaction[total.TransactionID] = total
+		}
+
+		for i := range transactions {
+			report.TransactionsChecked++
+
+			total, ok := totalsByTransaction[transactions[i].ID]
+			if !ok {
+				total = repository.TransactionMutationTotal{TransactionID: transactions[i].ID, Debit: decimal.Zero, Credit: decimal.Zero}
+			}
+
+			if result := reconcileTransaction(&transactions[i], total); len(result.Discrepancies) > 0 {
+				report.Transactions = append(report.Transactions, *result)
+			}
+		}
+
+		if len(transactions) < reconciliationPageSize {
+			return nil
+		}
+		afterID = transactions[len(transactions)-1].ID
+	}
+}
+
+// walletChain checks the mutation chain of a wallet against its balance, one mutation at a time in booking order.
+type walletChain struct {
+	wallet *entity.Wallet
+	result *model.WalletReconciliation
+	// previousBalanceAfter starts at zero, a wallet starts empty so the first balance_before must be zero as well
+	previousBalanceAfter decimal.Decimal
+	mutations            int
+}
+
+func newWalletChain(wallet *entity.Wallet) *walletChain {
+	return &walletChain{
+		wallet: wallet,
+		result: &model.WalletReconciliation{
+			WalletID:      wallet.ID,
+			UserID:        wallet.UserID,
+			Balance:       wallet.Balance,
+			Discrepancies: []model.ReconciliationDiscrepancy{},
+		},
+		previousBalanceAfter: decimal.Zero,
+	}
+}
+
+// add checks the next mutation of the chain.
+func (c *walletChain) add(mutation *entity.WalletMutation) {
+	mutationID := mutation.ID
+	transactionID := mutation.TransactionID
+
+	if !mutation.BalanceBefore.Equal(c.previousBalanceAfter) {
+		c.result.Discrepancies = append(c.result.Discrepancies, model.ReconciliationDiscrepancy{
+			Code:          model.DiscrepancyBrokenChain,
+			MutationID:    &mutationID,
+			TransactionID: &transactionID,
+			Expected:      c.previousBalanceAfter,
+			Actual:        mutation.BalanceBefore,
+			Message:       "balance_before does not match the previous balance_after",
+		})
+	}
+
+	expectedBalanceAfter := mutation.BalanceBefore.Add(mutation.Amount)
+	if mutation.Type == entity.MutationTypeDebit {
+		expectedBalanceAfter = mutation.BalanceBefore.Sub(mutation.Amount)
+	}
+	if !mutation.BalanceAfter.Equal(expectedBalanceAfter) {
+		c.result.Discrepancies = append(c.result.Discrepancies, model.ReconciliationDiscrepancy{
+			Code:          model.DiscrepancyMutationArithmetic,
+			MutationID:    &mutationID,
+			TransactionID: &transactionID,
+			Expected:      expectedBalanceAfter,
+			Actual:        mutation.BalanceAfter,
+			Message:       fmt.Sprintf("balance_after does not match balance_before %s amount", mutation.Type),
+		})
+	}
+
+	c.previousBalanceAfter = mutation.BalanceAfter
+	c.mutations++
+}
+
+// finish compares the end of the chain with the wallet balance and returns the result.
+func (c *walletChain) finish() *model.WalletReconciliation {
+	if c.mutations > 0 {
+		latestBalanceAfter := c.previousBalanceAfter
+		c.result.LatestBalanceAfter = &latestBalanceAfter
+	}
+
+	if !c.wallet.Balance.Equal(c.previousBalanceAfter) {
+		c.result.Discrepancies = append(c.result.Discrepancies, model.ReconciliationDiscrepancy{
+			Code:     model.DiscrepancyBalanceMismatch,
+			Expected: c.previousBalanceAfter,
+			Actual:   c.wallet.Balance,
+			Message:  "wallet balance does not match the latest balance_after",
+		})
+	}
+
+	return c.result
+}
+
+// reconcileTransaction checks that the mutations of a transaction balance out.
+func reconcileTransaction(transaction *entity.Transaction, total repository.TransactionMutationTotal) *model.TransactionReconciliation {
+	result := &model.TransactionReconciliation{
+		TransactionID: transaction.ID,
+		Type:          string(transaction.Type),
+		Status:        string(transaction.Status),
+		Amount:        transaction.Amount,
+		Discrepancies: []model.ReconciliationDiscrepancy{},
+	}
+
+	// Pending and failed transactions must not have moved any money
+	if transaction.Status != entity.TransactionStatusCompleted {
+		moved := total.Debit.Add(total.Credit)
+		if !moved.IsZero() {
+			result.Discrepancies = append(result.Discrepancies, model.ReconciliationDiscrepancy{
+				Code:     model.DiscrepancyUnexpectedMutations,
+				Expected: decimal.Zero,
+				Actual:   moved,
+				Message:  fmt.Sprintf("%s transaction has mutations", transaction.Status),
+			})
+		}
+		return result
+	}
+
+	// Each wallet of the transaction has one leg. Top-ups and withdraws booked before the treasury existed
+	// have no treasury side and the treasury opening balance has no recipient, so they are single-sided.
+	// Legacy issues name a sender that was never debited.
+	expectedDebit, expectedCredit := decimal.Zero, decimal.Zero
+	if transaction.FromWalletID != nil && !transaction.LegacyIssue {
+		expectedDebit = transaction.Amount
+	}
+	if transaction.ToWalletID != nil {
+		expectedCredit = transaction.Amount
+	}
+
+	if !total.Debit.Equal(expectedDebit) {
+		result.Discrepancies = append(result.Discrepancies, model.ReconciliationDiscrepancy{
+			Code:     model.DiscrepancyDebitMismatch,
+			Expected: expectedDebit,
+			Actual:   total.Debit,
+			Message:  "sum of debit mutations does not match transaction amount",
+		})
+	}
+
+	if !total.Credit.Equal(expectedCredit) {
+		result.Discrepancies = append(result.Discrepancies, model.ReconciliationDiscrepancy{
+			Code:     model.DiscrepancyCreditMismatch,
+			Expected: expectedCredit,
+			Actual:   total.Credit,
+			Message:  "sum of credit mutations does not match transaction amount",
+		})
+	}
+
+	return result
+}
@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"backend/internal/model"
+	"backend/internal/util"
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// SessionUseCase manages the sessions of a user. A session is a refresh token family,
+// started at login and kept in the Redis session registry.
+// Revoking a session also closes the real-time connections opened with it.
+type SessionUseCase struct {
+	Log         *logrus.Logger
+	TokenUtil   *util.TokenUtil
+	Connections ConnectionCloserInterface
+}
+
+func NewSessionUseCase(log *logrus.Logger, tokenUtil *util.TokenUtil, connections ConnectionCloserInterface) *SessionUseCase {
+	return &SessionUseCase{
+		Log:         log,
+		TokenUtil:   tokenUtil,
+		Connections: connections,
+	}
+}
+
+// List returns the active sessions of the authenticated user, most recently used first.
+func (uc *SessionUseCase) List(ctx context.Context, auth *model.Auth) ([]model.SessionResponse, error) {
+	sessions, err := uc.TokenUtil.ListSessions(ctx, *auth.UserID)
+	if err != nil {
+		uc.Log.Errorf("ListSessions error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == auth.FamilyID
+	}
+	return sessions, nil
+}
+
+// Revoke ends a session of the authenticated user, its access and refresh tokens stop working immediately.
+func (uc *SessionUseCase) Revoke(ctx context.Context, auth *model.Auth, sessionID string) error {
+	revoked, err := uc.TokenUtil.RevokeSession(ctx, *auth.UserID, sessionID)
+	if err != nil {
+		uc.Log.Errorf("RevokeSession error: %v", err)
+		return fiber.ErrInternalServerError
+	}
+	if !revoked {
+		return fiber.NewError(fiber.StatusNotFound, "Session not found")
+	}
+	if err := closeConnections(uc.Log, uc.Connections, &model.DisconnectRequest{UserID: *auth.UserID, SessionID: sessionID}); err != nil {
+		return err
+	}
+
+	uc.Log.Infof("Session %s revoked by user ID: %d", sessionID, *auth.UserID)
+	return nil
+}
+
+// RevokeOthers ends every session of the authenticated user except the current one.
+func (uc *SessionUseCase) RevokeOthers(ctx context.Context, auth *model.Auth) (*model.RevokeSessionsResponse, error) {
+	revoked, err := uc.TokenUtil.RevokeOtherSessions(ctx, *auth.UserID, auth.FamilyID)
+	if err != nil {
+		uc.Log.Errorf("RevokeOtherSessions error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	if err := closeConnections(uc.Log, uc.Connections, &model.DisconnectRequest{UserID: *auth.UserID, KeepSessionID: auth.FamilyID}); err != nil {
+		return nil, err
+	}
+
+	uc.Log.Infof("%d other sessions revoked by user ID: %d", revoked, *auth.UserID)
+	return &model.RevokeSessionsResponse{Revoked: revoked}, nil
+}
+
+// closeConnections closes the real-time connections selected by request after their sessions were revoked,
+// they would otherwise keep receiving events until they disconnect.
+func closeConnections(log *logrus.Logger, connections ConnectionCloserInterface, request *model.DisconnectRequest) error {
+	if err := connections.Disconnect(request); err != nil {
+		log.Errorf("Disconnect error: %v", err)
+		return fiber.ErrInternalServerError
+	}
+	return nil
+}
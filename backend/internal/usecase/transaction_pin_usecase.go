@@ -0,0 +1,212 @@
+package usecase
+
+import (
+	"backend/internal/entity"
+	"backend/internal/model"
+	"backend/internal/repository"
+	"backend/internal/util"
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// TransactionPINUseCase manages the transaction PIN that authorizes outgoing money.
+// Wrong PINs are counted per user by AttemptLimiter, which locks the PIN after too many.
+type TransactionPINUseCase struct {
+	DB             *gorm.DB
+	Log            *logrus.Logger
+	Validate       *validator.Validate
+	UserRepository *repository.UserRepository
+	AttemptLimiter *util.AttemptLimiter
+}
+
+func NewTransactionPINUseCase(db *gorm.DB, log *logrus.Logger, validate *validator.Validate, userRepo *repository.UserRepository, attemptLimiter *util.AttemptLimiter) *TransactionPINUseCase {
+	return &TransactionPINUseCase{
+		DB:             db,
+		Log:            log,
+		Validate:       validate,
+		UserRepository: userRepo,
+		AttemptLimiter: attemptLimiter,
+	}
+}
+
+// Status returns whether the authenticated user set a transaction PIN and whether it is locked.
+func (uc *TransactionPINUseCase) Status(ctx context.Context, auth *model.Auth) (*model.TransactionPINStatusResponse, error) {
+	user, err := uc.findUser(uc.DB.WithContext(ctx), *auth.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &model.TransactionPINStatusResponse{IsSet: user.TransactionPINSet()}
+	lockedFor, err := uc.AttemptLimiter.LockedFor(ctx, userLimiterKey(user.ID))
+	if err != nil {
+		uc.Log.Errorf("LockedFor error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	if lockedFor > 0 {
+		lockedUntil := time.Now().Add(lockedFor)
+		response.LockedUntil = &lockedUntil
+	}
+	return response, nil
+}
+
+// Set sets the first transaction PIN of the authenticated user, confirmed with the account password.
+func (uc *TransactionPINUseCase) Set(ctx context.Context, auth *model.Auth, request *model.TransactionPINRequest) error {
+	// Validate request
+	if err := uc.Validate.Struct(request); err != nil {
+		uc.Log.Warnf("Validation error: %v", err)
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	db := uc.DB.WithContext(ctx)
+	user, err := uc.findUser(db, *auth.UserID)
+	if err != nil {
+		return err
+	}
+	if user.TransactionPINSet() {
+		return fiber.NewError(fiber.StatusConflict, "Transaction PIN is already set")
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(request.Password)); err != nil {
+		uc.Log.Warnf("Password mismatch setting transaction PIN for user ID: %d", user.ID)
+		return fiber.NewError(fiber.StatusBadRequest, "Password is incorrect")
+	}
+
+	if err := uc.updatePIN(db, user.ID, request.PIN); err != nil {
+		return err
+	}
+
+	uc.Log.Infof("Transaction PIN set by user ID: %d", user.ID)
+	return nil
+}
+
+// Change replaces the transaction PIN of the authenticated user. A wrong current PIN counts as a failed attempt.
+func (uc *TransactionPINUseCase) Change(ctx context.Context, auth *model.Auth, request *model.ChangeTransactionPINRequest) error {
+	// Validate request
+	if err := uc.Validate.Struct(request); err != nil {
+		uc.Log.Warnf("Validation error: %v", err)
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	if err := uc.Verify(ctx, *auth.UserID, request.CurrentPIN); err != nil {
+		return err
+	}
+
+	if err := uc.updatePIN(uc.DB.WithContext(ctx), *auth.UserID, request.NewPIN); err != nil {
+		return err
+	}
+
+	uc.Log.Infof("Transaction PIN changed by user ID: %d", *auth.UserID)
+	return nil
+}
+
+// Reset replaces a forgotten transaction PIN, confirmed with the account password, and lifts a lockout.
+func (uc *TransactionPINUseCase) Reset(ctx context.Context, auth *model.Auth, request *model.TransactionPINRequest) error {
+	// Validate request
+	if err := uc.Validate.Struct(request); err != nil {
+		uc.Log.Warnf("Validation error: %v", err)
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	db := uc.DB.WithContext(ctx)
+	user, err := uc.findUser(db, *auth.UserID)
+	if err != nil {
+		return err
+	}
+	if !user.TransactionPINSet() {
+		return fiber.NewError(fiber.StatusBadRequest, "Transaction PIN is not set")
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(request.Password)); err != nil {
+		uc.Log.Warnf("Password mismatch resetting transaction PIN for user ID: %d", user.ID)
+		return fiber.NewError(fiber.StatusBadRequest, "Password is incorrect")
+	}
+
+	if err := uc.updatePIN(db, user.ID, request.PIN); err != nil {
+		return err
+	}
+	if err := uc.AttemptLimiter.Reset(ctx, userLimiterKey(user.ID)); err != nil {
+		uc.Log.Errorf("AttemptLimiter.Reset error: %v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	uc.Log.Infof("Transaction PIN reset by user ID: %d", user.ID)
+	return nil
+}
+
+// Verify checks the transaction PIN of a user before money leaves the wallet.
+// The attempt is counted before the PIN is compared, so concurrent requests cannot try more than
+// AttemptLimiter.MaxAttempts PINs. After that many wrong PINs the PIN is locked, a correct PIN clears the count.
+func (uc *TransactionPINUseCase) Verify(ctx context.Context, userID uint, pin string) error {
+	user, err := uc.findUser(uc.DB.WithContext(ctx), userID)
+	if err != nil {
+		return err
+	}
+	if !user.TransactionPINSet() {
+		return fiber.NewError(fiber.StatusForbidden, "Transaction PIN is not set")
+	}
+
+	key := userLimiterKey(user.ID)
+	attempt, err := uc.AttemptLimiter.Begin(ctx, key)
+	if err != nil {
+		uc.Log.Errorf("AttemptLimiter.Begin error: %v", err)
+		return fiber.ErrInternalServerError
+	}
+	if !attempt.Allowed() {
+		uc.Log.Warnf("Locked transaction PIN used by user ID: %d", user.ID)
+		return NewCodedError(fiber.StatusTooManyRequests, ErrorCodeTransactionPINLocked, fmt.Sprintf("Transaction PIN is locked, try again in %s or reset it", attempt.Wait.Round(time.Second)), attempt.Wait)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.TransactionPIN), []byte(pin)); err != nil {
+		uc.Log.Warnf("Wrong transaction PIN for user ID %d, %d attempts left", user.ID, attempt.Left)
+		if attempt.Left == 0 {
+			return NewCodedError(fiber.StatusTooManyRequests, ErrorCodeTransactionPINLocked, fmt.Sprintf("Transaction PIN is locked for %s after too many wrong attempts", uc.AttemptLimiter.Lockout), uc.AttemptLimiter.Lockout)
+		}
+		return fiber.NewError(fiber.StatusForbidden, fmt.Sprintf("Invalid transaction PIN, %d attempts left", attempt.Left))
+	}
+
+	if err := uc.AttemptLimiter.Reset(ctx, key); err != nil {
+		uc.Log.Errorf("AttemptLimiter.Reset error: %v", err)
+		return fiber.ErrInternalServerError
+	}
+	return nil
+}
+
+// findUser loads a user that is expected to exist.
+func (uc *TransactionPINUseCase) findUser(db *gorm.DB, userID uint) (*entity.User, error) {
+	user, err := uc.UserRepository.FindByID(db, userID)
+	if err != nil {
+		uc.Log.Errorf("FindByID error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	if user == nil {
+		uc.Log.Warnf("User not found: %d", userID)
+		return nil, fiber.NewError(fiber.StatusNotFound, "User not found")
+	}
+	return user, nil
+}
+
+// updatePIN hashes and stores a new transaction PIN for a user.
+func (uc *TransactionPINUseCase) updatePIN(db *gorm.DB, userID uint, pin string) error {
+	pinHash, err := bcrypt.GenerateFromPassword([]byte(pin), bcrypt.DefaultCost)
+	if err != nil {
+		uc.Log.Errorf("PIN hashing error: %v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	if err := uc.UserRepository.UpdateTransactionPIN(db, userID, string(pinHash)); err != nil {
+		uc.Log.Errorf("UpdateTransactionPIN error: %v", err)
+		return fiber.ErrInternalServerError
+	}
+	return nil
+}
+
+// userLimiterKey returns the AttemptLimiter key of a user.
+func userLimiterKey(userID uint) string {
+	return strconv.FormatUint(uint64(userID), 10)
+}
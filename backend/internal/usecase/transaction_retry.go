@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"backend/internal/model"
+	"backend/internal/repository"
+	"context"
+	"errors"
+	"expvar"
+	"math/rand/v2"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// transactionMaxAttempts bounds how often a money movement is run before giving up.
+	transactionMaxAttempts = 5
+	// transactionRetryBaseDelay is the backoff before the second attempt, doubled for every further attempt.
+	transactionRetryBaseDelay = 10 * time.Millisecond
+)
+
+// Retry metrics per operation, published on /debug/vars.
+var (
+	transactionRetries          = expvar.NewMap("transaction_retries")
+	transactionRetriesExhausted = expvar.NewMap("transaction_retries_exhausted")
+)
+
+// retryableError marks a failure after which the database transaction can be run again.
+type retryableError struct {
+	err error
+}
+
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+// databaseError maps a failed database call to the error returned by a transaction attempt.
+// Deadlocks, lock wait timeouts and version conflicts are kept so withRetry can try again,
+// anything else becomes an internal server error.
+func databaseError(err error) error {
+	if repository.IsRetryableError(err) {
+		return &retryableError{err: err}
+	}
+	return fiber.ErrInternalServerError
+}
+
+// withRetry runs fn until it succeeds, fails with a non-retryable error or runs out of attempts.
+// Every attempt must run its own database transaction, so a retry starts from a clean state.
+func (uc *TransactionUseCase) withRetry(ctx context.Context, operation string, fn func() (*model.TransactionResponse, error)) (*model.TransactionResponse, error) {
+	delay := transactionRetryBaseDelay
+	for attempt := 1; ; attempt++ {
+		response, err := fn()
+
+		var retryErr *retryableError
+		if !errors.As(err, &retryErr) {
+			return response, err
+		}
+
+		if attempt == transactionMaxAttempts {
+			transactionRetriesExhausted.Add(operation, 1)
+			uc.Log.Errorf("Transaction %s failed after %d attempts: %v", operation, attempt, retryErr.err)
+			return nil, fiber.NewError(fiber.StatusServiceUnavailable, "Wallet is busy, please try again")
+		}
+
+		transactionRetries.Add(operation, 1)
+		uc.Log.Warnf("Transaction %s attempt %d failed, retrying: %v", operation, attempt, retryErr.err)
+
+		// Back off with jitter so competing requests do not collide again
+		wait := delay/2 + rand.N(delay)
+		select {
+		case <-ctx.Done():
+			return nil, fiber.ErrInternalServerError
+		case <-time.After(wait):
+		}
+		delay *= 2
+	}
+}
@@ -1,12 +1,17 @@
 package usecase
 
 import (
-	"backend/internal/delivery/websocket"
 	"backend/internal/entity"
 	"backend/internal/model"
 	"backend/internal/model/converter"
 	"backend/internal/repository"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
 	"time"
 
 	"github.com/go-playground/validator/v10"
@@ -23,7 +28,10 @@ type TransactionUseCase struct {
 	TransactionRepository    *repository.TransactionRepository
 	WalletRepository         *repository.WalletRepository
 	WalletMutationRepository *repository.WalletMutationRepository
-	Notifier                 websocket.NotifierInterface
+	IdempotencyKeyRepository *repository.IdempotencyKeyRepository
+	OutboxEventRepository    *repository.OutboxEventRepository
+	UserRepository           *repository.UserRepository
+	TransactionPINUseCase    *TransactionPINUseCase
 }
 
 func NewTransactionUseCase(
@@ -33,6 +41,10 @@ func NewTransactionUseCase(
 	transactionRepo *repository.TransactionRepository,
 	walletRepo *repository.WalletRepository,
 	walletMutationRepo *repository.WalletMutationRepository,
+	idempotencyKeyRepo *repository.IdempotencyKeyRepository,
+	outboxEventRepo *repository.OutboxEventRepository,
+	userRepo *repository.UserRepository,
+	transactionPINUseCase *TransactionPINUseCase,
 ) *TransactionUseCase {
 	return &TransactionUseCase{
 		DB:                       db,
@@ -41,33 +53,39 @@ func NewTransactionUseCase(
 		TransactionRepository:    transactionRepo,
 		WalletRepository:         walletRepo,
 		WalletMutationRepository: walletMutationRepo,
+		IdempotencyKeyRepository: idempotencyKeyRepo,
+		OutboxEventRepository:    outboxEventRepo,
+		UserRepository:           userRepo,
+		TransactionPINUseCase:    transactionPINUseCase,
 	}
 }
 
-// SetNotifier sets the WebSocket notifier for real-time notifications.
-func (uc *TransactionUseCase) SetNotifier(notifier websocket.NotifierInterface) {
-	uc.Notifier = notifier
+// TopUp credits a wallet from the treasury, the route requires entity.PermissionTopUpCreate.
+func (uc *TransactionUseCase) TopUp(ctx context.Context, auth *model.Auth, request *model.TopUpRequest) (*model.TransactionResponse, error) {
+	return uc.withRetry(ctx, "top_up", func() (*model.TransactionResponse, error) {
+		return uc.topUp(ctx, auth, request)
+	})
 }
 
-// TopUp handles top-up operation by super admin.
-func (uc *TransactionUseCase) TopUp(ctx context.Context, auth *model.Auth, request *model.TopUpRequest) (*model.TransactionResponse, error) {
+// topUp runs a single TopUp attempt inside one database transaction.
+func (uc *TransactionUseCase) topUp(ctx context.Context, auth *model.Auth, request *model.TopUpRequest) (*model.TransactionResponse, error) {
 	// Validate request
 	if err := uc.Validate.Struct(request); err != nil {
 		uc.Log.Warnf("Validation error: %v", err)
 		return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
 
-	// Check if user is super admin
-	if auth.Role != "super_admin" {
-		uc.Log.Warnf("Unauthorized top-up attempt by user ID: %d", *auth.UserID)
-		return nil, fiber.NewError(fiber.StatusForbidden, "Only super admin can perform top-up")
-	}
-
 	// Validate amount is positive
 	if request.Amount.LessThanOrEqual(decimal.Zero) {
 		return nil, fiber.NewError(fiber.StatusBadRequest, "Amount must be greater than zero")
 	}
 
+	// Replay the stored result if this request was already processed
+	requestHash := idempotencyRequestHash(entity.TransactionTypeTopUp, request.ToUserID, request.Amount, request.Description)
+	if response, err := uc.findIdempotentTransaction(uc.DB.WithContext(ctx), *auth.UserID, request.IdempotencyKey, requestHash); response != nil || err != nil {
+		return response, err
+	}
+
 	// Start transaction
 	tx := uc.DB.WithContext(ctx).Begin()
 	defer tx.Rollback()
@@ -76,26 +94,42 @@ func (uc *TransactionUseCase) TopUp(ctx context.Context, auth *model.Auth, reque
 	toWallet, err := uc.WalletRepository.FindByUserID(tx, request.ToUserID)
 	if err != nil {
 		uc.Log.Errorf("FindByUserID error: %v", err)
-		return nil, fiber.ErrInternalServerError
+		return nil, databaseError(err)
 	}
 	if toWallet == nil {
 		return nil, fiber.NewError(fiber.StatusNotFound, "Recipient wallet not found")
 	}
+	if toWallet.Type == entity.WalletTypeTreasury {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Cannot top up treasury wallet")
+	}
 
-	// Lock wallet for update
-	toWallet, err = uc.WalletRepository.LockForUpdate(tx, toWallet.ID)
+	// Find treasury wallet, the source of all issued money
+	treasuryWallet, err := uc.WalletRepository.FindTreasury(tx)
 	if err != nil {
-		uc.Log.Errorf("LockForUpdate error: %v", err)
+		uc.Log.Errorf("FindTreasury error: %v", err)
+		return nil, databaseError(err)
+	}
+	if treasuryWallet == nil {
+		uc.Log.Error("Treasury wallet not found")
 		return nil, fiber.ErrInternalServerError
 	}
 
+	// Lock wallets for update (lock in consistent order to prevent deadlock)
+	wallets, err := uc.lockWallets(tx, treasuryWallet.ID, toWallet.ID)
+	if err != nil {
+		uc.Log.Errorf("LockForUpdate error: %v", err)
+		return nil, databaseError(err)
+	}
+	treasuryWallet = wallets[treasuryWallet.ID]
+	toWallet = wallets[toWallet.ID]
+
 	// Create transaction record
 	description := request.Description
 	transaction := &entity.Transaction{
 		Type:              entity.TransactionTypeTopUp,
 		Amount:            request.Amount,
-		FromWalletID:      nil, // Top-up has no source wallet
-		ToWalletID:        toWallet.ID,
+		FromWalletID:      &treasuryWallet.ID,
+		ToWalletID:        &toWallet.ID,
 		PerformedByUserID: *auth.UserID,
 		Status:            entity.TransactionStatusCompleted,
 		Description:       &description,
@@ -103,7 +137,42 @@ func (uc *TransactionUseCase) TopUp(ctx context.Context, auth *model.Auth, reque
 
 	if err := uc.TransactionRepository.Create(tx, transaction); err != nil {
 		uc.Log.Errorf("Transaction creation error: %v", err)
-		return nil, fiber.ErrInternalServerError
+		return nil, databaseError(err)
+	}
+
+	// Claim the idempotency key, concurrent duplicates block here until the first request finishes
+	if request.IdempotencyKey != "" {
+		if err := uc.saveIdempotencyKey(tx, *auth.UserID, request.IdempotencyKey, requestHash, transaction.ID); err != nil {
+			if repository.IsDuplicateKeyError(err) {
+				tx.Rollback()
+				return uc.findIdempotentTransaction(uc.DB.WithContext(ctx), *auth.UserID, request.IdempotencyKey, requestHash)
+			}
+			uc.Log.Errorf("Idempotency key creation error: %v", err)
+			return nil, databaseError(err)
+		}
+	}
+
+	// Create debit mutation for treasury, it is allowed to go negative
+	treasuryBalanceAfter := treasuryWallet.Balance.Sub(request.Amount)
+
+	debitMutation := &entity.WalletMutation{
+		WalletID:      treasuryWallet.ID,
+		TransactionID: transaction.ID,
+		Type:          entity.MutationTypeDebit,
+		Amount:        request.Amount,
+		BalanceBefore: treasuryWallet.Balance,
+		BalanceAfter:  treasuryBalanceAfter,
+	}
+
+	if err := uc.WalletMutationRepository.Create(tx, debitMutation); err != nil {
+		uc.Log.Errorf("Debit mutation creation error: %v", err)
+		return nil, databaseError(err)
+	}
+
+	// Update treasury wallet balance
+	if err := uc.WalletRepository.UpdateBalance(tx, treasuryWallet, treasuryBalanceAfter); err != nil {
+		uc.Log.Errorf("UpdateBalance error for treasury: %v", err)
+		return nil, databaseError(err)
 	}
 
 	// Create wallet mutation for credit
@@ -121,52 +190,69 @@ func (uc *TransactionUseCase) TopUp(ctx context.Context, auth *model.Auth, reque
 
 	if err := uc.WalletMutationRepository.Create(tx, mutation); err != nil {
 		uc.Log.Errorf("Wallet mutation creation error: %v", err)
-		return nil, fiber.ErrInternalServerError
+		return nil, databaseError(err)
 	}
 
 	// Update wallet balance
-	if err := uc.WalletRepository.UpdateBalance(tx, toWallet.ID, balanceAfter); err != nil {
+	if err := uc.WalletRepository.UpdateBalance(tx, toWallet, balanceAfter); err != nil {
 		uc.Log.Errorf("UpdateBalance error: %v", err)
-		return nil, fiber.ErrInternalServerError
+		return nil, databaseError(err)
 	}
 
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		uc.Log.Errorf("Transaction commit error: %v", err)
-		return nil, fiber.ErrInternalServerError
+	// Resolve the recipient username for the admin feed
+	adminNotification, err := uc.adminTransactionNotification(tx, auth, transaction, nil, request.ToUserID)
+	if err != nil {
+		uc.Log.Errorf("Admin notification error: %v", err)
+		return nil, databaseError(err)
 	}
 
-	// Send real-time notification to recipient
-	if uc.Notifier != nil {
-		go func() {
-			notification := &model.TransactionNotification{
+	// Queue real-time notifications to recipient and the admin feed, delivered by the outbox dispatcher after commit
+	now := time.Now()
+	if err := uc.enqueueNotifications(tx,
+		outboxNotification{
+			UserID: request.ToUserID,
+			Type:   entity.OutboxEventTypeTransaction,
+			Payload: &model.TransactionNotification{
 				TransactionID:     transaction.ID,
 				TransactionType:   string(transaction.Type),
 				Amount:            request.Amount.String(),
 				ToUserID:          request.ToUserID,
 				PerformedByUserID: *auth.UserID,
 				Description:       transaction.Description,
-				CreatedAt:         time.Now().Format(time.RFC3339),
-			}
-			uc.Notifier.NotifyTransaction(request.ToUserID, notification)
-
-			// Also notify wallet update
-			walletNotification := &model.WalletUpdateNotification{
+				CreatedAt:         now.Format(time.RFC3339),
+			},
+		},
+		outboxNotification{
+			UserID: request.ToUserID,
+			Type:   entity.OutboxEventTypeWalletUpdate,
+			Payload: &model.WalletUpdateNotification{
 				WalletID:      toWallet.ID,
 				NewBalance:    balanceAfter.String(),
 				MutationType:  string(entity.MutationTypeCredit),
 				MutationID:    mutation.ID,
 				TransactionID: transaction.ID,
 				Amount:        request.Amount.String(),
-			}
-			uc.Notifier.NotifyWalletUpdate(request.ToUserID, walletNotification)
-		}()
+				UpdatedAt:     now,
+			},
+		},
+		outboxNotification{UserID: *auth.UserID, Type: entity.OutboxEventTypeAdminTransaction, Payload: adminNotification},
+	); err != nil {
+		uc.Log.Errorf("Outbox event creation error: %v", err)
+		return nil, databaseError(err)
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		uc.Log.Errorf("Transaction commit error: %v", err)
+		return nil, databaseError(err)
 	}
 
 	return converter.TransactionToTransactionResponse(transaction), nil
 }
 
-// Transfer handles transfer between users.
+// Transfer handles transfer between users, authorized by the transaction PIN of the sender.
+// Invalid requests and replays of a processed idempotency key are answered before the PIN is checked,
+// so neither counts as a wrong PIN attempt.
 func (uc *TransactionUseCase) Transfer(ctx context.Context, auth *model.Auth, request *model.TransferRequest) (*model.TransactionResponse, error) {
 	// Validate request
 	if err := uc.Validate.Struct(request); err != nil {
@@ -184,15 +270,40 @@ func (uc *TransactionUseCase) Transfer(ctx context.Context, auth *model.Auth, re
 		return nil, fiber.NewError(fiber.StatusBadRequest, "Cannot transfer to yourself")
 	}
 
+	// Replay the stored result if this request was already processed
+	requestHash := idempotencyRequestHash(entity.TransactionTypeTransfer, request.ToUserID, request.Amount, request.Description)
+	if response, err := uc.findIdempotentTransaction(uc.DB.WithContext(ctx), *auth.UserID, request.IdempotencyKey, requestHash); response != nil || err != nil {
+		return response, err
+	}
+
+	if err := uc.verifyPIN(ctx, auth, request.PIN); err != nil {
+		return nil, err
+	}
+
+	return uc.withRetry(ctx, "transfer", func() (*model.TransactionResponse, error) {
+		return uc.transfer(ctx, auth, request, requestHash)
+	})
+}
+
+// transfer runs a single Transfer attempt of a validated request inside one database transaction.
+func (uc *TransactionUseCase) transfer(ctx context.Context, auth *model.Auth, request *model.TransferRequest, requestHash string) (*model.TransactionResponse, error) {
 	// Start transaction
 	tx := uc.DB.WithContext(ctx).Begin()
 	defer tx.Rollback()
 
-	// Find sender wallet
-	fromWallet, err := uc.WalletRepository.FindByUserID(tx, *auth.UserID)
+	fromTreasury := entity.RoleHasPermission(auth.Role, entity.PermissionTreasuryTransfer)
+
+	// Find sender wallet, transfers of roles allowed to issue money come from the treasury
+	var fromWallet *entity.Wallet
+	var err error
+	if fromTreasury {
+		fromWallet, err = uc.WalletRepository.FindTreasury(tx)
+	} else {
+		fromWallet, err = uc.WalletRepository.FindByUserID(tx, *auth.UserID)
+	}
 	if err != nil {
-		uc.Log.Errorf("FindByUserID error for sender: %v", err)
-		return nil, fiber.ErrInternalServerError
+		uc.Log.Errorf("Find sender wallet error: %v", err)
+		return nil, databaseError(err)
 	}
 	if fromWallet == nil {
 		return nil, fiber.NewError(fiber.StatusNotFound, "Your wallet not found")
@@ -202,47 +313,27 @@ func (uc *TransactionUseCase) Transfer(ctx context.Context, auth *model.Auth, re
 	toWallet, err := uc.WalletRepository.FindByUserID(tx, request.ToUserID)
 	if err != nil {
 		uc.Log.Errorf("FindByUserID error for recipient: %v", err)
-		return nil, fiber.ErrInternalServerError
+		return nil, databaseError(err)
 	}
 	if toWallet == nil {
 		return nil, fiber.NewError(fiber.StatusNotFound, "Recipient wallet not found")
 	}
+	if toWallet.Type == entity.WalletTypeTreasury {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Cannot transfer to treasury wallet")
+	}
 
 	// Lock wallets for update (lock in consistent order to prevent deadlock)
-	var firstWallet, secondWallet *entity.Wallet
-	if fromWallet.ID < toWallet.ID {
-		firstWallet, err = uc.WalletRepository.LockForUpdate(tx, fromWallet.ID)
-		if err != nil {
-			uc.Log.Errorf("LockForUpdate error: %v", err)
-			return nil, fiber.ErrInternalServerError
-		}
-		secondWallet, err = uc.WalletRepository.LockForUpdate(tx, toWallet.ID)
-		if err != nil {
-			uc.Log.Errorf("LockForUpdate error: %v", err)
-			return nil, fiber.ErrInternalServerError
-		}
-		fromWallet = firstWallet
-		toWallet = secondWallet
-	} else {
-		firstWallet, err = uc.WalletRepository.LockForUpdate(tx, toWallet.ID)
-		if err != nil {
-			uc.Log.Errorf("LockForUpdate error: %v", err)
-			return nil, fiber.ErrInternalServerError
-		}
-		secondWallet, err = uc.WalletRepository.LockForUpdate(tx, fromWallet.ID)
-		if err != nil {
-			uc.Log.Errorf("LockForUpdate error: %v", err)
-			return nil, fiber.ErrInternalServerError
-		}
-		toWallet = firstWallet
-		fromWallet = secondWallet
+	wallets, err := uc.lockWallets(tx, fromWallet.ID, toWallet.ID)
+	if err != nil {
+		uc.Log.Errorf("LockForUpdate error: %v", err)
+		return nil, databaseError(err)
 	}
+	fromWallet = wallets[fromWallet.ID]
+	toWallet = wallets[toWallet.ID]
 
-	isSuperAdmin := auth.Role == "super_admin"
-
-	// Check sufficient balance (Skip for Super Admin)
-	if !isSuperAdmin {
-		if fromWallet.Balance.LessThan(request.Amount) {
+	// Check sufficient balance (Skip for treasury, it is allowed to go negative)
+	if !fromTreasury {
+		if fromWallet.AvailableBalance().LessThan(request.Amount) {
 			return nil, fiber.NewError(fiber.StatusBadRequest, "Insufficient balance")
 		}
 	}
@@ -253,7 +344,7 @@ func (uc *TransactionUseCase) Transfer(ctx context.Context, auth *model.Auth, re
 		Type:              entity.TransactionTypeTransfer,
 		Amount:            request.Amount,
 		FromWalletID:      &fromWallet.ID,
-		ToWalletID:        toWallet.ID,
+		ToWalletID:        &toWallet.ID,
 		PerformedByUserID: *auth.UserID,
 		Status:            entity.TransactionStatusCompleted,
 		Description:       &description,
@@ -261,37 +352,43 @@ func (uc *TransactionUseCase) Transfer(ctx context.Context, auth *model.Auth, re
 
 	if err := uc.TransactionRepository.Create(tx, transaction); err != nil {
 		uc.Log.Errorf("Transaction creation error: %v", err)
-		return nil, fiber.ErrInternalServerError
+		return nil, databaseError(err)
 	}
 
-	// Variables for notification
-	var fromBalanceAfter decimal.Decimal = fromWallet.Balance
-	var debitMutation *entity.WalletMutation
+	// Claim the idempotency key, concurrent duplicates block here until the first request finishes
+	if request.IdempotencyKey != "" {
+		if err := uc.saveIdempotencyKey(tx, *auth.UserID, request.IdempotencyKey, requestHash, transaction.ID); err != nil {
+			if repository.IsDuplicateKeyError(err) {
+				tx.Rollback()
+				return uc.findIdempotentTransaction(uc.DB.WithContext(ctx), *auth.UserID, request.IdempotencyKey, requestHash)
+			}
+			uc.Log.Errorf("Idempotency key creation error: %v", err)
+			return nil, databaseError(err)
+		}
+	}
 
-	// Create debit mutation & update balance for sender (Skip for Super Admin)
-	if !isSuperAdmin {
-		fromBalanceBefore := fromWallet.Balance
-		fromBalanceAfter = fromWallet.Balance.Sub(request.Amount)
+	// Create debit mutation & update balance for sender
+	fromBalanceBefore := fromWallet.Balance
+	fromBalanceAfter := fromWallet.Balance.Sub(request.Amount)
 
-		debitMutation = &entity.WalletMutation{
-			WalletID:      fromWallet.ID,
-			TransactionID: transaction.ID,
-			Type:          entity.MutationTypeDebit,
-			Amount:        request.Amount,
-			BalanceBefore: fromBalanceBefore,
-			BalanceAfter:  fromBalanceAfter,
-		}
+	debitMutation := &entity.WalletMutation{
+		WalletID:      fromWallet.ID,
+		TransactionID: transaction.ID,
+		Type:          entity.MutationTypeDebit,
+		Amount:        request.Amount,
+		BalanceBefore: fromBalanceBefore,
+		BalanceAfter:  fromBalanceAfter,
+	}
 
-		if err := uc.WalletMutationRepository.Create(tx, debitMutation); err != nil {
-			uc.Log.Errorf("Debit mutation creation error: %v", err)
-			return nil, fiber.ErrInternalServerError
-		}
+	if err := uc.WalletMutationRepository.Create(tx, debitMutation); err != nil {
+		uc.Log.Errorf("Debit mutation creation error: %v", err)
+		return nil, databaseError(err)
+	}
 
-		// Update sender wallet balance
-		if err := uc.WalletRepository.UpdateBalance(tx, fromWallet.ID, fromBalanceAfter); err != nil {
-			uc.Log.Errorf("UpdateBalance error for sender: %v", err)
-			return nil, fiber.ErrInternalServerError
-		}
+	// Update sender wallet balance
+	if err := uc.WalletRepository.UpdateBalance(tx, fromWallet, fromBalanceAfter); err != nil {
+		uc.Log.Errorf("UpdateBalance error for sender: %v", err)
+		return nil, databaseError(err)
 	}
 
 	// Create credit mutation for recipient
@@ -309,88 +406,728 @@ func (uc *TransactionUseCase) Transfer(ctx context.Context, auth *model.Auth, re
 
 	if err := uc.WalletMutationRepository.Create(tx, creditMutation); err != nil {
 		uc.Log.Errorf("Credit mutation creation error: %v", err)
-		return nil, fiber.ErrInternalServerError
+		return nil, databaseError(err)
 	}
 
 	// Update recipient wallet balance
-	if err := uc.WalletRepository.UpdateBalance(tx, toWallet.ID, toBalanceAfter); err != nil {
+	if err := uc.WalletRepository.UpdateBalance(tx, toWallet, toBalanceAfter); err != nil {
 		uc.Log.Errorf("UpdateBalance error for recipient: %v", err)
-		return nil, fiber.ErrInternalServerError
+		return nil, databaseError(err)
 	}
 
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		uc.Log.Errorf("Transaction commit error: %v", err)
-		return nil, fiber.ErrInternalServerError
+	// Queue real-time notifications, delivered by the outbox dispatcher after commit
+	now := time.Now()
+	transactionNotification := &model.TransactionNotification{
+		TransactionID:     transaction.ID,
+		TransactionType:   string(transaction.Type),
+		Amount:            request.Amount.String(),
+		FromUserID:        auth.UserID,
+		ToUserID:          request.ToUserID,
+		PerformedByUserID: *auth.UserID,
+		Description:       transaction.Description,
+		CreatedAt:         now.Format(time.RFC3339),
 	}
 
-	// Send real-time notifications
-	if uc.Notifier != nil {
-		go func() {
-			// Notification for sender (confirmation)
-			senderNotification := &model.TransactionNotification{
-				TransactionID:     transaction.ID,
-				TransactionType:   string(transaction.Type),
-				Amount:            request.Amount.String(),
-				FromUserID:        auth.UserID,
-				ToUserID:          request.ToUserID,
-				PerformedByUserID: *auth.UserID,
-				Description:       transaction.Description,
-				CreatedAt:         time.Now().Format(time.RFC3339),
-			}
-			uc.Notifier.NotifyTransaction(*auth.UserID, senderNotification)
-
-			// Wallet update for sender (Skip for Super Admin)
-			if !isSuperAdmin && debitMutation != nil {
-				senderWalletNotification := &model.WalletUpdateNotification{
-					WalletID:      fromWallet.ID,
-					NewBalance:    fromBalanceAfter.String(),
-					MutationType:  string(entity.MutationTypeDebit),
-					MutationID:    debitMutation.ID,
-					TransactionID: transaction.ID,
-					Amount:        request.Amount.String(),
-				}
-				uc.Notifier.NotifyWalletUpdate(*auth.UserID, senderWalletNotification)
-			}
+	// Notification for sender (confirmation)
+	notifications := []outboxNotification{
+		{UserID: *auth.UserID, Type: entity.OutboxEventTypeTransaction, Payload: transactionNotification},
+	}
 
-			// Notification for recipient
-			recipientNotification := &model.TransactionNotification{
-				TransactionID:     transaction.ID,
-				TransactionType:   string(transaction.Type),
-				Amount:            request.Amount.String(),
-				FromUserID:        auth.UserID,
-				ToUserID:          request.ToUserID,
-				PerformedByUserID: *auth.UserID,
-				Description:       transaction.Description,
-				CreatedAt:         time.Now().Format(time.RFC3339),
-			}
-			uc.Notifier.NotifyTransaction(request.ToUserID, recipientNotification)
+	// Wallet update for sender (Skip for treasury transfers, the treasury was debited)
+	if !fromTreasury {
+		notifications = append(notifications, outboxNotification{
+			UserID: *auth.UserID,
+			Type:   entity.OutboxEventTypeWalletUpdate,
+			Payload: &model.WalletUpdateNotification{
+				WalletID:      fromWallet.ID,
+				NewBalance:    fromBalanceAfter.String(),
+				MutationType:  string(entity.MutationTypeDebit),
+				MutationID:    debitMutation.ID,
+				TransactionID: transaction.ID,
+				Amount:        request.Amount.String(),
+				UpdatedAt:     now,
+			},
+		})
+	}
 
-			// Wallet update for recipient
-			recipientWalletNotification := &model.WalletUpdateNotification{
+	// Notification and wallet update for recipient
+	notifications = append(notifications,
+		outboxNotification{UserID: request.ToUserID, Type: entity.OutboxEventTypeTransaction, Payload: transactionNotification},
+		outboxNotification{
+			UserID: request.ToUserID,
+			Type:   entity.OutboxEventTypeWalletUpdate,
+			Payload: &model.WalletUpdateNotification{
 				WalletID:      toWallet.ID,
 				NewBalance:    toBalanceAfter.String(),
 				MutationType:  string(entity.MutationTypeCredit),
 				MutationID:    creditMutation.ID,
 				TransactionID: transaction.ID,
 				Amount:        request.Amount.String(),
+				UpdatedAt:     now,
+			},
+		},
+	)
+
+	// Stream the transfer to the admin feed with both usernames
+	adminNotification, err := uc.adminTransactionNotification(tx, auth, transaction, auth.UserID, request.ToUserID)
+	if err != nil {
+		uc.Log.Errorf("Admin notification error: %v", err)
+		return nil, databaseError(err)
+	}
+	notifications = append(notifications, outboxNotification{UserID: *auth.UserID, Type: entity.OutboxEventTypeAdminTransaction, Payload: adminNotification})
+
+	if err := uc.enqueueNotifications(tx, notifications...); err != nil {
+		uc.Log.Errorf("Outbox event creation error: %v", err)
+		return nil, databaseError(err)
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		uc.Log.Errorf("Transaction commit error: %v", err)
+		return nil, databaseError(err)
+	}
+
+	return converter.TransactionToTransactionResponse(transaction), nil
+}
+
+// Withdraw creates a pending withdraw and holds the amount on the user's wallet until it is settled.
+// It is authorized by the transaction PIN of the user, which like in Transfer is checked after validation
+// and idempotent replays.
+func (uc *TransactionUseCase) Withdraw(ctx context.Context, auth *model.Auth, request *model.WithdrawRequest) (*model.TransactionResponse, error) {
+	// Validate request
+	if err := uc.Validate.Struct(request); err != nil {
+		uc.Log.Warnf("Validation error: %v", err)
+		return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	// Validate amount is positive
+	if request.Amount.LessThanOrEqual(decimal.Zero) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Amount must be greater than zero")
+	}
+
+	// Replay the stored result if this request was already processed
+	requestHash := idempotencyRequestHash(entity.TransactionTypeWithdraw, *auth.UserID, request.Amount, request.Description)
+	if response, err := uc.findIdempotentTransaction(uc.DB.WithContext(ctx), *auth.UserID, request.IdempotencyKey, requestHash); response != nil || err != nil {
+		return response, err
+	}
+
+	if err := uc.verifyPIN(ctx, auth, request.PIN); err != nil {
+		return nil, err
+	}
+
+	return uc.withRetry(ctx, "withdraw", func() (*model.TransactionResponse, error) {
+		return uc.withdraw(ctx, auth, request, requestHash)
+	})
+}
+
+// withdraw runs a single Withdraw attempt of a validated request inside one database transaction.
+func (uc *TransactionUseCase) withdraw(ctx context.Context, auth *model.Auth, request *model.WithdrawRequest, requestHash string) (*model.TransactionResponse, error) {
+	// Start transaction
+	tx := uc.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	// Find user wallet
+	wallet, err := uc.WalletRepository.FindByUserID(tx, *auth.UserID)
+	if err != nil {
+		uc.Log.Errorf("FindByUserID error: %v", err)
+		return nil, databaseError(err)
+	}
+	if wallet == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Your wallet not found")
+	}
+
+	// Lock wallet for update
+	wallet, err = uc.WalletRepository.LockForUpdate(tx, wallet.ID)
+	if err != nil {
+		uc.Log.Errorf("LockForUpdate error: %v", err)
+		return nil, databaseError(err)
+	}
+
+	// Check sufficient balance, funds already held by other withdrawals are not available
+	if wallet.AvailableBalance().LessThan(request.Amount) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Insufficient balance")
+	}
+
+	// Withdrawn money goes back to the treasury once the withdraw is completed
+	treasuryWallet, err := uc.WalletRepository.FindTreasury(tx)
+	if err != nil {
+		uc.Log.Errorf("FindTreasury error: %v", err)
+		return nil, databaseError(err)
+	}
+	if treasuryWallet == nil {
+		uc.Log.Error("Treasury wallet not found")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	// Create pending transaction record
+	description := request.Description
+	transaction := &entity.Transaction{
+		Type:              entity.TransactionTypeWithdraw,
+		Amount:            request.Amount,
+		FromWalletID:      &wallet.ID,
+		ToWalletID:        &treasuryWallet.ID,
+		PerformedByUserID: *auth.UserID,
+		Status:            entity.TransactionStatusPending,
+		Description:       &description,
+	}
+
+	if err := uc.TransactionRepository.Create(tx, transaction); err != nil {
+		uc.Log.Errorf("Transaction creation error: %v", err)
+		return nil, databaseError(err)
+	}
+
+	// Claim the idempotency key, concurrent duplicates block here until the first request finishes
+	if request.IdempotencyKey != "" {
+		if err := uc.saveIdempotencyKey(tx, *auth.UserID, request.IdempotencyKey, requestHash, transaction.ID); err != nil {
+			if repository.IsDuplicateKeyError(err) {
+				tx.Rollback()
+				return uc.findIdempotentTransaction(uc.DB.WithContext(ctx), *auth.UserID, request.IdempotencyKey, requestHash)
 			}
-			uc.Notifier.NotifyWalletUpdate(request.ToUserID, recipientWalletNotification)
-		}()
+			uc.Log.Errorf("Idempotency key creation error: %v", err)
+			return nil, databaseError(err)
+		}
+	}
+
+	// Put a hold on the funds
+	if err := uc.WalletRepository.UpdateHeldBalance(tx, wallet, wallet.HeldBalance.Add(request.Amount)); err != nil {
+		uc.Log.Errorf("UpdateHeldBalance error: %v", err)
+		return nil, databaseError(err)
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		uc.Log.Errorf("Transaction commit error: %v", err)
+		return nil, databaseError(err)
 	}
 
 	return converter.TransactionToTransactionResponse(transaction), nil
 }
 
-// GetTransactionsByUserID retrieves transactions for a user.
-func (uc *TransactionUseCase) GetTransactionsByUserID(ctx context.Context, userID uint, page, limit int) (*model.TransactionListResponse, error) {
-	if page <= 0 {
-		page = 1
+// SettleWithdraw completes or fails a pending withdraw, the route requires entity.PermissionTransactionsSettle.
+// Completing debits the held funds from the wallet, failing releases the hold.
+func (uc *TransactionUseCase) SettleWithdraw(ctx context.Context, auth *model.Auth, request *model.SettleWithdrawRequest) (*model.TransactionResponse, error) {
+	return uc.withRetry(ctx, "settle_withdraw", func() (*model.TransactionResponse, error) {
+		return uc.settleWithdraw(ctx, auth, request)
+	})
+}
+
+// settleWithdraw runs a single SettleWithdraw attempt inside one database transaction.
+func (uc *TransactionUseCase) settleWithdraw(ctx context.Context, auth *model.Auth, request *model.SettleWithdrawRequest) (*model.TransactionResponse, error) {
+	// Validate request
+	if err := uc.Validate.Struct(request); err != nil {
+		uc.Log.Warnf("Validation error: %v", err)
+		return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
-	if limit <= 0 {
-		limit = 10
+
+	// Start transaction
+	tx := uc.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	// Find withdraw transaction
+	transaction := new(entity.Transaction)
+	if err := uc.TransactionRepository.FindByID(tx, transaction, request.TransactionID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "Transaction not found")
+		}
+		uc.Log.Errorf("FindByID error: %v", err)
+		return nil, databaseError(err)
 	}
+	if transaction.Type != entity.TransactionTypeWithdraw {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Transaction is not a withdraw")
+	}
+	if transaction.Status != entity.TransactionStatusPending {
+		return nil, fiber.NewError(fiber.StatusConflict, "Withdraw already settled")
+	}
+
+	// Find treasury wallet, it receives the withdrawn money
+	treasuryWallet, err := uc.WalletRepository.FindTreasury(tx)
+	if err != nil {
+		uc.Log.Errorf("FindTreasury error: %v", err)
+		return nil, databaseError(err)
+	}
+	if treasuryWallet == nil {
+		uc.Log.Error("Treasury wallet not found")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	// Lock wallets for update (lock in consistent order to prevent deadlock)
+	wallets, err := uc.lockWallets(tx, *transaction.FromWalletID, treasuryWallet.ID)
+	if err != nil {
+		uc.Log.Errorf("LockForUpdate error: %v", err)
+		return nil, databaseError(err)
+	}
+	wallet, ok := wallets[*transaction.FromWalletID]
+	if !ok {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Wallet not found")
+	}
+	treasuryWallet = wallets[treasuryWallet.ID]
+
+	// Move status only if nobody settled it in the meantime
+	status := entity.TransactionStatus(request.Status)
+	affected, err := uc.TransactionRepository.UpdateStatus(tx, transaction.ID, entity.TransactionStatusPending, status)
+	if err != nil {
+		uc.Log.Errorf("UpdateStatus error: %v", err)
+		return nil, databaseError(err)
+	}
+	if affected == 0 {
+		return nil, fiber.NewError(fiber.StatusConflict, "Withdraw already settled")
+	}
+	transaction.Status = status
+
+	// Release the hold in both outcomes
+	if err := uc.WalletRepository.UpdateHeldBalance(tx, wallet, wallet.HeldBalance.Sub(transaction.Amount)); err != nil {
+		uc.Log.Errorf("UpdateHeldBalance error: %v", err)
+		return nil, databaseError(err)
+	}
+
+	// Create debit mutation & update balance when completed
+	if status == entity.TransactionStatusCompleted {
+		balanceAfter := wallet.Balance.Sub(transaction.Amount)
+
+		debitMutation := &entity.WalletMutation{
+			WalletID:      wallet.ID,
+			TransactionID: transaction.ID,
+			Type:          entity.MutationTypeDebit,
+			Amount:        transaction.Amount,
+			BalanceBefore: wallet.Balance,
+			BalanceAfter:  balanceAfter,
+		}
+
+		if err := uc.WalletMutationRepository.Create(tx, debitMutation); err != nil {
+			uc.Log.Errorf("Debit mutation creation error: %v", err)
+			return nil, databaseError(err)
+		}
+
+		if err := uc.WalletRepository.UpdateBalance(tx, wallet, balanceAfter); err != nil {
+			uc.Log.Errorf("UpdateBalance error: %v", err)
+			return nil, databaseError(err)
+		}
+
+		// Credit treasury so the withdrawn money is no longer counted as issued
+		treasuryBalanceAfter := treasuryWallet.Balance.Add(transaction.Amount)
+
+		creditMutation := &entity.WalletMutation{
+			WalletID:      treasuryWallet.ID,
+			TransactionID: transaction.ID,
+			Type:          entity.MutationTypeCredit,
+			Amount:        transaction.Amount,
+			BalanceBefore: treasuryWallet.Balance,
+			BalanceAfter:  treasuryBalanceAfter,
+		}
+
+		if err := uc.WalletMutationRepository.Create(tx, creditMutation); err != nil {
+			uc.Log.Errorf("Credit mutation creation error: %v", err)
+			return nil, databaseError(err)
+		}
+
+		if err := uc.WalletRepository.UpdateBalance(tx, treasuryWallet, treasuryBalanceAfter); err != nil {
+			uc.Log.Errorf("UpdateBalance error for treasury: %v", err)
+			return nil, databaseError(err)
+		}
+
+		// Queue real-time notification to wallet owner
+		if err := uc.enqueueNotifications(tx, outboxNotification{
+			UserID: wallet.UserID,
+			Type:   entity.OutboxEventTypeWalletUpdate,
+			Payload: &model.WalletUpdateNotification{
+				WalletID:      wallet.ID,
+				NewBalance:    balanceAfter.String(),
+				MutationType:  string(entity.MutationTypeDebit),
+				MutationID:    debitMutation.ID,
+				TransactionID: transaction.ID,
+				Amount:        transaction.Amount.String(),
+				UpdatedAt:     time.Now(),
+			},
+		}); err != nil {
+			uc.Log.Errorf("Outbox event creation error: %v", err)
+			return nil, databaseError(err)
+		}
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		uc.Log.Errorf("Transaction commit error: %v", err)
+		return nil, databaseError(err)
+	}
+
+	uc.Log.Infof("Withdraw transaction ID %d settled as %s by user ID: %d", transaction.ID, status, *auth.UserID)
+
+	return converter.TransactionToTransactionResponse(transaction), nil
+}
+
+// Reverse undoes a completed top-up or transfer, fully or partially, the route requires entity.PermissionTransactionsReverse.
+// It creates a linked reversal transaction whose mutations mirror the original ones.
+func (uc *TransactionUseCase) Reverse(ctx context.Context, auth *model.Auth, request *model.ReverseTransactionRequest) (*model.TransactionResponse, error) {
+	return uc.withRetry(ctx, "reverse", func() (*model.TransactionResponse, error) {
+		return uc.reverse(ctx, auth, request)
+	})
+}
+
+// reverse runs a single Reverse attempt inside one database transaction.
+func (uc *TransactionUseCase) reverse(ctx context.Context, auth *model.Auth, request *model.ReverseTransactionRequest) (*model.TransactionResponse, error) {
+	// Validate request
+	if err := uc.Validate.Struct(request); err != nil {
+		uc.Log.Warnf("Validation error: %v", err)
+		return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	// Validate amount is not negative, zero means the remaining amount
+	if request.Amount.LessThan(decimal.Zero) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Amount must be greater than zero")
+	}
+
+	// Start transaction
+	tx := uc.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	// Lock original transaction so concurrent reversals see each other
+	original, err := uc.TransactionRepository.LockForUpdate(tx, request.TransactionID)
+	if err != nil {
+		uc.Log.Errorf("LockForUpdate error: %v", err)
+		return nil, databaseError(err)
+	}
+	if original == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Transaction not found")
+	}
+	if original.Type != entity.TransactionTypeTopUp && original.Type != entity.TransactionTypeTransfer {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Only top-up and transfer can be reversed")
+	}
+	if original.Status != entity.TransactionStatusCompleted {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Only completed transaction can be reversed")
+	}
+
+	// Refuse to reverse more than what is left of the original amount
+	reversed, err := uc.TransactionRepository.SumReversedAmount(tx, original.ID)
+	if err != nil {
+		uc.Log.Errorf("SumReversedAmount error: %v", err)
+		return nil, databaseError(err)
+	}
+	remaining := original.Amount.Sub(reversed)
+	if remaining.LessThanOrEqual(decimal.Zero) {
+		return nil, fiber.NewError(fiber.StatusConflict, "Transaction already fully reversed")
+	}
+	amount := request.Amount
+	if amount.IsZero() {
+		amount = remaining
+	}
+	if amount.GreaterThan(remaining) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Amount exceeds reversible amount of %s", remaining.String()))
+	}
+
+	// Mirror the mutations of the original transaction
+	originalMutations, err := uc.WalletMutationRepository.FindByTransactionID(tx, original.ID)
+	if err != nil {
+		uc.Log.Errorf("FindByTransactionID error: %v", err)
+		return nil, databaseError(err)
+	}
+
+	// Each leg of the reversal undoes one leg of the original
+	legs := make([]reversalLeg, 0, len(originalMutations)+1)
+	hasDebit := false
+	for _, mutation := range originalMutations {
+		if mutation.Type == entity.MutationTypeCredit {
+			legs = append(legs, reversalLeg{WalletID: mutation.WalletID, Type: entity.MutationTypeDebit})
+		} else {
+			legs = append(legs, reversalLeg{WalletID: mutation.WalletID, Type: entity.MutationTypeCredit})
+			hasDebit = true
+		}
+	}
+
+	// Top-ups and super admin transfers booked before the treasury existed never debited anyone,
+	// the money they issued is part of the treasury opening balance and goes back to the treasury
+	toWalletID := original.FromWalletID
+	if !hasDebit {
+		treasuryWallet, err := uc.WalletRepository.FindTreasury(tx)
+		if err != nil {
+			uc.Log.Errorf("FindTreasury error: %v", err)
+			return nil, databaseError(err)
+		}
+		if treasuryWallet == nil {
+			uc.Log.Error("Treasury wallet not found")
+			return nil, fiber.ErrInternalServerError
+		}
+		legs = append(legs, reversalLeg{WalletID: treasuryWallet.ID, Type: entity.MutationTypeCredit})
+		toWalletID = &treasuryWallet.ID
+	}
+
+	walletIDs := make([]uint, 0, len(legs))
+	for _, leg := range legs {
+		walletIDs = append(walletIDs, leg.WalletID)
+	}
+
+	wallets, err := uc.lockWallets(tx, walletIDs...)
+	if err != nil {
+		uc.Log.Errorf("LockForUpdate error: %v", err)
+		return nil, databaseError(err)
+	}
+
+	// The original recipient must still hold the money being taken back
+	for _, leg := range legs {
+		wallet, ok := wallets[leg.WalletID]
+		if !ok {
+			return nil, fiber.NewError(fiber.StatusNotFound, "Wallet not found")
+		}
+		if leg.Type == entity.MutationTypeDebit && wallet.AvailableBalance().LessThan(amount) {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Insufficient balance to reverse")
+		}
+	}
+
+	// Create reversal transaction record, money flows back from recipient to sender
+	description := request.Description
+	transaction := &entity.Transaction{
+		Type:                  entity.TransactionTypeReversal,
+		Amount:                amount,
+		FromWalletID:          original.ToWalletID,
+		ToWalletID:            toWalletID,
+		PerformedByUserID:     *auth.UserID,
+		ReversedTransactionID: &original.ID,
+		Status:                entity.TransactionStatusCompleted,
+		Description:           &description,
+	}
+
+	if err := uc.TransactionRepository.Create(tx, transaction); err != nil {
+		uc.Log.Errorf("Transaction creation error: %v", err)
+		return nil, databaseError(err)
+	}
+
+	// Create compensating mutations & update balances
+	mutations := make([]*entity.WalletMutation, 0, len(legs))
+	for _, leg := range legs {
+		wallet := wallets[leg.WalletID]
+
+		mutation := &entity.WalletMutation{
+			WalletID:      wallet.ID,
+			TransactionID: transaction.ID,
+			Type:          leg.Type,
+			Amount:        amount,
+			BalanceBefore: wallet.Balance,
+		}
+		if leg.Type == entity.MutationTypeDebit {
+			mutation.BalanceAfter = wallet.Balance.Sub(amount)
+		} else {
+			mutation.BalanceAfter = wallet.Balance.Add(amount)
+		}
+
+		if err := uc.WalletMutationRepository.Create(tx, mutation); err != nil {
+			uc.Log.Errorf("Reversal mutation creation error: %v", err)
+			return nil, databaseError(err)
+		}
+
+		if err := uc.WalletRepository.UpdateBalance(tx, wallet, mutation.BalanceAfter); err != nil {
+			uc.Log.Errorf("UpdateBalance error: %v", err)
+			return nil, databaseError(err)
+		}
+
+		mutations = append(mutations, mutation)
+	}
+
+	// Queue real-time notifications to both parties, delivered by the outbox dispatcher after commit
+	var fromUserID *uint
+	var toUserID uint
+	for _, mutation := range mutations {
+		if mutation.Type == entity.MutationTypeDebit {
+			fromUserID = &wallets[mutation.WalletID].UserID
+		} else {
+			toUserID = wallets[mutation.WalletID].UserID
+		}
+	}
+
+	now := time.Now()
+	notifications := make([]outboxNotification, 0, len(mutations)*2)
+	for _, mutation := range mutations {
+		wallet := wallets[mutation.WalletID]
+
+		notifications = append(notifications,
+			outboxNotification{
+				UserID: wallet.UserID,
+				Type:   entity.OutboxEventTypeTransaction,
+				Payload: &model.TransactionNotification{
+					TransactionID:     transaction.ID,
+					TransactionType:   string(transaction.Type),
+					Amount:            amount.String(),
+					FromUserID:        fromUserID,
+					ToUserID:          toUserID,
+					PerformedByUserID: *auth.UserID,
+					Description:       transaction.Description,
+					CreatedAt:         now.Format(time.RFC3339),
+				},
+			},
+			outboxNotification{
+				UserID: wallet.UserID,
+				Type:   entity.OutboxEventTypeWalletUpdate,
+				Payload: &model.WalletUpdateNotification{
+					WalletID:      wallet.ID,
+					NewBalance:    mutation.BalanceAfter.String(),
+					MutationType:  string(mutation.Type),
+					MutationID:    mutation.ID,
+					TransactionID: transaction.ID,
+					Amount:        amount.String(),
+					UpdatedAt:     now,
+				},
+			},
+		)
+	}
+
+	if err := uc.enqueueNotifications(tx, notifications...); err != nil {
+		uc.Log.Errorf("Outbox event creation error: %v", err)
+		return nil, databaseError(err)
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		uc.Log.Errorf("Transaction commit error: %v", err)
+		return nil, databaseError(err)
+	}
+
+	uc.Log.Infof("Transaction ID %d reversed by transaction ID %d for amount %s by user ID: %d", original.ID, transaction.ID, amount.String(), *auth.UserID)
+
+	return converter.TransactionToTransactionResponse(transaction), nil
+}
+
+// reversalLeg is a wallet the reversal moves money on and the direction it moves it.
+type reversalLeg struct {
+	WalletID uint
+	Type     entity.MutationType
+}
+
+// verifyPIN checks the transaction PIN before money leaves a wallet, once per request and not per retry.
+func (uc *TransactionUseCase) verifyPIN(ctx context.Context, auth *model.Auth, pin string) error {
+	if err := uc.Validate.Var(pin, "required,numeric,len=6"); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Transaction PIN must be 6 digits")
+	}
+	return uc.TransactionPINUseCase.Verify(ctx, *auth.UserID, pin)
+}
+
+// lockWallets locks the given wallets in ascending ID order to prevent deadlocks.
+// Wallets that do not exist are left out of the returned map.
+func (uc *TransactionUseCase) lockWallets(tx *gorm.DB, walletIDs ...uint) (map[uint]*entity.Wallet, error) {
+	ids := slices.Clone(walletIDs)
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	wallets := make(map[uint]*entity.Wallet, len(ids))
+	for _, id := range ids {
+		wallet, err := uc.WalletRepository.LockForUpdate(tx, id)
+		if err != nil {
+			return nil, err
+		}
+		if wallet != nil {
+			wallets[id] = wallet
+		}
+	}
+
+	return wallets, nil
+}
+
+// findIdempotentTransaction returns the stored transaction of a previously used idempotency key.
+// It returns nil when no key is given or the key has not been used yet.
+func (uc *TransactionUseCase) findIdempotentTransaction(db *gorm.DB, userID uint, key string, requestHash string) (*model.TransactionResponse, error) {
+	if key == "" {
+		return nil, nil
+	}
+
+	idempotencyKey, err := uc.IdempotencyKeyRepository.FindByUserIDAndKey(db, userID, key)
+	if err != nil {
+		uc.Log.Errorf("FindByUserIDAndKey error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	if idempotencyKey == nil {
+		return nil, nil
+	}
+
+	// Same key with a different payload is a client error, not a retry
+	if idempotencyKey.RequestHash != requestHash {
+		uc.Log.Warnf("Idempotency key reused with different payload by user ID: %d", userID)
+		return nil, fiber.NewError(fiber.StatusConflict, "Idempotency key already used with a different request")
+	}
+
+	transaction := new(entity.Transaction)
+	if err := uc.TransactionRepository.FindByID(db, transaction, idempotencyKey.TransactionID); err != nil {
+		uc.Log.Errorf("FindByID error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	uc.Log.Infof("Replaying transaction ID %d for idempotency key of user ID: %d", transaction.ID, userID)
+	return converter.TransactionToTransactionResponse(transaction), nil
+}
+
+// saveIdempotencyKey stores the idempotency key together with the transaction it produced.
+func (uc *TransactionUseCase) saveIdempotencyKey(tx *gorm.DB, userID uint, key string, requestHash string, transactionID uint) error {
+	return uc.IdempotencyKeyRepository.Create(tx, &entity.IdempotencyKey{
+		UserID:        userID,
+		Key:           key,
+		RequestHash:   requestHash,
+		TransactionID: transactionID,
+	})
+}
+
+// outboxNotification is a real-time notification waiting to be written to the outbox.
+type outboxNotification struct {
+	UserID  uint
+	Type    entity.OutboxEventType
+	Payload any
+}
+
+// enqueueNotifications stores notifications in the outbox as part of tx,
+// so they are only delivered when the change they describe is committed.
+func (uc *TransactionUseCase) enqueueNotifications(tx *gorm.DB, notifications ...outboxNotification) error {
+	now := time.Now()
+	for _, notification := range notifications {
+		payload, err := json.Marshal(notification.Payload)
+		if err != nil {
+			return err
+		}
+
+		if err := uc.OutboxEventRepository.Create(tx, &entity.OutboxEvent{
+			UserID:        notification.UserID,
+			EventType:     notification.Type,
+			Payload:       payload,
+			Status:        entity.OutboxEventStatusPending,
+			NextAttemptAt: now,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// adminTransactionNotification builds the admin feed event of a transaction, looking up the recipient username.
+// The sender, when there is one, is the authenticated user.
+func (uc *TransactionUseCase) adminTransactionNotification(tx *gorm.DB, auth *model.Auth, transaction *entity.Transaction, fromUserID *uint, toUserID uint) (*model.AdminTransactionNotification, error) {
+	recipient, err := uc.UserRepository.FindByID(tx, toUserID)
+	if err != nil {
+		return nil, err
+	}
+	if recipient == nil {
+		return nil, fmt.Errorf("recipient user ID %d not found", toUserID)
+	}
+
+	notification := &model.AdminTransactionNotification{
+		TransactionID:       transaction.ID,
+		TransactionType:     string(transaction.Type),
+		Amount:              transaction.Amount.String(),
+		ToUserID:            toUserID,
+		ToUsername:          recipient.Username,
+		PerformedByUserID:   *auth.UserID,
+		PerformedByUsername: auth.Username,
+		Description:         transaction.Description,
+		CreatedAt:           time.Now().Format(time.RFC3339),
+	}
+	if fromUserID != nil {
+		notification.FromUserID = fromUserID
+		notification.FromUsername = &auth.Username
+	}
+	return notification, nil
+}
+
+// idempotencyRequestHash fingerprints the payload of a money movement request.
+func idempotencyRequestHash(transactionType entity.TransactionType, toUserID uint, amount decimal.Decimal, description string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%d|%s|%s", transactionType, toUserID, amount.String(), description)))
+	return hex.EncodeToString(sum[:])
+}
 
+// GetTransactionsByUserID retrieves transactions for a user.
+func (uc *TransactionUseCase) GetTransactionsByUserID(ctx context.Context, userID uint, page, limit int) (*model.TransactionListResponse, error) {
 	// Get wallet for user
 	wallet, err := uc.WalletRepository.FindByUserID(uc.DB.WithContext(ctx), userID)
 	if err != nil {
@@ -401,7 +1138,33 @@ func (uc *TransactionUseCase) GetTransactionsByUserID(ctx context.Context, userI
 		return nil, fiber.NewError(fiber.StatusNotFound, "Wallet not found")
 	}
 
-	transactions, total, err := uc.TransactionRepository.FindByWalletID(uc.DB.WithContext(ctx), wallet.ID, page, limit)
+	return uc.listTransactions(ctx, wallet.ID, page, limit)
+}
+
+// GetTransactionsByWalletID retrieves the transactions of any wallet, including the treasury, for staff.
+func (uc *TransactionUseCase) GetTransactionsByWalletID(ctx context.Context, walletID uint, page, limit int) (*model.TransactionListResponse, error) {
+	count, err := uc.WalletRepository.CountById(uc.DB.WithContext(ctx), walletID)
+	if err != nil {
+		uc.Log.Errorf("CountById error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	if count == 0 {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Wallet not found")
+	}
+
+	return uc.listTransactions(ctx, walletID, page, limit)
+}
+
+// listTransactions returns a page of the transactions of a wallet.
+func (uc *TransactionUseCase) listTransactions(ctx context.Context, walletID uint, page, limit int) (*model.TransactionListResponse, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+
+	transactions, total, err := uc.TransactionRepository.FindByWalletID(uc.DB.WithContext(ctx), walletID, page, limit)
 	if err != nil {
 		uc.Log.Errorf("FindByWalletID error: %v", err)
 		return nil, fiber.ErrInternalServerError
@@ -0,0 +1,479 @@
+package usecase
+
+import (
+	"backend/internal/entity"
+	"backend/internal/model"
+	"backend/internal/model/converter"
+	"backend/internal/repository"
+	"backend/internal/util"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// recoveryCodeCount is how many recovery codes are issued at once.
+const recoveryCodeCount = 10
+
+// TwoFactorUseCase manages TOTP two-factor authentication: enrollment, recovery codes
+// and the second step of a login.
+// RequiredRoles lists the roles that may not turn two-factor authentication off once enabled.
+// Wrong codes of a signed in user are counted per user ID by CodeLimiter and wrong passwords by PasswordLimiter,
+// the same limiter that guards changing the password. Login codes are limited by the challenge instead.
+type TwoFactorUseCase struct {
+	DB                         *gorm.DB
+	Log                        *logrus.Logger
+	Validate                   *validator.Validate
+	UserRepository             *repository.UserRepository
+	UserRecoveryCodeRepository *repository.UserRecoveryCodeRepository
+	TokenUtil                  *util.TokenUtil
+	TOTPUtil                   *util.TOTPUtil
+	RequiredRoles              []string
+	PasswordLimiter            *util.AttemptLimiter
+	CodeLimiter                *util.AttemptLimiter
+	Connections                ConnectionCloserInterface
+}
+
+func NewTwoFactorUseCase(db *gorm.DB, log *logrus.Logger, validate *validator.Validate, userRepo *repository.UserRepository, recoveryCodeRepo *repository.UserRecoveryCodeRepository, tokenUtil *util.TokenUtil, totpUtil *util.TOTPUtil, requiredRoles []string, passwordLimiter *util.AttemptLimiter, codeLimiter *util.AttemptLimiter, connections ConnectionCloserInterface) *TwoFactorUseCase {
+	return &TwoFactorUseCase{
+		DB:                         db,
+		Log:                        log,
+		Validate:                   validate,
+		UserRepository:             userRepo,
+		UserRecoveryCodeRepository: recoveryCodeRepo,
+		TokenUtil:                  tokenUtil,
+		TOTPUtil:                   totpUtil,
+		RequiredRoles:              requiredRoles,
+		PasswordLimiter:            passwordLimiter,
+		CodeLimiter:                codeLimiter,
+		Connections:                connections,
+	}
+}
+
+// Status returns whether the authenticated user has two-factor authentication enabled.
+func (uc *TwoFactorUseCase) Status(ctx context.Context, auth *model.Auth) (*model.TwoFactorStatusResponse, error) {
+	db := uc.DB.WithContext(ctx)
+	user, err := uc.findUser(db, *auth.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &model.TwoFactorStatusResponse{
+		Enabled:   user.TwoFactorEnabled(),
+		EnabledAt: user.TOTPEnabledAt,
+		Required:  slices.Contains(uc.RequiredRoles, user.Role),
+	}
+	if user.TwoFactorEnabled() {
+		response.RecoveryCodesRemaining, err = uc.UserRecoveryCodeRepository.CountUnused(db, user.ID)
+		if err != nil {
+			uc.Log.Errorf("CountUnused error: %v", err)
+			return nil, fiber.ErrInternalServerError
+		}
+	}
+	return response, nil
+}
+
+// Setup generates a new TOTP secret for the authenticated user. It only takes effect after Confirm,
+// calling Setup again replaces a secret that was not confirmed yet.
+func (uc *TwoFactorUseCase) Setup(ctx context.Context, auth *model.Auth) (*model.TwoFactorSetupResponse, error) {
+	db := uc.DB.WithContext(ctx)
+	user, err := uc.findUser(db, *auth.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactorEnabled() {
+		return nil, fiber.NewError(fiber.StatusConflict, "Two-factor authentication is already enabled")
+	}
+
+	secret, err := uc.TOTPUtil.GenerateSecret()
+	if err != nil {
+		uc.Log.Errorf("GenerateSecret error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	encrypted, err := uc.TOTPUtil.EncryptSecret(secret)
+	if err != nil {
+		uc.Log.Errorf("EncryptSecret error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := uc.UserRepository.UpdateTOTPSecret(db, user.ID, encrypted); err != nil {
+		uc.Log.Errorf("UpdateTOTPSecret error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return &model.TwoFactorSetupResponse{
+		Secret:          secret,
+		ProvisioningURI: uc.TOTPUtil.ProvisioningURI(user.Username, secret),
+	}, nil
+}
+
+// Confirm enables two-factor authentication with a code for the secret from Setup and returns the recovery codes.
+// Every other session of the user is revoked, they were not started with a second factor.
+func (uc *TwoFactorUseCase) Confirm(ctx context.Context, auth *model.Auth, request *model.TwoFactorCodeRequest) (*model.RecoveryCodesResponse, error) {
+	// Validate request
+	if err := uc.Validate.Struct(request); err != nil {
+		uc.Log.Warnf("Validation error: %v", err)
+		return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	tx := uc.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	user, err := uc.findUser(tx, *auth.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactorEnabled() {
+		return nil, fiber.NewError(fiber.StatusConflict, "Two-factor authentication is already enabled")
+	}
+	if user.TOTPSecret == "" {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Two-factor authentication setup was not started")
+	}
+
+	var step int64
+	ok, err := uc.checkLimited(ctx, uc.CodeLimiter, user.ID, ErrorCodeTwoFactorLocked, "two-factor codes", func() (bool, error) {
+		var valid bool
+		var err error
+		step, valid, err = uc.validateTOTP(user, request.Code)
+		return valid, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		uc.Log.Warnf("Invalid two-factor confirmation code for user ID: %d", user.ID)
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid two-factor code")
+	}
+
+	if err := uc.UserRepository.EnableTOTP(tx, user.ID, time.Now(), step); err != nil {
+		uc.Log.Errorf("EnableTOTP error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	response, err := uc.replaceRecoveryCodes(tx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		uc.Log.Errorf("Transaction commit error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	revoked, err := uc.TokenUtil.RevokeOtherSessions(ctx, user.ID, auth.FamilyID)
+	if err != nil {
+		uc.Log.Errorf("RevokeOtherSessions error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	if err := closeConnections(uc.Log, uc.Connections, &model.DisconnectRequest{UserID: user.ID, KeepSessionID: auth.FamilyID}); err != nil {
+		return nil, err
+	}
+
+	uc.Log.Infof("Two-factor authentication enabled by user ID %d, %d other sessions revoked", user.ID, revoked)
+	return response, nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the authenticated user, the old ones stop working.
+func (uc *TwoFactorUseCase) RegenerateRecoveryCodes(ctx context.Context, auth *model.Auth, request *model.TwoFactorCodeRequest) (*model.RecoveryCodesResponse, error) {
+	// Validate request
+	if err := uc.Validate.Struct(request); err != nil {
+		uc.Log.Warnf("Validation error: %v", err)
+		return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	tx := uc.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	user, err := uc.findUser(tx, *auth.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TwoFactorEnabled() {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Two-factor authentication is not enabled")
+	}
+
+	ok, err := uc.checkLimited(ctx, uc.CodeLimiter, user.ID, ErrorCodeTwoFactorLocked, "two-factor codes", func() (bool, error) {
+		return uc.useTOTP(tx, user, request.Code)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		uc.Log.Warnf("Invalid two-factor code for recovery codes of user ID: %d", user.ID)
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid two-factor code")
+	}
+
+	response, err := uc.replaceRecoveryCodes(tx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		uc.Log.Errorf("Transaction commit error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	uc.Log.Infof("Recovery codes regenerated by user ID: %d", user.ID)
+	return response, nil
+}
+
+// Disable turns two-factor authentication off after checking the password and a code.
+// Users of a role in RequiredRoles cannot turn it off.
+func (uc *TwoFactorUseCase) Disable(ctx context.Context, auth *model.Auth, request *model.TwoFactorDisableRequest) error {
+	// Validate request
+	if err := uc.Validate.Struct(request); err != nil {
+		uc.Log.Warnf("Validation error: %v", err)
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	tx := uc.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	user, err := uc.findUser(tx, *auth.UserID)
+	if err != nil {
+		return err
+	}
+	if !user.TwoFactorEnabled() {
+		return fiber.NewError(fiber.StatusBadRequest, "Two-factor authentication is not enabled")
+	}
+	if slices.Contains(uc.RequiredRoles, user.Role) {
+		uc.Log.Warnf("User ID %d with role %s attempted to disable two-factor authentication", user.ID, user.Role)
+		return fiber.NewError(fiber.StatusForbidden, "Two-factor authentication is required for your role")
+	}
+
+	ok, err := uc.checkLimited(ctx, uc.PasswordLimiter, user.ID, ErrorCodePasswordLocked, "passwords", func() (bool, error) {
+		return bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(request.Password)) == nil, nil
+	})
+	if err != nil {
+		return err
+	}
+	if !ok {
+		uc.Log.Warnf("Password mismatch disabling two-factor authentication for user ID: %d", user.ID)
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid password or two-factor code")
+	}
+
+	ok, err = uc.checkLimited(ctx, uc.CodeLimiter, user.ID, ErrorCodeTwoFactorLocked, "two-factor codes", func() (bool, error) {
+		return uc.useCode(tx, user, request.Code)
+	})
+	if err != nil {
+		return err
+	}
+	if !ok {
+		uc.Log.Warnf("Invalid two-factor code disabling two-factor authentication for user ID: %d", user.ID)
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid password or two-factor code")
+	}
+
+	if err := uc.UserRepository.DisableTOTP(tx, user.ID); err != nil {
+		uc.Log.Errorf("DisableTOTP error: %v", err)
+		return fiber.ErrInternalServerError
+	}
+	if err := uc.UserRecoveryCodeRepository.DeleteByUserID(tx, user.ID); err != nil {
+		uc.Log.Errorf("DeleteByUserID error: %v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		uc.Log.Errorf("Transaction commit error: %v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	uc.Log.Infof("Two-factor authentication disabled by user ID: %d", user.ID)
+	return nil
+}
+
+// Login completes a login challenge with a code from the authenticator app or a recovery code
+// and issues the tokens the password step held back.
+func (uc *TwoFactorUseCase) Login(ctx context.Context, request *model.TwoFactorLoginRequest) (*model.UserResponse, error) {
+	// Validate request
+	if err := uc.Validate.Struct(request); err != nil {
+		uc.Log.Warnf("Validation error: %v", err)
+		return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	userID, session, err := uc.TokenUtil.AttemptTwoFactorChallenge(ctx, request.ChallengeToken)
+	if errors.Is(err, util.ErrTwoFactorChallengeInvalid) {
+		uc.Log.Warnf("Invalid two-factor challenge")
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired login challenge")
+	}
+	if err != nil {
+		uc.Log.Errorf("AttemptTwoFactorChallenge error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	tx := uc.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	user, err := uc.UserRepository.FindByID(tx, userID)
+	if err != nil {
+		uc.Log.Errorf("FindByID error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	if user == nil || !user.TwoFactorEnabled() {
+		uc.Log.Warnf("Two-factor challenge for user ID %d that no longer uses two-factor authentication", userID)
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired login challenge")
+	}
+	if user.Disabled() {
+		uc.Log.Warnf("Two-factor login of disabled user ID: %d", user.ID)
+		return nil, errAccountDisabled()
+	}
+
+	ok, err := uc.useCode(tx, user, request.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		uc.Log.Warnf("Invalid two-factor code at login for user ID: %d", user.ID)
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid two-factor code")
+	}
+
+	// Only one request completes a challenge, a concurrent one with another valid code loses
+	completed, err := uc.TokenUtil.CompleteTwoFactorChallenge(ctx, request.ChallengeToken)
+	if err != nil {
+		uc.Log.Errorf("CompleteTwoFactorChallenge error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	if !completed {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired login challenge")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		uc.Log.Errorf("Transaction commit error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if request.IPAddress != "" {
+		session.IPAddress = request.IPAddress
+	}
+	tokens, err := uc.TokenUtil.CreateTokenPair(ctx, &model.Auth{
+		UserID:    &user.ID,
+		Username:  user.Username,
+		Role:      user.Role,
+		TwoFactor: true,
+	}, session)
+	if err != nil {
+		uc.Log.Warnf("Token creation error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.UserToUserResponse(user, tokens), nil
+}
+
+// findUser loads a user that is expected to exist.
+func (uc *TwoFactorUseCase) findUser(db *gorm.DB, userID uint) (*entity.User, error) {
+	user, err := uc.UserRepository.FindByID(db, userID)
+	if err != nil {
+		uc.Log.Errorf("FindByID error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	if user == nil {
+		uc.Log.Warnf("User not found: %d", userID)
+		return nil, fiber.NewError(fiber.StatusNotFound, "User not found")
+	}
+	return user, nil
+}
+
+// checkLimited counts an attempt of a user on limiter before running check, so concurrent requests cannot try
+// more than limiter.MaxAttempts secrets. A wrong secret reports false, the last one allowed returns the lockout
+// error with errorCode instead. A correct secret clears the count.
+func (uc *TwoFactorUseCase) checkLimited(ctx context.Context, limiter *util.AttemptLimiter, userID uint, errorCode string, secrets string, check func() (bool, error)) (bool, error) {
+	key := userLimiterKey(userID)
+	attempt, err := limiter.Begin(ctx, key)
+	if err != nil {
+		uc.Log.Errorf("AttemptLimiter.Begin error: %v", err)
+		return false, fiber.ErrInternalServerError
+	}
+	if !attempt.Allowed() {
+		uc.Log.Warnf("Locked %s check used by user ID: %d", secrets, userID)
+		return false, NewCodedError(fiber.StatusTooManyRequests, errorCode, fmt.Sprintf("Too many wrong %s, try again in %s", secrets, attempt.Wait.Round(time.Second)), attempt.Wait)
+	}
+
+	ok, err := check()
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		if attempt.Left == 0 {
+			uc.Log.Warnf("Too many wrong %s for user ID %d, locked for %s", secrets, userID, limiter.Lockout)
+			return false, NewCodedError(fiber.StatusTooManyRequests, errorCode, fmt.Sprintf("Too many wrong %s, try again in %s", secrets, limiter.Lockout), limiter.Lockout)
+		}
+		return false, nil
+	}
+
+	if err := limiter.Reset(ctx, key); err != nil {
+		uc.Log.Errorf("AttemptLimiter.Reset error: %v", err)
+		return false, fiber.ErrInternalServerError
+	}
+	return true, nil
+}
+
+// validateTOTP checks a code against the stored secret of a user without redeeming it.
+func (uc *TwoFactorUseCase) validateTOTP(user *entity.User, code string) (int64, bool, error) {
+	secret, err := uc.TOTPUtil.DecryptSecret(user.TOTPSecret)
+	if err != nil {
+		uc.Log.Errorf("DecryptSecret error for user ID %d: %v", user.ID, err)
+		return 0, false, fiber.ErrInternalServerError
+	}
+
+	step, ok := uc.TOTPUtil.Validate(secret, code, time.Now())
+	return step, ok, nil
+}
+
+// useTOTP redeems a code from the authenticator app, a code that was used before is rejected.
+func (uc *TwoFactorUseCase) useTOTP(db *gorm.DB, user *entity.User, code string) (bool, error) {
+	step, ok, err := uc.validateTOTP(user, code)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	used, err := uc.UserRepository.UseTOTPStep(db, user.ID, step)
+	if err != nil {
+		uc.Log.Errorf("UseTOTPStep error: %v", err)
+		return false, fiber.ErrInternalServerError
+	}
+	return used, nil
+}
+
+// useCode redeems a code from the authenticator app or, failing that, a recovery code.
+func (uc *TwoFactorUseCase) useCode(db *gorm.DB, user *entity.User, code string) (bool, error) {
+	ok, err := uc.useTOTP(db, user, code)
+	if err != nil || ok {
+		return ok, err
+	}
+
+	used, err := uc.UserRecoveryCodeRepository.Use(db, user.ID, uc.TOTPUtil.HashRecoveryCode(code), time.Now())
+	if err != nil {
+		uc.Log.Errorf("Use recovery code error: %v", err)
+		return false, fiber.ErrInternalServerError
+	}
+	if used {
+		uc.Log.Infof("Recovery code used by user ID: %d", user.ID)
+	}
+	return used, nil
+}
+
+// replaceRecoveryCodes generates new recovery codes for a user and stores their hashes.
+func (uc *TwoFactorUseCase) replaceRecoveryCodes(db *gorm.DB, userID uint) (*model.RecoveryCodesResponse, error) {
+	codes, err := uc.TOTPUtil.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		uc.Log.Errorf("GenerateRecoveryCodes error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = uc.TOTPUtil.HashRecoveryCode(code)
+	}
+	if err := uc.UserRecoveryCodeRepository.ReplaceByUserID(db, userID, hashes); err != nil {
+		uc.Log.Errorf("ReplaceByUserID error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return &model.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
@@ -11,8 +11,56 @@ import (
 // UserUseCaseInterface defines the interface for user-related use cases.
 type UserUseCaseInterface interface {
 	Create(ctx context.Context, request *model.UserRegistrationRequest) (*model.UserResponse, error)
-	Login(ctx context.Context, request *model.UserLoginRequest) (*model.UserResponse, error)
+	Login(ctx context.Context, request *model.UserLoginRequest) (*model.LoginResponse, error)
+	Refresh(ctx context.Context, request *model.RefreshTokenRequest) (*model.UserResponse, error)
+	Logout(ctx context.Context, auth *model.Auth, token string) error
+	ChangePassword(ctx context.Context, auth *model.Auth, request *model.ChangePasswordRequest) error
+	IssuePasswordReset(ctx context.Context, auth *model.Auth, userID uint) (*model.PasswordResetTokenResponse, error)
+	ResetPassword(ctx context.Context, request *model.ResetPasswordRequest) error
+	Unlock(ctx context.Context, auth *model.Auth, userID uint) error
 	GetProfile(ctx context.Context, userID uint) (*model.UserProfileResponse, error)
+	CheckActive(ctx context.Context, userID uint) error
+	CheckPermission(ctx context.Context, userID uint, permission entity.Permission) error
+}
+
+// AdminUserUseCaseInterface defines the interface for admin user management use cases.
+type AdminUserUseCaseInterface interface {
+	Search(ctx context.Context, request *model.AdminUserSearchRequest) (*model.AdminUserListResponse, error)
+	Get(ctx context.Context, userID uint) (*model.AdminUserDetailResponse, error)
+	ChangeRole(ctx context.Context, auth *model.Auth, userID uint, request *model.ChangeRoleRequest) (*model.AdminUserResponse, error)
+	Disable(ctx context.Context, auth *model.Auth, userID uint) (*model.AdminUserResponse, error)
+	Enable(ctx context.Context, auth *model.Auth, userID uint) (*model.AdminUserResponse, error)
+	ForceLogout(ctx context.Context, auth *model.Auth, userID uint) (*model.RevokeSessionsResponse, error)
+}
+
+// AuditLogUseCaseInterface defines the interface for recording audit log entries.
+type AuditLogUseCaseInterface interface {
+	Record(ctx context.Context, request *model.AuditLogRequest) error
+}
+
+// SessionUseCaseInterface defines the interface for session management use cases.
+type SessionUseCaseInterface interface {
+	List(ctx context.Context, auth *model.Auth) ([]model.SessionResponse, error)
+	Revoke(ctx context.Context, auth *model.Auth, sessionID string) error
+	RevokeOthers(ctx context.Context, auth *model.Auth) (*model.RevokeSessionsResponse, error)
+}
+
+// TwoFactorUseCaseInterface defines the interface for two-factor authentication use cases.
+type TwoFactorUseCaseInterface interface {
+	Status(ctx context.Context, auth *model.Auth) (*model.TwoFactorStatusResponse, error)
+	Setup(ctx context.Context, auth *model.Auth) (*model.TwoFactorSetupResponse, error)
+	Confirm(ctx context.Context, auth *model.Auth, request *model.TwoFactorCodeRequest) (*model.RecoveryCodesResponse, error)
+	RegenerateRecoveryCodes(ctx context.Context, auth *model.Auth, request *model.TwoFactorCodeRequest) (*model.RecoveryCodesResponse, error)
+	Disable(ctx context.Context, auth *model.Auth, request *model.TwoFactorDisableRequest) error
+	Login(ctx context.Context, request *model.TwoFactorLoginRequest) (*model.UserResponse, error)
+}
+
+// TransactionPINUseCaseInterface defines the interface for transaction PIN use cases.
+type TransactionPINUseCaseInterface interface {
+	Status(ctx context.Context, auth *model.Auth) (*model.TransactionPINStatusResponse, error)
+	Set(ctx context.Context, auth *model.Auth, request *model.TransactionPINRequest) error
+	Change(ctx context.Context, auth *model.Auth, request *model.ChangeTransactionPINRequest) error
+	Reset(ctx context.Context, auth *model.Auth, request *model.TransactionPINRequest) error
 }
 
 // WalletUseCaseInterface defines the interface for wallet-related use cases.
@@ -25,10 +73,43 @@ type WalletUseCaseInterface interface {
 type TransactionUseCaseInterface interface {
 	TopUp(ctx context.Context, auth *model.Auth, request *model.TopUpRequest) (*model.TransactionResponse, error)
 	Transfer(ctx context.Context, auth *model.Auth, request *model.TransferRequest) (*model.TransactionResponse, error)
+	Withdraw(ctx context.Context, auth *model.Auth, request *model.WithdrawRequest) (*model.TransactionResponse, error)
+	SettleWithdraw(ctx context.Context, auth *model.Auth, request *model.SettleWithdrawRequest) (*model.TransactionResponse, error)
+	Reverse(ctx context.Context, auth *model.Auth, request *model.ReverseTransactionRequest) (*model.TransactionResponse, error)
 	GetTransactionsByUserID(ctx context.Context, userID uint, page, limit int) (*model.TransactionListResponse, error)
+	GetTransactionsByWalletID(ctx context.Context, walletID uint, page, limit int) (*model.TransactionListResponse, error)
 }
 
 // WalletMutationUseCaseInterface defines the interface for wallet mutation-related use cases.
 type WalletMutationUseCaseInterface interface {
 	GetMutationsByUserID(ctx context.Context, userID uint, page, limit int) (*model.WalletMutationListResponse, error)
+	GetMutationsByWalletID(ctx context.Context, walletID uint, page, limit int) (*model.WalletMutationListResponse, error)
+}
+
+// WebhookUseCaseInterface defines the interface for webhook endpoint use cases.
+type WebhookUseCaseInterface interface {
+	Create(ctx context.Context, auth *model.Auth, request *model.CreateWebhookRequest) (*model.WebhookResponse, error)
+	List(ctx context.Context, auth *model.Auth) ([]model.WebhookResponse, error)
+	Delete(ctx context.Context, auth *model.Auth, webhookID uint) error
+	GetDeliveries(ctx context.Context, auth *model.Auth, webhookID uint, page, limit int) (*model.WebhookDeliveryListResponse, error)
+	Redeliver(ctx context.Context, auth *model.Auth, webhookID uint, deliveryID uint) (*model.WebhookDeliveryResponse, error)
+}
+
+// NotificationUseCaseInterface defines the interface for notification inbox use cases.
+type NotificationUseCaseInterface interface {
+	List(ctx context.Context, auth *model.Auth, unreadOnly bool, page, limit int) (*model.NotificationListResponse, error)
+	CountUnread(ctx context.Context, auth *model.Auth) (*model.UnreadNotificationCountResponse, error)
+	Mark(ctx context.Context, auth *model.Auth, notificationID uint, read bool) (*model.NotificationResponse, error)
+	MarkBulk(ctx context.Context, auth *model.Auth, request *model.MarkNotificationsRequest, read bool) (*model.MarkNotificationsResponse, error)
+}
+
+// ReconciliationUseCaseInterface defines the interface for ledger reconciliation use cases.
+type ReconciliationUseCaseInterface interface {
+	GetReport(ctx context.Context, auth *model.Auth) (*model.ReconciliationReport, error)
+}
+
+// ConnectionCloserInterface closes open real-time connections (WebSocket and SSE) of a user,
+// so revoked sessions stop receiving events. It is implemented by the WebSocket Hub.
+type ConnectionCloserInterface interface {
+	Disconnect(request *model.DisconnectRequest) error
 }
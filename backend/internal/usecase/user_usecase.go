@@ -7,6 +7,10 @@ import (
 	"backend/internal/repository"
 	"backend/internal/util"
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
@@ -15,6 +19,10 @@ import (
 	"gorm.io/gorm"
 )
 
+// UserUseCase handles accounts, logins and passwords.
+// Failed logins are counted per username by LoginUserLimiter and per client IP by LoginIPLimiter,
+// which throttle and then lock out further attempts. Wrong current passwords of a signed in user
+// are counted per user ID by PasswordLimiter.
 type UserUseCase struct {
 	DB               *gorm.DB
 	Log              *logrus.Logger
@@ -22,9 +30,13 @@ type UserUseCase struct {
 	UserRepository   *repository.UserRepository
 	WalletRepository *repository.WalletRepository
 	TokenUtil        *util.TokenUtil
+	LoginUserLimiter *util.AttemptLimiter
+	LoginIPLimiter   *util.AttemptLimiter
+	PasswordLimiter  *util.AttemptLimiter
+	Connections      ConnectionCloserInterface
 }
 
-func NewUserUseCase(db *gorm.DB, log *logrus.Logger, validate *validator.Validate, userRepo *repository.UserRepository, walletRepo *repository.WalletRepository, tokenUtil *util.TokenUtil) *UserUseCase {
+func NewUserUseCase(db *gorm.DB, log *logrus.Logger, validate *validator.Validate, userRepo *repository.UserRepository, walletRepo *repository.WalletRepository, tokenUtil *util.TokenUtil, loginUserLimiter *util.AttemptLimiter, loginIPLimiter *util.AttemptLimiter, passwordLimiter *util.AttemptLimiter, connections ConnectionCloserInterface) *UserUseCase {
 	return &UserUseCase{
 		DB:               db,
 		Log:              log,
@@ -32,6 +44,10 @@ func NewUserUseCase(db *gorm.DB, log *logrus.Logger, validate *validator.Validat
 		UserRepository:   userRepo,
 		WalletRepository: walletRepo,
 		TokenUtil:        tokenUtil,
+		LoginUserLimiter: loginUserLimiter,
+		LoginIPLimiter:   loginIPLimiter,
+		PasswordLimiter:  passwordLimiter,
+		Connections:      connections,
 	}
 }
 
@@ -92,22 +108,24 @@ func (uc *UserUseCase) Create(ctx context.Context, request *model.UserRegistrati
 		return nil, fiber.ErrInternalServerError
 	}
 
-	// Create token
-	token, err := uc.TokenUtil.CreateToken(ctx, &model.Auth{
+	// Create token pair
+	tokens, err := uc.TokenUtil.CreateTokenPair(ctx, &model.Auth{
 		UserID:   &user.ID,
 		Username: request.Username,
 		Role:     user.Role,
-	})
+	}, newSessionInfo(request.DeviceName, request.UserAgent, request.IPAddress))
 	if err != nil {
 		uc.Log.Warnf("Token creation error: %v", err)
 		return nil, fiber.ErrInternalServerError
 	}
 
-	return converter.UserToUserResponse(user, token), err
+	return converter.UserToUserResponse(user, tokens), err
 }
 
 // Login handles the business logic for user login.
-func (uc *UserUseCase) Login(ctx context.Context, request *model.UserLoginRequest) (*model.UserResponse, error) {
+// Users with two-factor authentication enabled get a challenge instead of tokens,
+// the login is completed with a code through TwoFactorUseCase.Login.
+func (uc *UserUseCase) Login(ctx context.Context, request *model.UserLoginRequest) (*model.LoginResponse, error) {
 	// Validate request
 	err := uc.Validate.Struct(request)
 	if err != nil {
@@ -115,6 +133,13 @@ func (uc *UserUseCase) Login(ctx context.Context, request *model.UserLoginReques
 		return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
 
+	// Refuse attempts from locked out or throttled usernames and IPs, and count the others before checking the password
+	userKey := loginUserLimiterKey(request.Username)
+	ipAttempt, userAttempt, err := uc.beginLoginAttempt(ctx, userKey, request.IPAddress)
+	if err != nil {
+		return nil, err
+	}
+
 	// Find user by username
 	user, err := uc.UserRepository.FindByUsername(uc.DB.WithContext(ctx), request.Username)
 	if err != nil {
@@ -124,28 +149,423 @@ func (uc *UserUseCase) Login(ctx context.Context, request *model.UserLoginReques
 
 	if user == nil {
 		uc.Log.Warnf("User not found: %s", request.Username)
-		return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid username or password")
+		return nil, uc.loginFailure(request.Username, request.IPAddress, ipAttempt, userAttempt)
+	}
+
+	// System accounts fail like an unknown username, whatever their stored password
+	if user.System {
+		uc.Log.Warnf("Login of system user: %s", request.Username)
+		return nil, uc.loginFailure(request.Username, request.IPAddress, ipAttempt, userAttempt)
 	}
 
 	// Verify password
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(request.Password))
 	if err != nil {
 		uc.Log.Warnf("Password mismatch for user: %s", request.Username)
-		return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid username or password")
+		return nil, uc.loginFailure(request.Username, request.IPAddress, ipAttempt, userAttempt)
+	}
+
+	if err := uc.endLoginAttempt(ctx, userKey, request.IPAddress, ipAttempt); err != nil {
+		return nil, err
+	}
+
+	// Only tell a disabled account apart once the password proved who is asking
+	if user.Disabled() {
+		uc.Log.Warnf("Login of disabled user: %s", request.Username)
+		return nil, errAccountDisabled()
+	}
+
+	session := newSessionInfo(request.DeviceName, request.UserAgent, request.IPAddress)
+
+	// Hold back the tokens until the second factor is provided
+	if user.TwoFactorEnabled() {
+		challengeToken, expiresAt, err := uc.TokenUtil.CreateTwoFactorChallenge(ctx, user.ID, session)
+		if err != nil {
+			uc.Log.Errorf("CreateTwoFactorChallenge error: %v", err)
+			return nil, fiber.ErrInternalServerError
+		}
+
+		return &model.LoginResponse{
+			TwoFactorRequired:  true,
+			ChallengeToken:     challengeToken,
+			ChallengeExpiresAt: &expiresAt,
+		}, nil
 	}
 
-	// Create token
-	token, err := uc.TokenUtil.CreateToken(ctx, &model.Auth{
+	// Create token pair
+	tokens, err := uc.TokenUtil.CreateTokenPair(ctx, &model.Auth{
 		UserID:   &user.ID,
 		Username: user.Username,
 		Role:     user.Role,
-	})
+	}, session)
 	if err != nil {
 		uc.Log.Warnf("Token creation error: %v", err)
 		return nil, fiber.ErrInternalServerError
 	}
 
-	return converter.UserToUserResponse(user, token), nil
+	return &model.LoginResponse{
+		UserResponse: converter.UserToUserResponse(user, tokens),
+	}, nil
+}
+
+// beginLoginAttempt counts a login attempt against the IP and username before the password is checked,
+// so concurrent logins cannot try more passwords than the limits allow.
+// It returns a coded 429 error when the IP or username is locked out or has to wait, the IP attempt is then taken back.
+// Without an IP address only the username is limited and the returned IP attempt is nil.
+func (uc *UserUseCase) beginLoginAttempt(ctx context.Context, userKey string, ipAddress string) (*util.Attempt, *util.Attempt, error) {
+	var ipAttempt *util.Attempt
+	if ipAddress != "" {
+		attempt, err := uc.LoginIPLimiter.Begin(ctx, ipAddress)
+		if err != nil {
+			uc.Log.Errorf("LoginIPLimiter.Begin error: %v", err)
+			return nil, nil, fiber.ErrInternalServerError
+		}
+		if !attempt.Allowed() {
+			uc.Log.Warnf("Login attempt from locked out IP: %s", ipAddress)
+			return nil, nil, NewCodedError(fiber.StatusTooManyRequests, ErrorCodeIPLocked, fmt.Sprintf("Too many failed logins from this address, try again in %s", attempt.Wait.Round(time.Second)), attempt.Wait)
+		}
+		ipAttempt = attempt
+	}
+
+	userAttempt, err := uc.LoginUserLimiter.Begin(ctx, userKey)
+	if err != nil {
+		uc.Log.Errorf("LoginUserLimiter.Begin error: %v", err)
+		return nil, nil, fiber.ErrInternalServerError
+	}
+	if userAttempt.Allowed() {
+		return ipAttempt, userAttempt, nil
+	}
+
+	// No password was checked, so the attempt does not count against the IP
+	if ipAttempt != nil {
+		if err := uc.LoginIPLimiter.Release(ctx, ipAddress, ipAttempt); err != nil {
+			uc.Log.Errorf("LoginIPLimiter.Release error: %v", err)
+			return nil, nil, fiber.ErrInternalServerError
+		}
+	}
+	if userAttempt.Locked {
+		uc.Log.Warnf("Login attempt for locked account %s from IP: %s", userKey, ipAddress)
+		return nil, nil, NewCodedError(fiber.StatusTooManyRequests, ErrorCodeAccountLocked, fmt.Sprintf("Account is locked after too many failed logins, try again in %s", userAttempt.Wait.Round(time.Second)), userAttempt.Wait)
+	}
+	return nil, nil, NewCodedError(fiber.StatusTooManyRequests, ErrorCodeLoginThrottled, fmt.Sprintf("Too many failed logins, try again in %s", userAttempt.Wait.Round(time.Second)), userAttempt.Wait)
+}
+
+// loginFailure returns the error to respond with to a failed login, the attempts were already counted.
+// Unknown usernames are counted too, so the response does not reveal whether an account exists.
+func (uc *UserUseCase) loginFailure(username string, ipAddress string, ipAttempt *util.Attempt, userAttempt *util.Attempt) error {
+	if ipAttempt != nil && ipAttempt.Left == 0 {
+		uc.Log.Warnf("IP %s locked out for %s after too many failed logins, last for username: %s", ipAddress, uc.LoginIPLimiter.Lockout, username)
+		return NewCodedError(fiber.StatusTooManyRequests, ErrorCodeIPLocked, fmt.Sprintf("Too many failed logins from this address, try again in %s", uc.LoginIPLimiter.Lockout), uc.LoginIPLimiter.Lockout)
+	}
+	if userAttempt.Left == 0 {
+		uc.Log.Warnf("Account %s locked out for %s after too many failed logins, last from IP: %s", username, uc.LoginUserLimiter.Lockout, ipAddress)
+		return NewCodedError(fiber.StatusTooManyRequests, ErrorCodeAccountLocked, fmt.Sprintf("Account is locked after too many failed logins, try again in %s", uc.LoginUserLimiter.Lockout), uc.LoginUserLimiter.Lockout)
+	}
+	return fiber.NewError(fiber.StatusUnauthorized, "Invalid username or password")
+}
+
+// endLoginAttempt clears the failures of a username once its password matched.
+// The IP only gets the attempt back and keeps its count, so one known password does not clear
+// the failures of others tried from it.
+func (uc *UserUseCase) endLoginAttempt(ctx context.Context, userKey string, ipAddress string, ipAttempt *util.Attempt) error {
+	if err := uc.LoginUserLimiter.Reset(ctx, userKey); err != nil {
+		uc.Log.Errorf("LoginUserLimiter.Reset error: %v", err)
+		return fiber.ErrInternalServerError
+	}
+	if ipAttempt != nil {
+		if err := uc.LoginIPLimiter.Release(ctx, ipAddress, ipAttempt); err != nil {
+			uc.Log.Errorf("LoginIPLimiter.Release error: %v", err)
+			return fiber.ErrInternalServerError
+		}
+	}
+	return nil
+}
+
+// Unlock lifts the login and current password lockouts and the login delay of a user on behalf of an admin.
+// Only roles with entity.PermissionUsersManageAdmins may unlock accounts other than regular users.
+func (uc *UserUseCase) Unlock(ctx context.Context, auth *model.Auth, userID uint) error {
+	user, err := uc.UserRepository.FindByID(uc.DB.WithContext(ctx), userID)
+	if err != nil {
+		uc.Log.Errorf("FindByID error: %v", err)
+		return fiber.ErrInternalServerError
+	}
+	if user == nil {
+		return fiber.NewError(fiber.StatusNotFound, "User not found")
+	}
+
+	if user.Role != entity.RoleUser && !entity.RoleHasPermission(auth.Role, entity.PermissionUsersManageAdmins) {
+		uc.Log.Warnf("Admin ID %d attempted to unlock %s ID %d", *auth.UserID, user.Role, user.ID)
+		return fiber.NewError(fiber.StatusForbidden, "Only super admins can unlock an admin")
+	}
+
+	if err := uc.LoginUserLimiter.Reset(ctx, loginUserLimiterKey(user.Username)); err != nil {
+		uc.Log.Errorf("LoginUserLimiter.Reset error: %v", err)
+		return fiber.ErrInternalServerError
+	}
+	if err := uc.PasswordLimiter.Reset(ctx, userLimiterKey(user.ID)); err != nil {
+		uc.Log.Errorf("PasswordLimiter.Reset error: %v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	uc.Log.Infof("Login lockout of user ID %d lifted by user ID: %d", user.ID, *auth.UserID)
+	return nil
+}
+
+// Refresh rotates a refresh token into a new access and refresh token of the same family.
+// Presenting a refresh token that was already rotated revokes the whole family.
+func (uc *UserUseCase) Refresh(ctx context.Context, request *model.RefreshTokenRequest) (*model.UserResponse, error) {
+	// Validate request
+	err := uc.Validate.Struct(request)
+	if err != nil {
+		uc.Log.Warnf("Validation error: %v", err)
+		return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	userID, familyID, err := uc.TokenUtil.RotateRefreshToken(ctx, request.RefreshToken)
+	if errors.Is(err, util.ErrRefreshTokenReused) {
+		uc.Log.Warnf("Refresh token reuse detected for user ID %d, token family %s revoked", userID, familyID)
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid refresh token")
+	}
+	if errors.Is(err, util.ErrRefreshTokenInvalid) {
+		uc.Log.Warnf("Invalid refresh token")
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid refresh token")
+	}
+	if err != nil {
+		uc.Log.Errorf("RotateRefreshToken error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	// Load the user again so the new access token carries the current username and role
+	user, err := uc.UserRepository.FindByID(uc.DB.WithContext(ctx), userID)
+	if err != nil {
+		uc.Log.Errorf("FindByID error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	if user == nil {
+		uc.Log.Warnf("User not found: %d", userID)
+		if err := uc.TokenUtil.RevokeFamily(ctx, familyID); err != nil {
+			uc.Log.Errorf("RevokeFamily error: %v", err)
+		}
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid refresh token")
+	}
+	if user.Disabled() {
+		uc.Log.Warnf("Refresh by disabled user ID: %d", user.ID)
+		if err := uc.TokenUtil.RevokeFamily(ctx, familyID); err != nil {
+			uc.Log.Errorf("RevokeFamily error: %v", err)
+		}
+		return nil, errAccountDisabled()
+	}
+
+	// Sessions of a user with two-factor authentication were all started with a second factor,
+	// enabling it signs out every other session
+	tokens, err := uc.TokenUtil.CreateTokenPairInFamily(ctx, &model.Auth{
+		UserID:    &user.ID,
+		Username:  user.Username,
+		Role:      user.Role,
+		TwoFactor: user.TwoFactorEnabled(),
+	}, familyID, request.IPAddress)
+	if errors.Is(err, util.ErrRefreshTokenInvalid) {
+		uc.Log.Warnf("Token family %s was revoked during refresh", familyID)
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid refresh token")
+	}
+	if err != nil {
+		uc.Log.Warnf("Token creation error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.UserToUserResponse(user, tokens), nil
+}
+
+// Logout ends the session of the access token, its refresh token stops working as well.
+func (uc *UserUseCase) Logout(ctx context.Context, auth *model.Auth, token string) error {
+	if auth.FamilyID != "" {
+		if err := uc.TokenUtil.RevokeFamily(ctx, auth.FamilyID); err != nil {
+			uc.Log.Errorf("RevokeFamily error: %v", err)
+			return fiber.ErrInternalServerError
+		}
+		if err := closeConnections(uc.Log, uc.Connections, &model.DisconnectRequest{UserID: *auth.UserID, SessionID: auth.FamilyID}); err != nil {
+			return err
+		}
+	}
+
+	// Tokens issued before sessions existed have no family, drop the token itself
+	if err := uc.TokenUtil.RevokeToken(ctx, token); err != nil {
+		uc.Log.Errorf("RevokeToken error: %v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	uc.Log.Infof("User ID %d logged out", *auth.UserID)
+	return nil
+}
+
+// ChangePassword replaces the password of the authenticated user after checking the current one.
+// Every other session is revoked, the session making the request stays signed in.
+func (uc *UserUseCase) ChangePassword(ctx context.Context, auth *model.Auth, request *model.ChangePasswordRequest) error {
+	// Validate request
+	if err := uc.Validate.Struct(request); err != nil {
+		uc.Log.Warnf("Validation error: %v", err)
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	db := uc.DB.WithContext(ctx)
+	user, err := uc.UserRepository.FindByID(db, *auth.UserID)
+	if err != nil {
+		uc.Log.Errorf("FindByID error: %v", err)
+		return fiber.ErrInternalServerError
+	}
+	if user == nil {
+		uc.Log.Warnf("User not found: %d", *auth.UserID)
+		return fiber.NewError(fiber.StatusNotFound, "User not found")
+	}
+
+	if err := uc.checkPassword(ctx, user, request.CurrentPassword); err != nil {
+		return err
+	}
+
+	if err := uc.updatePassword(db, user.ID, request.NewPassword); err != nil {
+		return err
+	}
+
+	revoked, err := uc.TokenUtil.RevokeOtherSessions(ctx, user.ID, auth.FamilyID)
+	if err != nil {
+		uc.Log.Errorf("RevokeOtherSessions error: %v", err)
+		return fiber.ErrInternalServerError
+	}
+	if err := closeConnections(uc.Log, uc.Connections, &model.DisconnectRequest{UserID: user.ID, KeepSessionID: auth.FamilyID}); err != nil {
+		return err
+	}
+
+	uc.Log.Infof("Password changed by user ID %d, %d other sessions revoked", user.ID, revoked)
+	return nil
+}
+
+// IssuePasswordReset creates a single-use password reset token for a user on behalf of an admin.
+// Only roles with entity.PermissionUsersManageAdmins may reset the password of accounts other than regular users.
+func (uc *UserUseCase) IssuePasswordReset(ctx context.Context, auth *model.Auth, userID uint) (*model.PasswordResetTokenResponse, error) {
+	user, err := uc.UserRepository.FindByID(uc.DB.WithContext(ctx), userID)
+	if err != nil {
+		uc.Log.Errorf("FindByID error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	if user == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "User not found")
+	}
+	if user.System {
+		uc.Log.Warnf("Admin ID %d attempted to reset the password of system user ID %d", *auth.UserID, user.ID)
+		return nil, fiber.NewError(fiber.StatusForbidden, "System accounts cannot be managed")
+	}
+
+	if user.Role != entity.RoleUser && !entity.RoleHasPermission(auth.Role, entity.PermissionUsersManageAdmins) {
+		uc.Log.Warnf("Admin ID %d attempted to reset the password of %s ID %d", *auth.UserID, user.Role, user.ID)
+		return nil, fiber.NewError(fiber.StatusForbidden, "Only super admins can reset the password of an admin")
+	}
+
+	token, expiresAt, err := uc.TokenUtil.CreatePasswordResetToken(ctx, user.ID)
+	if err != nil {
+		uc.Log.Errorf("CreatePasswordResetToken error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	uc.Log.Infof("Password reset for user ID %d issued by user ID: %d", user.ID, *auth.UserID)
+
+	return &model.PasswordResetTokenResponse{
+		UserID:    user.ID,
+		Token:     token,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// ResetPassword redeems a password reset token and sets a new password.
+// Every session of the user is revoked, so the new password is needed to sign in again.
+func (uc *UserUseCase) ResetPassword(ctx context.Context, request *model.ResetPasswordRequest) error {
+	// Validate request
+	if err := uc.Validate.Struct(request); err != nil {
+		uc.Log.Warnf("Validation error: %v", err)
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	userID, err := uc.TokenUtil.ConsumePasswordResetToken(ctx, request.Token)
+	if errors.Is(err, util.ErrPasswordResetTokenInvalid) {
+		uc.Log.Warnf("Invalid password reset token")
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid or expired reset token")
+	}
+	if err != nil {
+		uc.Log.Errorf("ConsumePasswordResetToken error: %v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	if err := uc.updatePassword(uc.DB.WithContext(ctx), userID, request.NewPassword); err != nil {
+		return err
+	}
+
+	revoked, err := uc.TokenUtil.RevokeOtherSessions(ctx, userID, "")
+	if err != nil {
+		uc.Log.Errorf("RevokeOtherSessions error: %v", err)
+		return fiber.ErrInternalServerError
+	}
+	if err := closeConnections(uc.Log, uc.Connections, &model.DisconnectRequest{UserID: userID}); err != nil {
+		return err
+	}
+
+	uc.Log.Infof("Password reset redeemed for user ID %d, %d sessions revoked", userID, revoked)
+	return nil
+}
+
+// updatePassword hashes and stores a new password for a user.
+func (uc *UserUseCase) updatePassword(db *gorm.DB, userID uint, newPassword string) error {
+	password, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		uc.Log.Errorf("Password hashing error: %v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	if err := uc.UserRepository.UpdatePassword(db, userID, string(password)); err != nil {
+		uc.Log.Errorf("UpdatePassword error: %v", err)
+		return fiber.ErrInternalServerError
+	}
+	return nil
+}
+
+// CheckActive rejects requests of users that were deleted or disabled after their access token was issued.
+// The auth middleware runs it for every request.
+func (uc *UserUseCase) CheckActive(ctx context.Context, userID uint) error {
+	exists, disabled, err := uc.UserRepository.IsDisabled(uc.DB.WithContext(ctx), userID)
+	if err != nil {
+		uc.Log.Errorf("IsDisabled error: %v", err)
+		return fiber.ErrInternalServerError
+	}
+	if !exists {
+		uc.Log.Warnf("Request of deleted user ID: %d", userID)
+		return fiber.ErrUnauthorized
+	}
+	if disabled {
+		uc.Log.Warnf("Request of disabled user ID: %d", userID)
+		return errAccountDisabled()
+	}
+	return nil
+}
+
+// CheckPermission rejects users whose current role no longer grants a permission, or who were deleted or
+// disabled since. Long-lived connections run it for permissions their token was checked for when they started.
+func (uc *UserUseCase) CheckPermission(ctx context.Context, userID uint, permission entity.Permission) error {
+	user, err := uc.UserRepository.FindByID(uc.DB.WithContext(ctx), userID)
+	if err != nil {
+		uc.Log.Errorf("FindByID error: %v", err)
+		return fiber.ErrInternalServerError
+	}
+	if user == nil {
+		uc.Log.Warnf("Permission check of deleted user ID: %d", userID)
+		return fiber.ErrUnauthorized
+	}
+	if user.Disabled() {
+		uc.Log.Warnf("Permission check of disabled user ID: %d", userID)
+		return errAccountDisabled()
+	}
+	if !entity.RoleHasPermission(user.Role, permission) {
+		uc.Log.Warnf("User ID %d with role %s no longer has permission %s", user.ID, user.Role, permission)
+		return fiber.ErrForbidden
+	}
+	return nil
 }
 
 // GetProfile retrieves user profile with wallet information.
@@ -169,9 +589,11 @@ func (uc *UserUseCase) GetProfile(ctx context.Context, userID uint) (*model.User
 	}
 
 	response := &model.UserProfileResponse{
-		ID:       user.ID,
-		Username: user.Username,
-		Role:     user.Role,
+		ID:                user.ID,
+		Username:          user.Username,
+		Role:              user.Role,
+		TwoFactorEnabled:  user.TwoFactorEnabled(),
+		TransactionPINSet: user.TransactionPINSet(),
 	}
 
 	if wallet != nil {
@@ -183,3 +605,52 @@ func (uc *UserUseCase) GetProfile(ctx context.Context, userID uint) (*model.User
 
 	return response, nil
 }
+
+// newSessionInfo describes the device a session is started from, naming it after the User-Agent
+// when the client did not send a device name.
+func newSessionInfo(deviceName string, userAgent string, ipAddress string) *model.SessionInfo {
+	if deviceName == "" {
+		deviceName = util.DeviceName(userAgent)
+	}
+	return &model.SessionInfo{
+		DeviceName: deviceName,
+		UserAgent:  userAgent,
+		IPAddress:  ipAddress,
+	}
+}
+
+// checkPassword compares the current password of a signed in user before it is changed.
+// The attempt is counted before the password is compared, after PasswordLimiter.MaxAttempts
+// wrong passwords the check is locked and a correct password clears the count.
+func (uc *UserUseCase) checkPassword(ctx context.Context, user *entity.User, password string) error {
+	key := userLimiterKey(user.ID)
+	attempt, err := uc.PasswordLimiter.Begin(ctx, key)
+	if err != nil {
+		uc.Log.Errorf("PasswordLimiter.Begin error: %v", err)
+		return fiber.ErrInternalServerError
+	}
+	if !attempt.Allowed() {
+		uc.Log.Warnf("Locked password check used by user ID: %d", user.ID)
+		return NewCodedError(fiber.StatusTooManyRequests, ErrorCodePasswordLocked, fmt.Sprintf("Too many wrong passwords, try again in %s", attempt.Wait.Round(time.Second)), attempt.Wait)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		uc.Log.Warnf("Current password mismatch for user ID %d, %d attempts left", user.ID, attempt.Left)
+		if attempt.Left == 0 {
+			return NewCodedError(fiber.StatusTooManyRequests, ErrorCodePasswordLocked, fmt.Sprintf("Password check is locked for %s after too many wrong attempts", uc.PasswordLimiter.Lockout), uc.PasswordLimiter.Lockout)
+		}
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Current password is incorrect, %d attempts left", attempt.Left))
+	}
+
+	if err := uc.PasswordLimiter.Reset(ctx, key); err != nil {
+		uc.Log.Errorf("PasswordLimiter.Reset error: %v", err)
+		return fiber.ErrInternalServerError
+	}
+	return nil
+}
+
+// loginUserLimiterKey returns the LoginUserLimiter key of a username.
+// Usernames match case-insensitively in MySQL, so case variants share one count.
+func loginUserLimiterKey(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
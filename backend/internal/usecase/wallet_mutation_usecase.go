@@ -38,13 +38,6 @@ func NewWalletMutationUseCase(
 
 // GetMutationsByUserID retrieves wallet mutations for a user.
 func (uc *WalletMutationUseCase) GetMutationsByUserID(ctx context.Context, userID uint, page, limit int) (*model.WalletMutationListResponse, error) {
-	if page <= 0 {
-		page = 1
-	}
-	if limit <= 0 {
-		limit = 10
-	}
-
 	// Get wallet for user
 	wallet, err := uc.WalletRepository.FindByUserID(uc.DB.WithContext(ctx), userID)
 	if err != nil {
@@ -55,7 +48,33 @@ func (uc *WalletMutationUseCase) GetMutationsByUserID(ctx context.Context, userI
 		return nil, fiber.NewError(fiber.StatusNotFound, "Wallet not found")
 	}
 
-	mutations, total, err := uc.WalletMutationRepository.FindByWalletID(uc.DB.WithContext(ctx), wallet.ID, page, limit)
+	return uc.listMutations(ctx, wallet.ID, page, limit)
+}
+
+// GetMutationsByWalletID retrieves the mutations of any wallet, including the treasury, for staff.
+func (uc *WalletMutationUseCase) GetMutationsByWalletID(ctx context.Context, walletID uint, page, limit int) (*model.WalletMutationListResponse, error) {
+	count, err := uc.WalletRepository.CountById(uc.DB.WithContext(ctx), walletID)
+	if err != nil {
+		uc.Log.Errorf("CountById error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	if count == 0 {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Wallet not found")
+	}
+
+	return uc.listMutations(ctx, walletID, page, limit)
+}
+
+// listMutations returns a page of the mutations of a wallet.
+func (uc *WalletMutationUseCase) listMutations(ctx context.Context, walletID uint, page, limit int) (*model.WalletMutationListResponse, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+
+	mutations, total, err := uc.WalletMutationRepository.FindByWalletID(uc.DB.WithContext(ctx), walletID, page, limit)
 	if err != nil {
 		uc.Log.Errorf("FindByWalletID error: %v", err)
 		return nil, fiber.ErrInternalServerError
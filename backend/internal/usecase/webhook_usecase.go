@@ -0,0 +1,235 @@
+package usecase
+
+import (
+	"backend/internal/entity"
+	"backend/internal/model"
+	"backend/internal/model/converter"
+	"backend/internal/repository"
+	"backend/internal/util"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type WebhookUseCase struct {
+	DB                        *gorm.DB
+	Log                       *logrus.Logger
+	Validate                  *validator.Validate
+	WebhookEndpointRepository *repository.WebhookEndpointRepository
+	WebhookDeliveryRepository *repository.WebhookDeliveryRepository
+}
+
+func NewWebhookUseCase(
+	db *gorm.DB,
+	log *logrus.Logger,
+	validate *validator.Validate,
+	webhookEndpointRepo *repository.WebhookEndpointRepository,
+	webhookDeliveryRepo *repository.WebhookDeliveryRepository,
+) *WebhookUseCase {
+	return &WebhookUseCase{
+		DB:                        db,
+		Log:                       log,
+		Validate:                  validate,
+		WebhookEndpointRepository: webhookEndpointRepo,
+		WebhookDeliveryRepository: webhookDeliveryRepo,
+	}
+}
+
+// Create registers a webhook endpoint for the authenticated user.
+// The signing secret is generated here and only returned in this response.
+func (uc *WebhookUseCase) Create(ctx context.Context, auth *model.Auth, request *model.CreateWebhookRequest) (*model.WebhookResponse, error) {
+	// Validate request
+	if err := uc.Validate.Struct(request); err != nil {
+		uc.Log.Warnf("Validation error: %v", err)
+		return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	// Deliveries are checked against the resolved address as well, this only catches the obvious targets early
+	if err := util.CheckPublicURL(request.URL); err != nil {
+		uc.Log.Warnf("Webhook URL %s refused for user ID %d: %v", request.URL, *auth.UserID, err)
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Webhook URL must point to a public address")
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		uc.Log.Errorf("Generate webhook secret error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	eventTypes := slices.Clone(request.EventTypes)
+	slices.Sort(eventTypes)
+	eventTypes = slices.Compact(eventTypes)
+
+	endpoint := &entity.WebhookEndpoint{
+		UserID:     *auth.UserID,
+		URL:        request.URL,
+		Secret:     secret,
+		EventTypes: strings.Join(eventTypes, ","),
+		IsActive:   true,
+	}
+
+	if err := uc.WebhookEndpointRepository.Create(uc.DB.WithContext(ctx), endpoint); err != nil {
+		uc.Log.Errorf("Webhook endpoint creation error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	uc.Log.Infof("Webhook endpoint ID %d registered by user ID: %d", endpoint.ID, *auth.UserID)
+
+	response := converter.WebhookEndpointToWebhookResponse(endpoint)
+	response.Secret = secret
+	return response, nil
+}
+
+// List returns the webhook endpoints of the authenticated user.
+func (uc *WebhookUseCase) List(ctx context.Context, auth *model.Auth) ([]model.WebhookResponse, error) {
+	endpoints, err := uc.WebhookEndpointRepository.FindByUserID(uc.DB.WithContext(ctx), *auth.UserID)
+	if err != nil {
+		uc.Log.Errorf("FindByUserID error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.WebhookEndpointsToWebhookResponses(endpoints), nil
+}
+
+// Delete removes a webhook endpoint of the authenticated user together with its delivery log.
+func (uc *WebhookUseCase) Delete(ctx context.Context, auth *model.Auth, webhookID uint) error {
+	endpoint, err := uc.findEndpoint(ctx, auth, webhookID)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.WebhookEndpointRepository.Delete(uc.DB.WithContext(ctx), endpoint); err != nil {
+		uc.Log.Errorf("Webhook endpoint deletion error: %v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	uc.Log.Infof("Webhook endpoint ID %d deleted by user ID: %d", endpoint.ID, *auth.UserID)
+	return nil
+}
+
+// GetDeliveries returns the delivery log of a webhook endpoint of the authenticated user.
+func (uc *WebhookUseCase) GetDeliveries(ctx context.Context, auth *model.Auth, webhookID uint, page, limit int) (*model.WebhookDeliveryListResponse, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	endpoint, err := uc.findEndpoint(ctx, auth, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries, total, err := uc.WebhookDeliveryRepository.FindByEndpointID(uc.DB.WithContext(ctx), endpoint.ID, page, limit)
+	if err != nil {
+		uc.Log.Errorf("FindByEndpointID error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return &model.WebhookDeliveryListResponse{
+		Deliveries: converter.WebhookDeliveriesToWebhookDeliveryResponses(deliveries),
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+	}, nil
+}
+
+// Redeliver queues a delivery to be sent again, whatever its current status.
+func (uc *WebhookUseCase) Redeliver(ctx context.Context, auth *model.Auth, webhookID uint, deliveryID uint) (*model.WebhookDeliveryResponse, error) {
+	endpoint, err := uc.findEndpoint(ctx, auth, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	db := uc.DB.WithContext(ctx)
+	delivery, err := uc.WebhookDeliveryRepository.FindByIDAndEndpointID(db, deliveryID, endpoint.ID)
+	if err != nil {
+		uc.Log.Errorf("FindByIDAndEndpointID error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	if delivery == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Webhook delivery not found")
+	}
+
+	now := time.Now()
+	if err := uc.WebhookDeliveryRepository.ResetForRedelivery(db, delivery.ID, now); err != nil {
+		uc.Log.Errorf("ResetForRedelivery error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	uc.Log.Infof("Webhook delivery ID %d queued for redelivery by user ID: %d", delivery.ID, *auth.UserID)
+
+	delivery.Status = entity.WebhookDeliveryStatusPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = now
+	return converter.WebhookDeliveryToWebhookDeliveryResponse(delivery), nil
+}
+
+// EnqueueForEvent queues a delivery of an outbox event to every subscribed endpoint as part of tx.
+// Running it again for the same event does not queue duplicates.
+func (uc *WebhookUseCase) EnqueueForEvent(tx *gorm.DB, event *entity.OutboxEvent) error {
+	endpoints, err := uc.WebhookEndpointRepository.FindSubscribed(tx, event.UserID, event.EventType)
+	if err != nil || len(endpoints) == 0 {
+		return err
+	}
+
+	payload, err := json.Marshal(&model.WebhookEvent{
+		ID:        event.ID,
+		Type:      string(event.EventType),
+		UserID:    event.UserID,
+		CreatedAt: event.CreatedAt,
+		Payload:   event.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	deliveries := make([]entity.WebhookDelivery, len(endpoints))
+	for i, endpoint := range endpoints {
+		deliveries[i] = entity.WebhookDelivery{
+			WebhookEndpointID: endpoint.ID,
+			OutboxEventID:     event.ID,
+			EventType:         event.EventType,
+			Payload:           payload,
+			Status:            entity.WebhookDeliveryStatusPending,
+			NextAttemptAt:     now,
+		}
+	}
+
+	return uc.WebhookDeliveryRepository.CreateIgnoringDuplicates(tx, deliveries)
+}
+
+// findEndpoint finds a webhook endpoint owned by the authenticated user.
+func (uc *WebhookUseCase) findEndpoint(ctx context.Context, auth *model.Auth, webhookID uint) (*entity.WebhookEndpoint, error) {
+	endpoint, err := uc.WebhookEndpointRepository.FindByIDAndUserID(uc.DB.WithContext(ctx), webhookID, *auth.UserID)
+	if err != nil {
+		uc.Log.Errorf("FindByIDAndUserID error: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	if endpoint == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Webhook not found")
+	}
+	return endpoint, nil
+}
+
+// generateWebhookSecret returns a random hex encoded HMAC secret.
+func generateWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
@@ -0,0 +1,197 @@
+package util
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// countFailureLua defines countFailure, which counts a failed attempt and locks the key once ARGV[1] failures were reached.
+// KEYS are the failure counter, lock key and delay key, ARGV[2] the lockout in milliseconds.
+// From ARGV[3] failures on, when it is positive, every further failure delays the next attempt by
+// ARGV[4] milliseconds doubled per failure, up to the lockout. Returns the attempts left.
+const countFailureLua = `
+local function countFailure()
+	local failures = redis.call('INCR', KEYS[1])
+	if failures == 1 then
+		redis.call('PEXPIRE', KEYS[1], ARGV[2])
+	end
+	if failures >= tonumber(ARGV[1]) then
+		redis.call('SET', KEYS[2], '1', 'PX', ARGV[2])
+		redis.call('DEL', KEYS[1], KEYS[3])
+		return 0
+	end
+	local delayAfter = tonumber(ARGV[3])
+	if delayAfter > 0 and failures >= delayAfter then
+		local delay = math.min(tonumber(ARGV[4]) * 2 ^ (failures - delayAfter), tonumber(ARGV[2]))
+		redis.call('SET', KEYS[3], '1', 'PX', math.floor(delay))
+	end
+	return tonumber(ARGV[1]) - failures
+end
+`
+
+// recordFailureScript counts a failed attempt, see countFailureLua.
+var recordFailureScript = redis.NewScript(countFailureLua + `
+return countFailure()
+`)
+
+// beginAttemptScript refuses an attempt while the key is locked or delayed, returning 1 or 2 and the time left.
+// Otherwise it counts the attempt as failed up front, see countFailureLua, and returns 0 and the attempts left.
+var beginAttemptScript = redis.NewScript(countFailureLua + `
+local locked = redis.call('PTTL', KEYS[2])
+if locked > 0 then
+	return {1, locked}
+end
+local delayed = redis.call('PTTL', KEYS[3])
+if delayed > 0 then
+	return {2, delayed}
+end
+return {0, countFailure()}
+`)
+
+// releaseAttemptScript takes back a successful attempt counted by beginAttemptScript.
+// ARGV[3] are the attempts it left, when zero the attempt locked the key and the lock is lifted again.
+// A delay of the key was started by the attempt itself, the one before had run out or it would have been refused,
+// so it is lifted as well.
+var releaseAttemptScript = redis.NewScript(`
+redis.call('DEL', KEYS[3])
+if tonumber(ARGV[3]) == 0 then
+	redis.call('DEL', KEYS[2])
+	if tonumber(ARGV[1]) > 1 then
+		redis.call('SET', KEYS[1], tonumber(ARGV[1]) - 1, 'PX', ARGV[2])
+	end
+	return 0
+end
+if tonumber(redis.call('GET', KEYS[1]) or '0') > 0 then
+	redis.call('DECR', KEYS[1])
+end
+return 0
+`)
+
+// Attempt is an attempt started with AttemptLimiter.Begin.
+// Locked or Delayed is set when it was refused, Wait is then how long until the next attempt is allowed.
+// Left is how many attempts are left once this one fails, zero means the key is locked until it succeeds.
+type Attempt struct {
+	Locked  bool
+	Delayed bool
+	Wait    time.Duration
+	Left    int
+}
+
+// Allowed reports whether the attempt may go ahead.
+func (a *Attempt) Allowed() bool {
+	return !a.Locked && !a.Delayed
+}
+
+// AttemptLimiter locks a key, such as a user ID, out after MaxAttempts failed attempts.
+// Failures are counted in Redis for Lockout since the first one, so every instance shares them,
+// and a key that reached the limit stays locked for Lockout.
+// With a progressive delay, failures from DelayAfter on also make the key wait BaseDelay,
+// doubled per further failure, before the next attempt.
+type AttemptLimiter struct {
+	Redis       *redis.Client
+	Prefix      string
+	MaxAttempts int
+	Lockout     time.Duration
+	DelayAfter  int
+	BaseDelay   time.Duration
+}
+
+// NewAttemptLimiter creates a new instance of AttemptLimiter.
+// prefix namespaces the Redis keys, so limiters for different secrets do not share counters.
+func NewAttemptLimiter(redisClient *redis.Client, prefix string, maxAttempts int, lockout time.Duration) *AttemptLimiter {
+	return &AttemptLimiter{
+		Redis:       redisClient,
+		Prefix:      prefix,
+		MaxAttempts: maxAttempts,
+		Lockout:     lockout,
+	}
+}
+
+// WithProgressiveDelay enables the progressive delay from the delayAfter-th failure on, starting at baseDelay.
+func (l *AttemptLimiter) WithProgressiveDelay(delayAfter int, baseDelay time.Duration) *AttemptLimiter {
+	l.DelayAfter = delayAfter
+	l.BaseDelay = baseDelay
+	return l
+}
+
+// LockedFor returns how much longer a key is locked out, zero when it is not.
+func (l *AttemptLimiter) LockedFor(ctx context.Context, key string) (time.Duration, error) {
+	return l.ttl(ctx, l.lockKey(key))
+}
+
+// DelayedFor returns how much longer a key has to wait before its next attempt, zero when it does not.
+func (l *AttemptLimiter) DelayedFor(ctx context.Context, key string) (time.Duration, error) {
+	return l.ttl(ctx, l.delayKey(key))
+}
+
+// RecordFailure counts a failed attempt and returns how many attempts are left.
+// Zero attempts left means the key is now locked for Lockout.
+func (l *AttemptLimiter) RecordFailure(ctx context.Context, key string) (int, error) {
+	return recordFailureScript.Run(ctx, l.Redis,
+		[]string{l.failuresKey(key), l.lockKey(key), l.delayKey(key)},
+		l.MaxAttempts, l.Lockout.Milliseconds(), l.DelayAfter, l.BaseDelay.Milliseconds(),
+	).Int()
+}
+
+// Begin starts an attempt, counting it as failed before the secret is checked, so concurrent attempts
+// can never get past MaxAttempts. A refused attempt is not counted.
+// A successful attempt is followed by Reset, or by Release when the key keeps its other failures.
+// A failed attempt needs nothing further.
+func (l *AttemptLimiter) Begin(ctx context.Context, key string) (*Attempt, error) {
+	result, err := beginAttemptScript.Run(ctx, l.Redis,
+		[]string{l.failuresKey(key), l.lockKey(key), l.delayKey(key)},
+		l.MaxAttempts, l.Lockout.Milliseconds(), l.DelayAfter, l.BaseDelay.Milliseconds(),
+	).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+
+	switch result[0] {
+	case 1:
+		return &Attempt{Locked: true, Wait: time.Duration(result[1]) * time.Millisecond}, nil
+	case 2:
+		return &Attempt{Delayed: true, Wait: time.Duration(result[1]) * time.Millisecond}, nil
+	default:
+		return &Attempt{Left: int(result[1])}, nil
+	}
+}
+
+// Release takes back a successful attempt started with Begin, the other failures of the key still count.
+// A progressive delay the attempt started is lifted, so the next attempt may follow right away.
+func (l *AttemptLimiter) Release(ctx context.Context, key string, attempt *Attempt) error {
+	return releaseAttemptScript.Run(ctx, l.Redis,
+		[]string{l.failuresKey(key), l.lockKey(key), l.delayKey(key)},
+		l.MaxAttempts, l.Lockout.Milliseconds(), attempt.Left,
+	).Err()
+}
+
+// Reset forgets the failed attempts of a key and lifts its lock, after a successful attempt or by an admin.
+func (l *AttemptLimiter) Reset(ctx context.Context, key string) error {
+	return l.Redis.Del(ctx, l.failuresKey(key), l.lockKey(key), l.delayKey(key)).Err()
+}
+
+// ttl returns the remaining lifetime of a Redis key, zero when it does not exist.
+func (l *AttemptLimiter) ttl(ctx context.Context, redisKey string) (time.Duration, error) {
+	ttl, err := l.Redis.PTTL(ctx, redisKey).Result()
+	if err != nil {
+		return 0, err
+	}
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+func (l *AttemptLimiter) failuresKey(key string) string {
+	return l.Prefix + ":failures:" + key
+}
+
+func (l *AttemptLimiter) lockKey(key string) string {
+	return l.Prefix + ":lock:" + key
+}
+
+func (l *AttemptLimiter) delayKey(key string) string {
+	return l.Prefix + ":delay:" + key
+}
@@ -0,0 +1,55 @@
+package util
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+	"syscall"
+)
+
+// ErrNonPublicAddress is returned for outgoing connections to addresses that are not on the public internet.
+var ErrNonPublicAddress = errors.New("address is not a public internet address")
+
+// sharedAddressSpace is the carrier-grade NAT range of RFC 6598, private to the network of a provider.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// IsPublicIP reports whether ip is reachable on the public internet.
+// Loopback, private, link-local, multicast, unspecified and shared addresses are not.
+func IsPublicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() || sharedAddressSpace.Contains(ip))
+}
+
+// CheckPublicURL returns ErrNonPublicAddress when the host of rawURL is localhost or an IP literal that is not public.
+// Hostnames may resolve to anything later on, so connections still have to be checked with PublicDialControl.
+func CheckPublicURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrNonPublicAddress
+	}
+	if ip := net.ParseIP(host); ip != nil && !IsPublicIP(ip) {
+		return ErrNonPublicAddress
+	}
+	return nil
+}
+
+// PublicDialControl is a net.Dialer Control function that refuses to connect to addresses that are not public.
+// It runs on the resolved address, so a hostname that is rebound to an internal address is refused as well.
+func PublicDialControl(network string, address string, conn syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !IsPublicIP(ip) {
+		return ErrNonPublicAddress
+	}
+	return nil
+}
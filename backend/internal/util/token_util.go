@@ -3,7 +3,13 @@ package util
 import (
 	"backend/internal/model"
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -11,25 +17,441 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+const (
+	refreshTokenKeyPrefix  = "refresh_token:"
+	refreshFamilyKeyPrefix = "refresh_family:"
+	userSessionsKeyPrefix  = "user_sessions:"
+	passwordResetKeyPrefix = "password_reset:"
+	// passwordResetUserKeyPrefix points at the hash of the one reset token a user may have outstanding
+	passwordResetUserKeyPrefix  = "password_reset_user:"
+	twoFactorChallengeKeyPrefix = "two_factor_challenge:"
+
+	// TwoFactorChallengeTTL is how long the second login step may take after the password was accepted.
+	TwoFactorChallengeTTL = 5 * time.Minute
+	// twoFactorChallengeMaxAttempts is how many codes may be tried against one challenge before it is dropped.
+	twoFactorChallengeMaxAttempts = 5
+)
+
+var (
+	// ErrRefreshTokenInvalid is returned for refresh tokens that are unknown, expired or belong to a revoked family.
+	ErrRefreshTokenInvalid = errors.New("refresh token is invalid")
+	// ErrRefreshTokenReused is returned when a refresh token that was already rotated is presented again.
+	// The whole token family is revoked before it is returned.
+	ErrRefreshTokenReused = errors.New("refresh token was already used")
+	// ErrPasswordResetTokenInvalid is returned for password reset tokens that are unknown, expired or already redeemed.
+	ErrPasswordResetTokenInvalid = errors.New("password reset token is invalid")
+	// ErrTwoFactorChallengeInvalid is returned for login challenges that are unknown, expired, already completed
+	// or had too many wrong codes.
+	ErrTwoFactorChallengeInvalid = errors.New("two-factor challenge is invalid")
+)
+
+// attemptTwoFactorChallengeScript counts an attempt against a login challenge and returns its fields,
+// a challenge that ran out of attempts is deleted. ARGV[1] is the maximum number of attempts.
+var attemptTwoFactorChallengeScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return false
+end
+local attempts = redis.call('HINCRBY', KEYS[1], 'attempts', 1)
+if attempts > tonumber(ARGV[1]) then
+	redis.call('DEL', KEYS[1])
+	return false
+end
+return redis.call('HMGET', KEYS[1], 'user_id', 'device', 'user_agent', 'ip_address')
+`)
+
+// createPasswordResetTokenScript stores a reset token for a user and drops the one issued before it.
+// KEYS are the token and user key, ARGV the token key prefix, user ID, TTL in milliseconds and token hash.
+var createPasswordResetTokenScript = redis.NewScript(`
+local previous = redis.call('GET', KEYS[2])
+if previous then
+	redis.call('DEL', ARGV[1] .. previous)
+end
+redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+redis.call('SET', KEYS[2], ARGV[4], 'PX', ARGV[3])
+return 1
+`)
+
+// consumePasswordResetTokenScript deletes a reset token and returns its user, or nil when it does not exist.
+var consumePasswordResetTokenScript = redis.NewScript(`
+local userID = redis.call('GET', KEYS[1])
+if not userID then
+	return false
+end
+redis.call('DEL', KEYS[1], ARGV[1] .. userID)
+return userID
+`)
+
+// storeRefreshTokenScript stores a refresh token of a family and extends the family,
+// unless the family was revoked in the meantime. KEYS are the token and family key.
+var storeRefreshTokenScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[2]) == 0 then
+	return 0
+end
+redis.call('HSET', KEYS[1], 'family_id', ARGV[1], 'user_id', ARGV[2], 'rotated', '0')
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+redis.call('HSET', KEYS[2], 'last_seen_at', ARGV[4], 'ip_address', ARGV[5])
+redis.call('PEXPIRE', KEYS[2], ARGV[3])
+return 1
+`)
+
+// touchSessionScript records activity on a session that still exists.
+var touchSessionScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+redis.call('HSET', KEYS[1], 'last_seen_at', ARGV[1], 'ip_address', ARGV[2])
+return 1
+`)
+
+// revokeSessionScript deletes a family when it belongs to the user in ARGV[1]. KEYS are the family and user sessions key.
+var revokeSessionScript = redis.NewScript(`
+if redis.call('HGET', KEYS[1], 'user_id') ~= ARGV[1] then
+	return 0
+end
+redis.call('DEL', KEYS[1])
+redis.call('SREM', KEYS[2], ARGV[2])
+return 1
+`)
+
+// rotateRefreshTokenScript marks a refresh token as rotated and returns its family and user.
+// A token that was rotated before revokes its family, the family key is built from ARGV[1].
+var rotateRefreshTokenScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return {'invalid'}
+end
+local token = redis.call('HMGET', KEYS[1], 'family_id', 'user_id', 'rotated')
+local familyKey = ARGV[1] .. token[1]
+if token[3] == '1' then
+	redis.call('DEL', familyKey)
+	return {'reused', token[1], token[2]}
+end
+if redis.call('EXISTS', familyKey) == 0 then
+	return {'invalid'}
+end
+redis.call('HSET', KEYS[1], 'rotated', '1')
+return {'ok', token[1], token[2]}
+`)
+
 type TokenUtil struct {
-	SecretKey string
-	Redis     *redis.Client
+	SecretKey        string
+	Redis            *redis.Client
+	AccessTTL        time.Duration
+	RefreshTTL       time.Duration
+	PasswordResetTTL time.Duration
 }
 
 // NewTokenUtil creates a new instance of TokenUtil.
 // TokenUtil requires a secret key for signing tokens and a Redis client for token storage and management.
-func NewTokenUtil(secretKey string, redisClient *redis.Client) *TokenUtil {
+// Access tokens live for accessTTL, refresh tokens and their family for refreshTTL since the last rotation,
+// password reset tokens for passwordResetTTL.
+func NewTokenUtil(secretKey string, redisClient *redis.Client, accessTTL time.Duration, refreshTTL time.Duration, passwordResetTTL time.Duration) *TokenUtil {
 	return &TokenUtil{
-		SecretKey: secretKey,
-		Redis:     redisClient,
+		SecretKey:        secretKey,
+		Redis:            redisClient,
+		AccessTTL:        accessTTL,
+		RefreshTTL:       refreshTTL,
+		PasswordResetTTL: passwordResetTTL,
 	}
 }
 
-// CreateToken generates a new JWT token for the given Auth model.
+// CreateTokenPair starts a new refresh token family and issues its first access and refresh token.
+// The family is the session of one device and is listed in the session registry of the user.
+func (tu *TokenUtil) CreateTokenPair(ctx context.Context, auth *model.Auth, session *model.SessionInfo) (*model.TokenPair, error) {
+	familyID, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	familyKey := refreshFamilyKeyPrefix + familyID
+	sessionsKey := userSessionsKey(*auth.UserID)
+	_, err = tu.Redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, familyKey,
+			"user_id", *auth.UserID,
+			"device", session.DeviceName,
+			"user_agent", session.UserAgent,
+			"ip_address", session.IPAddress,
+			"created_at", now,
+			"last_seen_at", now,
+		)
+		pipe.Expire(ctx, familyKey, tu.RefreshTTL)
+		pipe.SAdd(ctx, sessionsKey, familyID)
+		pipe.Expire(ctx, sessionsKey, tu.RefreshTTL)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tu.createTokenPair(ctx, auth, familyID, session.IPAddress)
+}
+
+// RotateRefreshToken consumes a refresh token and returns the user and family it was issued to.
+// The caller issues the next pair of the family with CreateTokenPairInFamily.
+func (tu *TokenUtil) RotateRefreshToken(ctx context.Context, refreshToken string) (uint, string, error) {
+	result, err := rotateRefreshTokenScript.Run(ctx, tu.Redis, []string{refreshTokenKey(refreshToken)}, refreshFamilyKeyPrefix).StringSlice()
+	if err != nil {
+		return 0, "", err
+	}
+
+	if result[0] == "invalid" {
+		return 0, "", ErrRefreshTokenInvalid
+	}
+
+	userID, err := strconv.ParseUint(result[2], 10, 64)
+	if err != nil {
+		return 0, "", err
+	}
+
+	if result[0] == "reused" {
+		return uint(userID), result[1], ErrRefreshTokenReused
+	}
+	return uint(userID), result[1], nil
+}
+
+// CreateTokenPairInFamily issues the next access and refresh token of a family after a rotation.
+// ipAddress is recorded as the last address the session was seen from.
+func (tu *TokenUtil) CreateTokenPairInFamily(ctx context.Context, auth *model.Auth, familyID string, ipAddress string) (*model.TokenPair, error) {
+	return tu.createTokenPair(ctx, auth, familyID, ipAddress)
+}
+
+// RevokeFamily invalidates every access and refresh token of a family.
+func (tu *TokenUtil) RevokeFamily(ctx context.Context, familyID string) error {
+	return tu.Redis.Del(ctx, refreshFamilyKeyPrefix+familyID).Err()
+}
+
+// RevokeToken invalidates a single access token.
+func (tu *TokenUtil) RevokeToken(ctx context.Context, tokenString string) error {
+	return tu.Redis.Del(ctx, tokenString).Err()
+}
+
+// TouchSession records that a session was used just now from ipAddress.
+// Sessions that were revoked are left alone.
+func (tu *TokenUtil) TouchSession(ctx context.Context, familyID string, ipAddress string) error {
+	return touchSessionScript.Run(ctx, tu.Redis, []string{refreshFamilyKeyPrefix + familyID}, time.Now().Unix(), ipAddress).Err()
+}
+
+// ListSessions returns the active sessions of a user, most recently used first.
+// Sessions that expired or were revoked are dropped from the registry on the way.
+func (tu *TokenUtil) ListSessions(ctx context.Context, userID uint) ([]model.SessionResponse, error) {
+	sessionsKey := userSessionsKey(userID)
+	familyIDs, err := tu.Redis.SMembers(ctx, sessionsKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	commands := make([]*redis.MapStringStringCmd, len(familyIDs))
+	_, err = tu.Redis.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, familyID := range familyIDs {
+			commands[i] = pipe.HGetAll(ctx, refreshFamilyKeyPrefix+familyID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]model.SessionResponse, 0, len(familyIDs))
+	var stale []interface{}
+	for i, command := range commands {
+		fields := command.Val()
+		if len(fields) == 0 {
+			stale = append(stale, familyIDs[i])
+			continue
+		}
+		sessions = append(sessions, model.SessionResponse{
+			ID:         familyIDs[i],
+			Device:     fields["device"],
+			UserAgent:  fields["user_agent"],
+			IPAddress:  fields["ip_address"],
+			CreatedAt:  unixField(fields["created_at"]),
+			LastSeenAt: unixField(fields["last_seen_at"]),
+		})
+	}
+
+	if len(stale) > 0 {
+		if err := tu.Redis.SRem(ctx, sessionsKey, stale...).Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+	})
+	return sessions, nil
+}
+
+// RevokeSession revokes a session of a user and reports whether the user had such a session.
+func (tu *TokenUtil) RevokeSession(ctx context.Context, userID uint, familyID string) (bool, error) {
+	revoked, err := revokeSessionScript.Run(ctx, tu.Redis, []string{refreshFamilyKeyPrefix + familyID, userSessionsKey(userID)}, userID, familyID).Int()
+	return revoked == 1, err
+}
+
+// RevokeOtherSessions revokes every session of a user except keepFamilyID and returns how many were revoked.
+func (tu *TokenUtil) RevokeOtherSessions(ctx context.Context, userID uint, keepFamilyID string) (int, error) {
+	sessions, err := tu.ListSessions(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	revoked := 0
+	for _, session := range sessions {
+		if session.ID == keepFamilyID {
+			continue
+		}
+		ok, err := tu.RevokeSession(ctx, userID, session.ID)
+		if err != nil {
+			return revoked, err
+		}
+		if ok {
+			revoked++
+		}
+	}
+	return revoked, nil
+}
+
+// CreatePasswordResetToken issues a single-use password reset token for a user.
+// Only the latest token of a user can be redeemed, issuing one invalidates the previous.
+func (tu *TokenUtil) CreatePasswordResetToken(ctx context.Context, userID uint) (string, time.Time, error) {
+	token, err := randomToken(32)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	tokenHash := hashToken(token)
+	expiresAt := time.Now().Add(tu.PasswordResetTTL)
+	err = createPasswordResetTokenScript.Run(ctx, tu.Redis,
+		[]string{passwordResetKeyPrefix + tokenHash, fmt.Sprintf("%s%d", passwordResetUserKeyPrefix, userID)},
+		passwordResetKeyPrefix, userID, tu.PasswordResetTTL.Milliseconds(), tokenHash,
+	).Err()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return token, expiresAt, nil
+}
+
+// ConsumePasswordResetToken redeems a password reset token and returns the user it was issued for.
+func (tu *TokenUtil) ConsumePasswordResetToken(ctx context.Context, token string) (uint, error) {
+	result, err := consumePasswordResetTokenScript.Run(ctx, tu.Redis,
+		[]string{passwordResetKeyPrefix + hashToken(token)},
+		passwordResetUserKeyPrefix,
+	).Text()
+	if errors.Is(err, redis.Nil) {
+		return 0, ErrPasswordResetTokenInvalid
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	userID, err := strconv.ParseUint(result, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return uint(userID), nil
+}
+
+// CreateTwoFactorChallenge remembers that a user passed the password step of a login from a device
+// and returns the token that completes the login together with a second factor.
+func (tu *TokenUtil) CreateTwoFactorChallenge(ctx context.Context, userID uint, session *model.SessionInfo) (string, time.Time, error) {
+	token, err := randomToken(32)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	key := twoFactorChallengeKeyPrefix + hashToken(token)
+	expiresAt := time.Now().Add(TwoFactorChallengeTTL)
+	_, err = tu.Redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key,
+			"user_id", userID,
+			"device", session.DeviceName,
+			"user_agent", session.UserAgent,
+			"ip_address", session.IPAddress,
+			"attempts", 0,
+		)
+		pipe.Expire(ctx, key, TwoFactorChallengeTTL)
+		return nil
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return token, expiresAt, nil
+}
+
+// AttemptTwoFactorChallenge counts an attempt to complete a login challenge and returns the user and device
+// it was created for. The challenge stays valid until CompleteTwoFactorChallenge or its attempts run out.
+func (tu *TokenUtil) AttemptTwoFactorChallenge(ctx context.Context, token string) (uint, *model.SessionInfo, error) {
+	result, err := attemptTwoFactorChallengeScript.Run(ctx, tu.Redis,
+		[]string{twoFactorChallengeKeyPrefix + hashToken(token)},
+		twoFactorChallengeMaxAttempts,
+	).StringSlice()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil, ErrTwoFactorChallengeInvalid
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+
+	userID, err := strconv.ParseUint(result[0], 10, 64)
+	if err != nil {
+		return 0, nil, err
+	}
+	return uint(userID), &model.SessionInfo{
+		DeviceName: result[1],
+		UserAgent:  result[2],
+		IPAddress:  result[3],
+	}, nil
+}
+
+// CompleteTwoFactorChallenge deletes a login challenge and reports whether it still existed,
+// so of two concurrent requests only one completes the login.
+func (tu *TokenUtil) CompleteTwoFactorChallenge(ctx context.Context, token string) (bool, error) {
+	deleted, err := tu.Redis.Del(ctx, twoFactorChallengeKeyPrefix+hashToken(token)).Result()
+	return deleted == 1, err
+}
+
+// createTokenPair signs an access token and stores it together with a new refresh token of the family.
+func (tu *TokenUtil) createTokenPair(ctx context.Context, auth *model.Auth, familyID string, ipAddress string) (*model.TokenPair, error) {
+	now := time.Now()
+	auth.FamilyID = familyID
+
+	accessToken, err := tu.CreateToken(ctx, auth)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	// Store the refresh token and extend the family, the family key gates every token issued with it
+	stored, err := storeRefreshTokenScript.Run(ctx, tu.Redis,
+		[]string{refreshTokenKey(refreshToken), refreshFamilyKeyPrefix + familyID},
+		familyID, *auth.UserID, tu.RefreshTTL.Milliseconds(), now.Unix(), ipAddress,
+	).Int()
+	if err != nil {
+		return nil, err
+	}
+	if stored == 0 {
+		// The family was revoked while the pair was being issued
+		return nil, ErrRefreshTokenInvalid
+	}
+
+	return &model.TokenPair{
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  auth.ExpiresAt.Time,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: now.Add(tu.RefreshTTL),
+	}, nil
+}
+
+// CreateToken generates a new JWT access token for the given Auth model.
 func (tu *TokenUtil) CreateToken(ctx context.Context, auth *model.Auth) (string, error) {
-	// Set token now and expiry with in 30 days
+	// Set token now and expiry after the access token lifetime
 	now := time.Now()
-	expiryDuration := time.Hour * 24 * 30
+	expiryDuration := tu.AccessTTL
 
 	// Assign registered claims
 	auth.ExpiresAt = jwt.NewNumericDate(now.Add(expiryDuration))
@@ -78,15 +500,50 @@ func (tu *TokenUtil) ParseToken(ctx context.Context, tokenString string) (*model
 		return nil, fiber.ErrUnauthorized
 	}
 
-	// Check token existence in Redis
-	result, err := tu.Redis.Exists(ctx, tokenString).Result()
+	// Check token existence in Redis, tokens of a revoked family are rejected as well
+	keys := []string{tokenString}
+	if claims.FamilyID != "" {
+		keys = append(keys, refreshFamilyKeyPrefix+claims.FamilyID)
+	}
+	result, err := tu.Redis.Exists(ctx, keys...).Result()
 	if err != nil {
 		return nil, err
 	}
 
-	if result == 0 {
+	if result != int64(len(keys)) {
 		return nil, fiber.ErrUnauthorized
 	}
 
 	return claims, nil
 }
+
+// userSessionsKey returns the Redis key of the set of session (family) IDs of a user.
+func userSessionsKey(userID uint) string {
+	return fmt.Sprintf("%s%d", userSessionsKeyPrefix, userID)
+}
+
+// unixField parses a Unix timestamp stored in a session hash.
+func unixField(value string) time.Time {
+	seconds, _ := strconv.ParseInt(value, 10, 64)
+	return time.Unix(seconds, 0)
+}
+
+// refreshTokenKey returns the Redis key of a refresh token, only its hash is stored.
+func refreshTokenKey(refreshToken string) string {
+	return refreshTokenKeyPrefix + hashToken(refreshToken)
+}
+
+// hashToken returns the hex SHA-256 of an opaque token, so Redis never holds a usable token.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomToken returns size random bytes as hex.
+func randomToken(size int) (string, error) {
+	token := make([]byte, size)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
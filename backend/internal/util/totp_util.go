@@ -0,0 +1,195 @@
+package util
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// totpPeriod, totpDigits and SHA-1 are the RFC 6238 defaults every authenticator app supports.
+	totpPeriod = 30
+	totpDigits = 6
+	// totpSkew is the number of time steps before and after the current one a code is accepted for,
+	// so a clock that is slightly off still works.
+	totpSkew = 1
+	// recoveryCodeAlphabet leaves out characters that are easily confused when typed in,
+	// its 32 characters keep every character equally likely.
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz023456789"
+)
+
+// ErrTOTPSecretInvalid is returned when a stored TOTP secret cannot be decrypted or decoded.
+var ErrTOTPSecretInvalid = errors.New("totp secret is invalid")
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TOTPUtil implements RFC 6238 time-based one-time passwords and the recovery codes that back them up.
+// Secrets are encrypted with AES-GCM before they are stored.
+type TOTPUtil struct {
+	Issuer string
+	aead   cipher.AEAD
+}
+
+// NewTOTPUtil creates a new instance of TOTPUtil.
+// issuer is shown next to the account in authenticator apps, encryptionKey protects the stored secrets.
+func NewTOTPUtil(issuer string, encryptionKey string) *TOTPUtil {
+	key := sha256.Sum256([]byte(encryptionKey))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		panic(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		panic(err)
+	}
+	return &TOTPUtil{
+		Issuer: issuer,
+		aead:   aead,
+	}
+}
+
+// GenerateSecret returns a new random 160-bit secret, base32 encoded as authenticator apps expect it.
+func (tu *TOTPUtil) GenerateSecret() (string, error) {
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(secret), nil
+}
+
+// ProvisioningURI returns the otpauth:// URI of a secret, usually shown as a QR code.
+func (tu *TOTPUtil) ProvisioningURI(accountName string, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", tu.Issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(totpDigits))
+	query.Set("period", fmt.Sprint(totpPeriod))
+
+	label := url.PathEscape(tu.Issuer) + ":" + url.PathEscape(accountName)
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// GenerateCode returns the code of a secret for the time step at.
+func (tu *TOTPUtil) GenerateCode(secret string, at time.Time) (string, error) {
+	key, err := decodeTOTPSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return totpCode(key, totpStep(at)), nil
+}
+
+// Validate checks a code against a secret around the time at and returns the time step it belongs to.
+// Callers record the step so the same code cannot be used twice.
+func (tu *TOTPUtil) Validate(secret string, code string, at time.Time) (int64, bool) {
+	code = strings.TrimSpace(code)
+	if len(code) != totpDigits {
+		return 0, false
+	}
+
+	key, err := decodeTOTPSecret(secret)
+	if err != nil {
+		return 0, false
+	}
+
+	current := totpStep(at)
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// EncryptSecret encrypts a secret for storage, the random nonce is prepended to the ciphertext.
+func (tu *TOTPUtil) EncryptSecret(secret string) (string, error) {
+	nonce := make([]byte, tu.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := tu.aead.Seal(nonce, nonce, []byte(secret), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptSecret reverses EncryptSecret.
+func (tu *TOTPUtil) DecryptSecret(encrypted string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil || len(sealed) < tu.aead.NonceSize() {
+		return "", ErrTOTPSecretInvalid
+	}
+
+	nonce, ciphertext := sealed[:tu.aead.NonceSize()], sealed[tu.aead.NonceSize():]
+	secret, err := tu.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", ErrTOTPSecretInvalid
+	}
+	return string(secret), nil
+}
+
+// GenerateRecoveryCodes returns count random recovery codes formatted as xxxxx-xxxxx.
+func (tu *TOTPUtil) GenerateRecoveryCodes(count int) ([]string, error) {
+	codes := make([]string, count)
+	for i := range codes {
+		random := make([]byte, 10)
+		if _, err := rand.Read(random); err != nil {
+			return nil, err
+		}
+
+		var code strings.Builder
+		for j, b := range random {
+			if j == 5 {
+				code.WriteByte('-')
+			}
+			code.WriteByte(recoveryCodeAlphabet[int(b)%len(recoveryCodeAlphabet)])
+		}
+		codes[i] = code.String()
+	}
+	return codes, nil
+}
+
+// HashRecoveryCode returns the hash a recovery code is stored under.
+// Case, spaces and dashes are ignored so the code can be typed in loosely.
+func (tu *TOTPUtil) HashRecoveryCode(code string) string {
+	normalized := strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(strings.TrimSpace(code)))
+	return hashToken(normalized)
+}
+
+// decodeTOTPSecret decodes a base32 secret, with or without padding.
+func decodeTOTPSecret(secret string) ([]byte, error) {
+	key, err := totpEncoding.DecodeString(strings.TrimRight(strings.ToUpper(secret), "="))
+	if err != nil || len(key) == 0 {
+		return nil, ErrTOTPSecretInvalid
+	}
+	return key, nil
+}
+
+// totpStep returns the RFC 6238 time step of a moment.
+func totpStep(at time.Time) int64 {
+	return at.Unix() / totpPeriod
+}
+
+// totpCode computes the RFC 4226 HOTP value of a key for a counter.
+func totpCode(key []byte, counter int64) string {
+	var message [8]byte
+	binary.BigEndian.PutUint64(message[:], uint64(counter))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(message[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
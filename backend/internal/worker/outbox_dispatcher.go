@@ -0,0 +1,197 @@
+package worker
+
+import (
+	"backend/internal/delivery/websocket"
+	"backend/internal/entity"
+	"backend/internal/model"
+	"backend/internal/repository"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+// maxRetryDelay caps the exponential backoff between outbox and webhook delivery attempts.
+const maxRetryDelay = 5 * time.Minute
+
+// outboxLease is how long a claimed outbox event is left alone before another dispatcher may deliver it again.
+const outboxLease = time.Minute
+
+// WebhookEnqueuerInterface queues webhook deliveries for an outbox event inside a database transaction.
+type WebhookEnqueuerInterface interface {
+	EnqueueForEvent(tx *gorm.DB, event *entity.OutboxEvent) error
+}
+
+// OutboxDispatcher delivers pending outbox events through the notifier and hands them to webhooks.
+// Events are marked sent only after a successful delivery, so they survive restarts.
+type OutboxDispatcher struct {
+	DB                    *gorm.DB
+	Log                   *logrus.Logger
+	OutboxEventRepository *repository.OutboxEventRepository
+	Notifier              websocket.NotifierInterface
+	Webhooks              WebhookEnqueuerInterface
+	Interval              time.Duration
+	BatchSize             int
+	MaxAttempts           int
+}
+
+// NewOutboxDispatcher creates a new OutboxDispatcher instance.
+func NewOutboxDispatcher(db *gorm.DB, log *logrus.Logger, config *viper.Viper, outboxEventRepo *repository.OutboxEventRepository, notifier websocket.NotifierInterface, webhooks WebhookEnqueuerInterface) *OutboxDispatcher {
+	return &OutboxDispatcher{
+		DB:                    db,
+		Log:                   log,
+		OutboxEventRepository: outboxEventRepo,
+		Notifier:              notifier,
+		Webhooks:              webhooks,
+		Interval:              config.GetDuration("outbox.interval"),
+		BatchSize:             config.GetInt("outbox.batch_size"),
+		MaxAttempts:           config.GetInt("outbox.max_attempts"),
+	}
+}
+
+// Run polls the outbox until ctx is cancelled.
+func (d *OutboxDispatcher) Run(ctx context.Context) {
+	d.Log.Infof("Outbox dispatcher started, polling every %s", d.Interval)
+
+	ticker := time.NewTicker(d.Interval)
+	defer ticker.Stop()
+
+	for {
+		// Keep draining while batches come back full
+		for {
+			dispatched, err := d.DispatchBatch(ctx)
+			if err != nil {
+				d.Log.Errorf("Outbox dispatch error: %v", err)
+				break
+			}
+			if dispatched < d.BatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			d.Log.Info("Outbox dispatcher stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchBatch claims one batch of due events, delivers them in order and returns how many were processed.
+func (d *OutboxDispatcher) DispatchBatch(ctx context.Context) (int, error) {
+	events, err := d.claim(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range events {
+		d.dispatch(ctx, &events[i])
+	}
+
+	return len(events), nil
+}
+
+// claim leases due events in a short transaction so delivery happens without holding row locks,
+// and queues their webhook deliveries in the same transaction. Retried events are not queued twice.
+// An event whose dispatcher dies is delivered again once the lease runs out, the event log drops the duplicate.
+func (d *OutboxDispatcher) claim(ctx context.Context) ([]entity.OutboxEvent, error) {
+	// Start transaction
+	tx := d.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	now := time.Now()
+	events, err := d.OutboxEventRepository.ClaimDue(tx, now, now.Add(outboxLease), d.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.Webhooks != nil {
+		for i := range events {
+			if err := d.Webhooks.EnqueueForEvent(tx, &events[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// dispatch delivers a single claimed event and records the outcome.
+func (d *OutboxDispatcher) dispatch(ctx context.Context, event *entity.OutboxEvent) {
+	db := d.DB.WithContext(ctx)
+
+	err := d.Deliver(event)
+	now := time.Now()
+
+	if err == nil {
+		if err := d.OutboxEventRepository.MarkSent(db, event.ID, now); err != nil {
+			d.Log.Errorf("MarkSent error for outbox event ID %d: %v", event.ID, err)
+		}
+		return
+	}
+
+	attempts := event.Attempts + 1
+	status := entity.OutboxEventStatusPending
+	if attempts >= d.MaxAttempts {
+		status = entity.OutboxEventStatusFailed
+		d.Log.Errorf("Outbox event ID %d failed after %d attempts: %v", event.ID, attempts, err)
+	} else {
+		d.Log.Warnf("Outbox event ID %d delivery attempt %d failed: %v", event.ID, attempts, err)
+	}
+
+	if err := d.OutboxEventRepository.MarkAttemptFailed(db, event.ID, status, err.Error(), now.Add(retryDelay(attempts))); err != nil {
+		d.Log.Errorf("MarkAttemptFailed error for outbox event ID %d: %v", event.ID, err)
+	}
+}
+
+// Deliver sends a single outbox event through the notifier.
+// The event is keyed by its ID, so a retry after a failed publish is logged for replay only once.
+func (d *OutboxDispatcher) Deliver(event *entity.OutboxEvent) error {
+	eventKey := outboxEventKey(event.ID)
+	switch event.EventType {
+	case entity.OutboxEventTypeTransaction:
+		notification := new(model.TransactionNotification)
+		if err := json.Unmarshal(event.Payload, notification); err != nil {
+			return err
+		}
+		return d.Notifier.NotifyTransaction(event.UserID, eventKey, notification)
+	case entity.OutboxEventTypeWalletUpdate:
+		notification := new(model.WalletUpdateNotification)
+		if err := json.Unmarshal(event.Payload, notification); err != nil {
+			return err
+		}
+		return d.Notifier.NotifyWalletUpdate(event.UserID, eventKey, notification)
+	case entity.OutboxEventTypeAdminTransaction:
+		notification := new(model.AdminTransactionNotification)
+		if err := json.Unmarshal(event.Payload, notification); err != nil {
+			return err
+		}
+		return d.Notifier.NotifyAdminTransaction(notification)
+	default:
+		return fmt.Errorf("unknown outbox event type %q", event.EventType)
+	}
+}
+
+// outboxEventKey identifies an outbox event in the WebSocket event log.
+func outboxEventKey(eventID uint) string {
+	return fmt.Sprintf("outbox:%d", eventID)
+}
+
+// retryDelay returns the backoff before the next attempt, doubling from one second.
+func retryDelay(attempts int) time.Duration {
+	delay := time.Second
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
@@ -0,0 +1,149 @@
+package worker
+
+import (
+	"backend/internal/delivery/webhook"
+	"backend/internal/entity"
+	"backend/internal/repository"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+// WebhookDispatcher sends queued webhook deliveries and retries failures with exponential backoff.
+type WebhookDispatcher struct {
+	DB                        *gorm.DB
+	Log                       *logrus.Logger
+	WebhookDeliveryRepository *repository.WebhookDeliveryRepository
+	Sender                    *webhook.Sender
+	Interval                  time.Duration
+	BatchSize                 int
+	MaxAttempts               int
+}
+
+// NewWebhookDispatcher creates a new WebhookDispatcher instance.
+func NewWebhookDispatcher(db *gorm.DB, log *logrus.Logger, config *viper.Viper, webhookDeliveryRepo *repository.WebhookDeliveryRepository, sender *webhook.Sender) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		DB:                        db,
+		Log:                       log,
+		WebhookDeliveryRepository: webhookDeliveryRepo,
+		Sender:                    sender,
+		Interval:                  config.GetDuration("webhook.interval"),
+		BatchSize:                 config.GetInt("webhook.batch_size"),
+		MaxAttempts:               config.GetInt("webhook.max_attempts"),
+	}
+}
+
+// Run polls for due deliveries until ctx is cancelled.
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	d.Log.Infof("Webhook dispatcher started, polling every %s", d.Interval)
+
+	ticker := time.NewTicker(d.Interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			dispatched, err := d.DispatchBatch(ctx)
+			if err != nil {
+				d.Log.Errorf("Webhook dispatch error: %v", err)
+				break
+			}
+			if dispatched < d.BatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			d.Log.Info("Webhook dispatcher stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchBatch claims one batch of due deliveries, sends them concurrently
+// and returns how many were processed.
+func (d *WebhookDispatcher) DispatchBatch(ctx context.Context) (int, error) {
+	deliveries, err := d.claim(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	for i := range deliveries {
+		wg.Add(1)
+		go func(delivery *entity.WebhookDelivery) {
+			defer wg.Done()
+			d.send(ctx, delivery)
+		}(&deliveries[i])
+	}
+	wg.Wait()
+
+	return len(deliveries), nil
+}
+
+// claim leases due deliveries in a short transaction so the HTTP calls happen without holding row locks.
+// A delivery whose sender dies is picked up again once the lease runs out.
+func (d *WebhookDispatcher) claim(ctx context.Context) ([]entity.WebhookDelivery, error) {
+	// Start transaction
+	tx := d.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	now := time.Now()
+	leaseUntil := now.Add(d.Sender.Client.Timeout + time.Minute)
+	deliveries, err := d.WebhookDeliveryRepository.ClaimDue(tx, now, leaseUntil, d.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// send delivers a single webhook and records the outcome.
+func (d *WebhookDispatcher) send(ctx context.Context, delivery *entity.WebhookDelivery) {
+	db := d.DB.WithContext(ctx)
+
+	if delivery.WebhookEndpoint == nil || !delivery.WebhookEndpoint.IsActive {
+		if err := d.WebhookDeliveryRepository.MarkAttemptFailed(db, delivery.ID, entity.WebhookDeliveryStatusFailed, nil, "webhook endpoint is inactive", time.Now()); err != nil {
+			d.Log.Errorf("MarkAttemptFailed error for webhook delivery ID %d: %v", delivery.ID, err)
+		}
+		return
+	}
+
+	statusCode, err := d.Sender.Send(ctx, delivery.WebhookEndpoint, delivery)
+	now := time.Now()
+
+	if err == nil {
+		if err := d.WebhookDeliveryRepository.MarkSucceeded(db, delivery.ID, statusCode, now); err != nil {
+			d.Log.Errorf("MarkSucceeded error for webhook delivery ID %d: %v", delivery.ID, err)
+		}
+		return
+	}
+
+	var responseStatus *int
+	if statusCode != 0 {
+		responseStatus = &statusCode
+	}
+
+	attempts := delivery.Attempts + 1
+	status := entity.WebhookDeliveryStatusPending
+	if attempts >= d.MaxAttempts {
+		status = entity.WebhookDeliveryStatusFailed
+		d.Log.Errorf("Webhook delivery ID %d failed after %d attempts: %v", delivery.ID, attempts, err)
+	} else {
+		d.Log.Warnf("Webhook delivery ID %d attempt %d failed: %v", delivery.ID, attempts, err)
+	}
+
+	if err := d.WebhookDeliveryRepository.MarkAttemptFailed(db, delivery.ID, status, responseStatus, err.Error(), now.Add(retryDelay(attempts))); err != nil {
+		d.Log.Errorf("MarkAttemptFailed error for webhook delivery ID %d: %v", delivery.ID, err)
+	}
+}
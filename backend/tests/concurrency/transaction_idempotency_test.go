@@ -0,0 +1,61 @@
+package concurrency_test
+
+import (
+	"backend/internal/model"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTransfer_ReplayAndInvalidRequestSkipPIN tests that an idempotent replay is answered without checking
+// the PIN again, and that neither a replay nor an invalid request counts as a wrong PIN attempt.
+func TestTransfer_ReplayAndInvalidRequestSkipPIN(t *testing.T) {
+	env := setupTestEnv(t)
+
+	admin, _ := env.createUser(t, "super_admin")
+	sender, _ := env.createUser(t, "user")
+	recipient, _ := env.createUser(t, "user")
+	env.fund(t, admin, sender, decimal.NewFromInt(10))
+
+	request := &model.TransferRequest{
+		ToUserID:       recipient.ID,
+		Amount:         decimal.NewFromInt(1),
+		PIN:            testPIN,
+		IdempotencyKey: "replay-skips-pin",
+	}
+	first, err := env.TransactionUseCase.Transfer(context.Background(), authFor(sender), request)
+	require.NoError(t, err)
+
+	// More replays with a wrong PIN than the limiter allows, none of them may lock the PIN
+	for i := 0; i < 10; i++ {
+		replayed, err := env.TransactionUseCase.Transfer(context.Background(), authFor(sender), &model.TransferRequest{
+			ToUserID:       recipient.ID,
+			Amount:         decimal.NewFromInt(1),
+			PIN:            "000000",
+			IdempotencyKey: "replay-skips-pin",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, first.ID, replayed.ID)
+
+		_, err = env.TransactionUseCase.Transfer(context.Background(), authFor(sender), &model.TransferRequest{
+			ToUserID: recipient.ID,
+			Amount:   decimal.NewFromInt(-1),
+			PIN:      "000000",
+		})
+		var fiberErr *fiber.Error
+		require.True(t, errors.As(err, &fiberErr))
+		assert.Equal(t, fiber.StatusBadRequest, fiberErr.Code)
+	}
+
+	_, err = env.TransactionUseCase.Transfer(context.Background(), authFor(sender), &model.TransferRequest{
+		ToUserID: recipient.ID,
+		Amount:   decimal.NewFromInt(1),
+		PIN:      testPIN,
+	})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,68 @@
+package concurrency_test
+
+import (
+	"backend/internal/entity"
+	"backend/internal/model"
+	"context"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestReverse_LegacyTopUpCreditsTreasury reverses a top-up booked before the treasury existed,
+// which credited the recipient without debiting anyone. The money must go back to the treasury.
+func TestReverse_LegacyTopUpCreditsTreasury(t *testing.T) {
+	env := setupTestEnv(t)
+
+	amount := decimal.NewFromInt(100)
+	admin, _ := env.createUser(t, "super_admin")
+	_, wallet := env.createUser(t, "user")
+
+	// Book the top-up the way it was booked before the treasury, with a credit leg only
+	legacy := &entity.Transaction{
+		Type:              entity.TransactionTypeTopUp,
+		Amount:            amount,
+		ToWalletID:        &wallet.ID,
+		PerformedByUserID: admin.ID,
+		Status:            entity.TransactionStatusCompleted,
+	}
+	require.NoError(t, env.DB.Create(legacy).Error)
+	require.NoError(t, env.DB.Create(&entity.WalletMutation{
+		WalletID:      wallet.ID,
+		TransactionID: legacy.ID,
+		Type:          entity.MutationTypeCredit,
+		Amount:        amount,
+		BalanceBefore: decimal.Zero,
+		BalanceAfter:  amount,
+	}).Error)
+	require.NoError(t, env.WalletRepository.UpdateBalance(env.DB, wallet, amount))
+
+	treasuryBefore, err := env.WalletRepository.FindTreasury(env.DB)
+	require.NoError(t, err)
+	require.NotNil(t, treasuryBefore)
+
+	reversal, err := env.TransactionUseCase.Reverse(context.Background(), authFor(admin), &model.ReverseTransactionRequest{
+		TransactionID: legacy.ID,
+		Description:   "Reverse legacy top-up",
+	})
+	require.NoError(t, err)
+	assert.True(t, reversal.Amount.Equal(amount))
+	assert.Equal(t, &wallet.ID, reversal.FromWalletID)
+	assert.Equal(t, &treasuryBefore.ID, reversal.ToWalletID)
+
+	treasuryAfter, err := env.WalletRepository.FindTreasury(env.DB)
+	require.NoError(t, err)
+	assert.True(t, treasuryAfter.Balance.Equal(treasuryBefore.Balance.Add(amount)),
+		"treasury balance should grow by %s, got %s -> %s", amount, treasuryBefore.Balance, treasuryAfter.Balance)
+
+	report, err := env.ReconciliationUseCase.Run(context.Background())
+	require.NoError(t, err)
+
+	balance := env.assertWalletConsistent(t, report, wallet.ID)
+	assert.True(t, balance.IsZero(), "wallet balance should be zero after the reversal, got %s", balance)
+	for _, reconciliation := range report.Transactions {
+		assert.NotEqual(t, reversal.ID, reconciliation.TransactionID, "reversal has ledger discrepancies: %v", reconciliation.Discrepancies)
+	}
+}
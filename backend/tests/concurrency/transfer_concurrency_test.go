@@ -0,0 +1,264 @@
+package concurrency_test
+
+import (
+	"backend/internal/config"
+	"backend/internal/entity"
+	"backend/internal/model"
+	"backend/internal/repository"
+	"backend/internal/usecase"
+	"backend/internal/util"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// These tests hit a real, migrated MySQL database configured through .env or
+// DATABASE_* environment variables. They write ledger data, so point them at a
+// disposable database. Transfers check the transaction PIN against Redis, configured
+// through REDIS_*. Without DATABASE_HOST or REDIS_HOST they are skipped.
+
+// testPIN is the transaction PIN of every user created by the tests.
+const testPIN = "123456"
+
+type testEnv struct {
+	DB                    *gorm.DB
+	Log                   *logrus.Logger
+	Validate              *validator.Validate
+	TransactionUseCase    *usecase.TransactionUseCase
+	ReconciliationUseCase *usecase.ReconciliationUseCase
+	UserRepository        *repository.UserRepository
+	WalletRepository      *repository.WalletRepository
+}
+
+// setupTestEnv connects to the configured database or skips the test.
+func setupTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	viperConfig := config.NewViper()
+	if viperConfig.GetString("DATABASE_HOST") == "" || viperConfig.GetString("REDIS_HOST") == "" {
+		t.Skip("DATABASE_HOST or REDIS_HOST is not configured, skipping database concurrency test")
+	}
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	db := config.NewDatabase(viperConfig, log)
+	validate := config.NewValidator()
+	redisClient := config.NewRedisClient(viperConfig)
+	t.Cleanup(func() { redisClient.Close() })
+
+	userRepository := repository.NewUserRepository(log)
+	walletRepository := repository.NewWalletRepository(log)
+	transactionRepository := repository.NewTransactionRepository(log)
+	walletMutationRepository := repository.NewWalletMutationRepository(log)
+	idempotencyKeyRepository := repository.NewIdempotencyKeyRepository(log)
+	outboxEventRepository := repository.NewOutboxEventRepository(log)
+	pinAttemptLimiter := util.NewAttemptLimiter(redisClient, "transaction_pin_test", 5, time.Minute)
+	transactionPINUseCase := usecase.NewTransactionPINUseCase(db, log, validate, userRepository, pinAttemptLimiter)
+
+	return &testEnv{
+		DB:       db,
+		Log:      log,
+		Validate: validate,
+		TransactionUseCase: usecase.NewTransactionUseCase(
+			db, log, validate, transactionRepository, walletRepository, walletMutationRepository, idempotencyKeyRepository, outboxEventRepository, userRepository, transactionPINUseCase,
+		),
+		ReconciliationUseCase: usecase.NewReconciliationUseCase(
+			db, log, validate, walletRepository, walletMutationRepository, transactionRepository,
+		),
+		UserRepository:   userRepository,
+		WalletRepository: walletRepository,
+	}
+}
+
+// createUser creates a user with an empty wallet and returns both.
+func (e *testEnv) createUser(t *testing.T, role string) (*entity.User, *entity.Wallet) {
+	t.Helper()
+
+	pinHash, err := bcrypt.GenerateFromPassword([]byte(testPIN), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	user := &entity.User{
+		Username:       fmt.Sprintf("concurrency_%s_%d", role, time.Now().UnixNano()),
+		Password:       "!",
+		TransactionPIN: string(pinHash),
+		Role:           role,
+	}
+	require.NoError(t, e.UserRepository.Create(e.DB, user))
+
+	wallet := &entity.Wallet{
+		UserID:  user.ID,
+		Balance: decimal.Zero,
+	}
+	require.NoError(t, e.WalletRepository.Create(e.DB, wallet))
+
+	return user, wallet
+}
+
+// fund tops up a user's wallet through the regular top-up flow so the ledger stays balanced.
+func (e *testEnv) fund(t *testing.T, admin *entity.User, user *entity.User, amount decimal.Decimal) {
+	t.Helper()
+
+	_, err := e.TransactionUseCase.TopUp(context.Background(), authFor(admin), &model.TopUpRequest{
+		ToUserID:    user.ID,
+		Amount:      amount,
+		Description: "Concurrency test funding",
+	})
+	require.NoError(t, err)
+}
+
+// assertWalletConsistent checks the wallet balance is non-negative and matches its mutation chain.
+func (e *testEnv) assertWalletConsistent(t *testing.T, report *model.ReconciliationReport, walletID uint) decimal.Decimal {
+	t.Helper()
+
+	wallet := new(entity.Wallet)
+	require.NoError(t, e.WalletRepository.FindByID(e.DB, wallet, walletID))
+	assert.False(t, wallet.Balance.IsNegative(), "wallet %d has negative balance %s", walletID, wallet.Balance)
+
+	for _, reconciliation := range report.Wallets {
+		if reconciliation.WalletID == walletID {
+			assert.Empty(t, reconciliation.Discrepancies, "wallet %d has ledger discrepancies", walletID)
+		}
+	}
+
+	return wallet.Balance
+}
+
+func authFor(user *entity.User) *model.Auth {
+	userID := user.ID
+	return &model.Auth{
+		UserID:   &userID,
+		Username: user.Username,
+		Role:     user.Role,
+	}
+}
+
+// TestTransfer_ConcurrentDrainCannotOverdraw fires many parallel transfers at one
+// wallet that can only cover some of them.
+func TestTransfer_ConcurrentDrainCannotOverdraw(t *testing.T) {
+	env := setupTestEnv(t)
+
+	const (
+		transfers = 200
+		funded    = 50
+	)
+
+	admin, _ := env.createUser(t, "super_admin")
+	sender, senderWallet := env.createUser(t, "user")
+	recipient, recipientWallet := env.createUser(t, "user")
+	env.fund(t, admin, sender, decimal.NewFromInt(funded))
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		succeeded int
+		rejected  int
+		failures  []error
+	)
+
+	for i := 0; i < transfers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, err := env.TransactionUseCase.Transfer(context.Background(), authFor(sender), &model.TransferRequest{
+				ToUserID: recipient.ID,
+				Amount:   decimal.NewFromInt(1),
+				PIN:      testPIN,
+			})
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			var fiberErr *fiber.Error
+			switch {
+			case err == nil:
+				succeeded++
+			case errors.As(err, &fiberErr) && fiberErr.Code == fiber.StatusBadRequest:
+				rejected++
+			default:
+				failures = append(failures, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Empty(t, failures)
+	assert.Equal(t, funded, succeeded)
+	assert.Equal(t, transfers-funded, rejected)
+
+	report, err := env.ReconciliationUseCase.Run(context.Background())
+	require.NoError(t, err)
+
+	senderBalance := env.assertWalletConsistent(t, report, senderWallet.ID)
+	recipientBalance := env.assertWalletConsistent(t, report, recipientWallet.ID)
+	assert.True(t, senderBalance.IsZero(), "sender balance should be drained, got %s", senderBalance)
+	assert.True(t, recipientBalance.Equal(decimal.NewFromInt(funded)), "recipient balance should be %d, got %s", funded, recipientBalance)
+}
+
+// TestTransfer_ConcurrentOppositeDirections transfers back and forth between two
+// wallets in parallel to exercise lock ordering.
+func TestTransfer_ConcurrentOppositeDirections(t *testing.T) {
+	env := setupTestEnv(t)
+
+	const transfers = 200
+
+	admin, _ := env.createUser(t, "super_admin")
+	alice, aliceWallet := env.createUser(t, "user")
+	bob, bobWallet := env.createUser(t, "user")
+	env.fund(t, admin, alice, decimal.NewFromInt(100))
+	env.fund(t, admin, bob, decimal.NewFromInt(100))
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		failures []error
+	)
+
+	for i := 0; i < transfers; i++ {
+		from, to := alice, bob
+		if i%2 == 1 {
+			from, to = bob, alice
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, err := env.TransactionUseCase.Transfer(context.Background(), authFor(from), &model.TransferRequest{
+				ToUserID: to.ID,
+				Amount:   decimal.NewFromInt(3),
+				PIN:      testPIN,
+			})
+
+			var fiberErr *fiber.Error
+			if err != nil && !(errors.As(err, &fiberErr) && fiberErr.Code == fiber.StatusBadRequest) {
+				mu.Lock()
+				failures = append(failures, err)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Empty(t, failures)
+
+	report, err := env.ReconciliationUseCase.Run(context.Background())
+	require.NoError(t, err)
+
+	aliceBalance := env.assertWalletConsistent(t, report, aliceWallet.ID)
+	bobBalance := env.assertWalletConsistent(t, report, bobWallet.ID)
+	assert.True(t, aliceBalance.Add(bobBalance).Equal(decimal.NewFromInt(200)), "total balance should be conserved, got %s", aliceBalance.Add(bobBalance))
+}
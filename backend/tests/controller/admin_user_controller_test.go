@@ -0,0 +1,225 @@
+package controller_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	httpDelivery "backend/internal/delivery/http"
+	"backend/internal/model"
+	"backend/tests/mocks"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// setupAdminUserTestApp creates a Fiber app with AdminUserController for testing.
+func setupAdminUserTestApp(mockUseCase *mocks.MockAdminUserUseCase) *fiber.App {
+	app := fiber.New()
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	controller := httpDelivery.NewAdminUserController(log, mockUseCase)
+
+	app.Use(authenticateAs(9, "super_admin"))
+	app.Get("/admin/users", controller.Search)
+	app.Get("/admin/users/:id", controller.Get)
+	app.Put("/admin/users/:id/role", controller.ChangeRole)
+	app.Post("/admin/users/:id/disable", controller.Disable)
+	app.Post("/admin/users/:id/enable", controller.Enable)
+	app.Post("/admin/users/:id/logout", controller.ForceLogout)
+
+	return app
+}
+
+// TestSearchUsers_Success tests listing users with filters and paging.
+func TestSearchUsers_Success(t *testing.T) {
+	mockUseCase := new(mocks.MockAdminUserUseCase)
+	app := setupAdminUserTestApp(mockUseCase)
+
+	mockUseCase.On("Search", mock.Anything, mock.MatchedBy(func(request *model.AdminUserSearchRequest) bool {
+		return request.Query == "ali" && request.Role == "user" && request.Disabled != nil && !*request.Disabled &&
+			request.Page == 2 && request.Limit == 5
+	})).Return(&model.AdminUserListResponse{
+		Users: []model.AdminUserResponse{{ID: 2, Username: "alice", Role: "user", CreatedAt: time.Now()}},
+		Total: 6,
+		Page:  2,
+		Limit: 5,
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/users?query=ali&role=user&disabled=false&page=2&limit=5", nil)
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&result)
+
+	data := result["data"].(map[string]interface{})
+	assert.Equal(t, float64(6), data["total"])
+	assert.Equal(t, "alice", data["users"].([]interface{})[0].(map[string]interface{})["username"])
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestSearchUsers_InvalidDisabledFilter tests a disabled filter that is not a boolean.
+func TestSearchUsers_InvalidDisabledFilter(t *testing.T) {
+	mockUseCase := new(mocks.MockAdminUserUseCase)
+	app := setupAdminUserTestApp(mockUseCase)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/users?disabled=maybe", nil)
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	mockUseCase.AssertNotCalled(t, "Search", mock.Anything, mock.Anything)
+}
+
+// TestGetUser_Success tests viewing a user with their wallet.
+func TestGetUser_Success(t *testing.T) {
+	mockUseCase := new(mocks.MockAdminUserUseCase)
+	app := setupAdminUserTestApp(mockUseCase)
+
+	mockUseCase.On("Get", mock.Anything, uint(2)).Return(&model.AdminUserDetailResponse{
+		AdminUserResponse: model.AdminUserResponse{ID: 2, Username: "alice", Role: "user"},
+		Wallet:            &model.WalletResponse{ID: 7, UserID: 2, Balance: decimal.NewFromInt(50000)},
+		ActiveSessions:    2,
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/users/2", nil)
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&result)
+
+	data := result["data"].(map[string]interface{})
+	assert.Equal(t, "alice", data["username"])
+	assert.Equal(t, float64(2), data["active_sessions"])
+	assert.Equal(t, float64(7), data["wallet"].(map[string]interface{})["id"])
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestGetUser_NotFound tests viewing a user that does not exist.
+func TestGetUser_NotFound(t *testing.T) {
+	mockUseCase := new(mocks.MockAdminUserUseCase)
+	app := setupAdminUserTestApp(mockUseCase)
+
+	mockUseCase.On("Get", mock.Anything, uint(99)).Return(nil, fiber.NewError(fiber.StatusNotFound, "User not found"))
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/users/99", nil)
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestChangeRole_Success tests promoting a user to admin.
+func TestChangeRole_Success(t *testing.T) {
+	mockUseCase := new(mocks.MockAdminUserUseCase)
+	app := setupAdminUserTestApp(mockUseCase)
+
+	mockUseCase.On("ChangeRole", mock.Anything, mock.MatchedBy(func(auth *model.Auth) bool {
+		return *auth.UserID == 9
+	}), uint(2), mock.MatchedBy(func(request *model.ChangeRoleRequest) bool {
+		return request.Role == "admin"
+	})).Return(&model.AdminUserResponse{ID: 2, Username: "alice", Role: "admin"}, nil)
+
+	body, _ := json.Marshal(map[string]string{"role": "admin"})
+	req := httptest.NewRequest(http.MethodPut, "/admin/users/2/role", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestDisableUser_Success tests disabling a user account.
+func TestDisableUser_Success(t *testing.T) {
+	mockUseCase := new(mocks.MockAdminUserUseCase)
+	app := setupAdminUserTestApp(mockUseCase)
+
+	disabledAt := time.Now()
+	mockUseCase.On("Disable", mock.Anything, mock.Anything, uint(2)).
+		Return(&model.AdminUserResponse{ID: 2, Username: "alice", Role: "user", DisabledAt: &disabledAt}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/users/2/disable", nil)
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&result)
+	assert.NotNil(t, result["data"].(map[string]interface{})["disabled_at"])
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestEnableUser_NotDisabled tests enabling an account that is not disabled.
+func TestEnableUser_NotDisabled(t *testing.T) {
+	mockUseCase := new(mocks.MockAdminUserUseCase)
+	app := setupAdminUserTestApp(mockUseCase)
+
+	mockUseCase.On("Enable", mock.Anything, mock.Anything, uint(2)).
+		Return(nil, fiber.NewError(fiber.StatusConflict, "User is not disabled"))
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/users/2/enable", nil)
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestForceLogout_Success tests ending every session of a user.
+func TestForceLogout_Success(t *testing.T) {
+	mockUseCase := new(mocks.MockAdminUserUseCase)
+	app := setupAdminUserTestApp(mockUseCase)
+
+	mockUseCase.On("ForceLogout", mock.Anything, mock.Anything, uint(2)).
+		Return(&model.RevokeSessionsResponse{Revoked: 3}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/users/2/logout", nil)
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&result)
+	assert.Equal(t, float64(3), result["data"].(map[string]interface{})["revoked"])
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestForceLogout_InvalidID tests force-logout with a user ID that is not a number.
+func TestForceLogout_InvalidID(t *testing.T) {
+	mockUseCase := new(mocks.MockAdminUserUseCase)
+	app := setupAdminUserTestApp(mockUseCase)
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/users/abc/logout", nil)
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	mockUseCase.AssertNotCalled(t, "ForceLogout", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,116 @@
+package controller_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	httpDelivery "backend/internal/delivery/http"
+	"backend/internal/delivery/http/middleware"
+	"backend/internal/entity"
+	"backend/internal/model"
+	"backend/tests/mocks"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// setupAdminHistoryTestApp creates a Fiber app with the audited admin history routes for testing.
+func setupAdminHistoryTestApp(auditUseCase *mocks.MockAuditLogUseCase, transactionUseCase *mocks.MockTransactionUseCase, mutationUseCase *mocks.MockWalletMutationUseCase) *fiber.App {
+	app := fiber.New()
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	transactionController := httpDelivery.NewTransactionController(log, transactionUseCase)
+	mutationController := httpDelivery.NewWalletMutationController(log, mutationUseCase)
+	audit := middleware.NewAudit(auditUseCase)
+
+	app.Use(authenticateAs(9, "admin"))
+	app.Get("/admin/users/:id/transactions", audit(entity.AuditActionTransactionsView, entity.AuditTargetUser), transactionController.GetUserTransactions)
+	app.Get("/admin/wallets/:id/wallet-mutations", audit(entity.AuditActionWalletMutationsView, entity.AuditTargetWallet), mutationController.GetWalletMutations)
+
+	return app
+}
+
+// TestGetUserTransactions_Audited tests that an admin reading a user's transactions is recorded first.
+func TestGetUserTransactions_Audited(t *testing.T) {
+	auditUseCase := new(mocks.MockAuditLogUseCase)
+	transactionUseCase := new(mocks.MockTransactionUseCase)
+	app := setupAdminHistoryTestApp(auditUseCase, transactionUseCase, new(mocks.MockWalletMutationUseCase))
+
+	auditUseCase.On("Record", mock.Anything, mock.MatchedBy(func(request *model.AuditLogRequest) bool {
+		return request.ActorUserID == 9 && request.ActorRole == "admin" &&
+			request.Action == entity.AuditActionTransactionsView &&
+			request.TargetType == entity.AuditTargetUser && request.TargetID == 2 &&
+			request.UserAgent == "support-console"
+	})).Return(nil)
+	transactionUseCase.On("GetTransactionsByUserID", mock.Anything, uint(2), 1, 20).
+		Return(&model.TransactionListResponse{Total: 0, Page: 1, Limit: 20}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/users/2/transactions?limit=20", nil)
+	req.Header.Set("User-Agent", "support-console")
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	auditUseCase.AssertExpectations(t)
+	transactionUseCase.AssertExpectations(t)
+}
+
+// TestGetWalletMutations_Audited tests reading the mutations of a wallet by its ID.
+func TestGetWalletMutations_Audited(t *testing.T) {
+	auditUseCase := new(mocks.MockAuditLogUseCase)
+	mutationUseCase := new(mocks.MockWalletMutationUseCase)
+	app := setupAdminHistoryTestApp(auditUseCase, new(mocks.MockTransactionUseCase), mutationUseCase)
+
+	auditUseCase.On("Record", mock.Anything, mock.MatchedBy(func(request *model.AuditLogRequest) bool {
+		return request.Action == entity.AuditActionWalletMutationsView &&
+			request.TargetType == entity.AuditTargetWallet && request.TargetID == 7
+	})).Return(nil)
+	mutationUseCase.On("GetMutationsByWalletID", mock.Anything, uint(7), 1, 10).
+		Return(nil, fiber.NewError(fiber.StatusNotFound, "Wallet not found"))
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/wallets/7/wallet-mutations", nil)
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	auditUseCase.AssertExpectations(t)
+	mutationUseCase.AssertExpectations(t)
+}
+
+// TestAudit_RecordFails tests that nothing is read when the access cannot be recorded.
+func TestAudit_RecordFails(t *testing.T) {
+	auditUseCase := new(mocks.MockAuditLogUseCase)
+	transactionUseCase := new(mocks.MockTransactionUseCase)
+	app := setupAdminHistoryTestApp(auditUseCase, transactionUseCase, new(mocks.MockWalletMutationUseCase))
+
+	auditUseCase.On("Record", mock.Anything, mock.Anything).Return(fiber.ErrInternalServerError)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/users/2/transactions", nil)
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+	transactionUseCase.AssertNotCalled(t, "GetTransactionsByUserID", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestAudit_InvalidID tests that a target ID that is not a number is rejected before anything is recorded.
+func TestAudit_InvalidID(t *testing.T) {
+	auditUseCase := new(mocks.MockAuditLogUseCase)
+	app := setupAdminHistoryTestApp(auditUseCase, new(mocks.MockTransactionUseCase), new(mocks.MockWalletMutationUseCase))
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/users/abc/transactions", nil)
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	auditUseCase.AssertNotCalled(t, "Record", mock.Anything, mock.Anything)
+}
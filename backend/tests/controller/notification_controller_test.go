@@ -0,0 +1,248 @@
+package controller_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"backend/internal/config"
+	httpDelivery "backend/internal/delivery/http"
+	"backend/internal/model"
+	"backend/internal/usecase"
+	"backend/tests/mocks"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// setupNotificationTestApp creates a Fiber app with NotificationController for testing.
+func setupNotificationTestApp(mockUseCase *mocks.MockNotificationUseCase) *fiber.App {
+	app := fiber.New()
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	controller := httpDelivery.NewNotificationController(log, mockUseCase)
+
+	// Middleware to set auth context for testing
+	app.Use(func(c *fiber.Ctx) error {
+		userID := uint(1)
+		auth := &model.Auth{
+			UserID:   &userID,
+			Username: "testuser",
+			Role:     "user",
+		}
+		c.Locals("auth", auth)
+		return c.Next()
+	})
+
+	app.Get("/notifications", controller.List)
+	app.Get("/notifications/unread-count", controller.CountUnread)
+	app.Post("/notifications/read", controller.MarkAllRead)
+	app.Post("/notifications/unread", controller.MarkAllUnread)
+	app.Post("/notifications/:id/read", controller.MarkRead)
+	app.Post("/notifications/:id/unread", controller.MarkUnread)
+
+	return app
+}
+
+// TestListNotifications_Success tests listing unread notifications with pagination.
+func TestListNotifications_Success(t *testing.T) {
+	mockUseCase := new(mocks.MockNotificationUseCase)
+	app := setupNotificationTestApp(mockUseCase)
+
+	expectedResponse := &model.NotificationListResponse{
+		Notifications: []model.NotificationResponse{
+			{ID: 7, Type: "transaction", ReferenceID: 3, Payload: json.RawMessage(`{"transaction_id":3}`), CreatedAt: time.Now()},
+		},
+		Total: 1,
+		Page:  2,
+		Limit: 5,
+	}
+
+	mockUseCase.On("List", mock.Anything, mock.Anything, true, 2, 5).Return(expectedResponse, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/notifications?unread=true&page=2&limit=5", nil)
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&result)
+
+	data := result["data"].(map[string]interface{})
+	assert.Equal(t, float64(1), data["total"])
+	notification := data["notifications"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, false, notification["is_read"])
+	assert.Equal(t, float64(3), notification["payload"].(map[string]interface{})["transaction_id"])
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestCountUnreadNotifications_Success tests getting the unread notification count.
+func TestCountUnreadNotifications_Success(t *testing.T) {
+	mockUseCase := new(mocks.MockNotificationUseCase)
+	app := setupNotificationTestApp(mockUseCase)
+
+	mockUseCase.On("CountUnread", mock.Anything, mock.Anything).
+		Return(&model.UnreadNotificationCountResponse{UnreadCount: 4}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/notifications/unread-count", nil)
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&result)
+
+	data := result["data"].(map[string]interface{})
+	assert.Equal(t, float64(4), data["unread_count"])
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestMarkNotificationRead_Success tests marking a single notification read.
+func TestMarkNotificationRead_Success(t *testing.T) {
+	mockUseCase := new(mocks.MockNotificationUseCase)
+	app := setupNotificationTestApp(mockUseCase)
+
+	readAt := time.Now()
+	mockUseCase.On("Mark", mock.Anything, mock.Anything, uint(7), true).
+		Return(&model.NotificationResponse{ID: 7, Type: "transaction", IsRead: true, ReadAt: &readAt}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/notifications/7/read", nil)
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&result)
+
+	data := result["data"].(map[string]interface{})
+	assert.Equal(t, true, data["is_read"])
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestMarkNotificationUnread_NotFound tests marking a notification of another user unread.
+func TestMarkNotificationUnread_NotFound(t *testing.T) {
+	mockUseCase := new(mocks.MockNotificationUseCase)
+	app := setupNotificationTestApp(mockUseCase)
+
+	mockUseCase.On("Mark", mock.Anything, mock.Anything, uint(7), false).
+		Return(nil, fiber.NewError(fiber.StatusNotFound, "Notification not found"))
+
+	req := httptest.NewRequest(http.MethodPost, "/notifications/7/unread", nil)
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestMarkNotificationRead_InvalidID tests marking a notification with an invalid ID.
+func TestMarkNotificationRead_InvalidID(t *testing.T) {
+	mockUseCase := new(mocks.MockNotificationUseCase)
+	app := setupNotificationTestApp(mockUseCase)
+
+	req := httptest.NewRequest(http.MethodPost, "/notifications/abc/read", nil)
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+// TestMarkAllNotificationsRead_Success tests marking every notification read.
+func TestMarkAllNotificationsRead_Success(t *testing.T) {
+	mockUseCase := new(mocks.MockNotificationUseCase)
+	app := setupNotificationTestApp(mockUseCase)
+
+	mockUseCase.On("MarkBulk", mock.Anything, mock.Anything, mock.MatchedBy(func(req *model.MarkNotificationsRequest) bool {
+		return req.All && len(req.IDs) == 0
+	}), true).Return(&model.MarkNotificationsResponse{Updated: 3, UnreadCount: 0}, nil)
+
+	body, _ := json.Marshal(map[string]interface{}{"all": true})
+	req := httptest.NewRequest(http.MethodPost, "/notifications/read", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&result)
+
+	data := result["data"].(map[string]interface{})
+	assert.Equal(t, float64(3), data["updated"])
+	assert.Equal(t, float64(0), data["unread_count"])
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestMarkNotificationsUnread_ByIDs tests marking selected notifications unread.
+func TestMarkNotificationsUnread_ByIDs(t *testing.T) {
+	mockUseCase := new(mocks.MockNotificationUseCase)
+	app := setupNotificationTestApp(mockUseCase)
+
+	mockUseCase.On("MarkBulk", mock.Anything, mock.Anything, mock.MatchedBy(func(req *model.MarkNotificationsRequest) bool {
+		return !req.All && len(req.IDs) == 2 && req.IDs[0] == 7 && req.IDs[1] == 8
+	}), false).Return(&model.MarkNotificationsResponse{Updated: 2, UnreadCount: 2}, nil)
+
+	body, _ := json.Marshal(map[string]interface{}{"ids": []uint{7, 8}})
+	req := httptest.NewRequest(http.MethodPost, "/notifications/unread", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestMarkNotificationsRead_InvalidRequest tests bulk marking with an invalid request body.
+func TestMarkNotificationsRead_InvalidRequest(t *testing.T) {
+	mockUseCase := new(mocks.MockNotificationUseCase)
+	app := setupNotificationTestApp(mockUseCase)
+
+	req := httptest.NewRequest(http.MethodPost, "/notifications/read", bytes.NewReader([]byte("invalid json")))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+// TestMarkNotificationsRead_EmptyIDs tests that an empty selection is rejected instead of marking every notification.
+func TestMarkNotificationsRead_EmptyIDs(t *testing.T) {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	// The request is rejected before the database is used
+	notificationUseCase := usecase.NewNotificationUseCase(nil, log, config.NewValidator(), nil)
+
+	app := fiber.New()
+	controller := httpDelivery.NewNotificationController(log, notificationUseCase)
+	app.Use(func(c *fiber.Ctx) error {
+		userID := uint(1)
+		c.Locals("auth", &model.Auth{UserID: &userID, Username: "testuser", Role: "user"})
+		return c.Next()
+	})
+	app.Post("/notifications/read", controller.MarkAllRead)
+
+	for _, body := range []string{`{"ids": []}`, `{"ids": [], "all": false}`} {
+		req := httptest.NewRequest(http.MethodPost, "/notifications/read", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, body)
+	}
+}
@@ -0,0 +1,58 @@
+package controller_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend/internal/delivery/http/middleware"
+	"backend/internal/entity"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPermissionMiddleware tests that a route is only reachable by roles that grant its permission.
+func TestPermissionMiddleware(t *testing.T) {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	requirePermission := middleware.NewPermission(log)
+
+	tests := []struct {
+		name       string
+		role       string
+		permission entity.Permission
+		expected   int
+	}{
+		{name: "super admin tops up", role: entity.RoleSuperAdmin, permission: entity.PermissionTopUpCreate, expected: http.StatusOK},
+		{name: "admin cannot top up", role: entity.RoleAdmin, permission: entity.PermissionTopUpCreate, expected: http.StatusForbidden},
+		{name: "user cannot top up", role: entity.RoleUser, permission: entity.PermissionTopUpCreate, expected: http.StatusForbidden},
+		{name: "admin reads reconciliation", role: entity.RoleAdmin, permission: entity.PermissionReconciliationRead, expected: http.StatusOK},
+		{name: "admin unlocks users", role: entity.RoleAdmin, permission: entity.PermissionUsersUnlock, expected: http.StatusOK},
+		{name: "unknown role", role: "guest", permission: entity.PermissionMetricsRead, expected: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Use(authenticateAs(1, tt.role))
+			app.Post("/protected", requirePermission(tt.permission), func(c *fiber.Ctx) error {
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/protected", nil))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, resp.StatusCode)
+		})
+	}
+}
+
+// TestRolesWithPermission tests looking up the roles that grant a permission.
+func TestRolesWithPermission(t *testing.T) {
+	assert.Equal(t, []string{entity.RoleAdmin, entity.RoleSuperAdmin}, entity.RolesWithPermission(entity.PermissionReconciliationRead))
+	assert.Equal(t, []string{entity.RoleSuperAdmin}, entity.RolesWithPermission(entity.PermissionWebhooksAllEvents))
+	assert.Equal(t, []string{entity.RoleSuperAdmin}, entity.RolesWithPermission(entity.PermissionTopUpCreate))
+	assert.Empty(t, entity.RolesWithPermission("unknown:action"))
+}
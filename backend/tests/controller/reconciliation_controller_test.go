@@ -0,0 +1,114 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	httpDelivery "backend/internal/delivery/http"
+	"backend/internal/model"
+	"backend/tests/mocks"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// setupReconciliationTestApp creates a Fiber app with ReconciliationController for testing.
+func setupReconciliationTestApp(mockUseCase *mocks.MockReconciliationUseCase, role string) *fiber.App {
+	app := fiber.New()
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	controller := httpDelivery.NewReconciliationController(log, mockUseCase)
+
+	// Middleware to set auth context for testing
+	app.Use(func(c *fiber.Ctx) error {
+		userID := uint(1)
+		auth := &model.Auth{
+			UserID:   &userID,
+			Username: "admin",
+			Role:     role,
+		}
+		c.Locals("auth", auth)
+		return c.Next()
+	})
+
+	app.Get("/admin/reconciliation", controller.GetReport)
+
+	return app
+}
+
+// TestGetReconciliationReport_WithDiscrepancies tests the report with a broken wallet.
+func TestGetReconciliationReport_WithDiscrepancies(t *testing.T) {
+	mockUseCase := new(mocks.MockReconciliationUseCase)
+	app := setupReconciliationTestApp(mockUseCase, "super_admin")
+
+	latest := decimal.NewFromInt(100000)
+	expectedResponse := &model.ReconciliationReport{
+		GeneratedAt:         time.Now(),
+		Consistent:          false,
+		WalletsChecked:      3,
+		TransactionsChecked: 5,
+		TotalBalance:        decimal.NewFromInt(50000),
+		Wallets: []model.WalletReconciliation{
+			{
+				WalletID:           2,
+				UserID:             2,
+				Balance:            decimal.NewFromInt(150000),
+				LatestBalanceAfter: &latest,
+				Discrepancies: []model.ReconciliationDiscrepancy{
+					{
+						Code:     model.DiscrepancyBalanceMismatch,
+						Expected: latest,
+						Actual:   decimal.NewFromInt(150000),
+						Message:  "wallet balance does not match the latest balance_after",
+					},
+				},
+			},
+		},
+		Transactions: []model.TransactionReconciliation{},
+	}
+
+	mockUseCase.On("GetReport", mock.Anything, mock.Anything).Return(expectedResponse, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/reconciliation", nil)
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&result)
+
+	data := result["data"].(map[string]interface{})
+	assert.Equal(t, false, data["consistent"])
+	wallets := data["wallets"].([]interface{})
+	assert.Len(t, wallets, 1)
+	discrepancies := wallets[0].(map[string]interface{})["discrepancies"].([]interface{})
+	assert.Equal(t, "balance_mismatch", discrepancies[0].(map[string]interface{})["code"])
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestGetReconciliationReport_Forbidden tests the report requested by a regular user.
+func TestGetReconciliationReport_Forbidden(t *testing.T) {
+	mockUseCase := new(mocks.MockReconciliationUseCase)
+	app := setupReconciliationTestApp(mockUseCase, "user")
+
+	mockUseCase.On("GetReport", mock.Anything, mock.Anything).
+		Return(nil, fiber.NewError(fiber.StatusForbidden, "Only super admin can run reconciliation"))
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/reconciliation", nil)
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	mockUseCase.AssertExpectations(t)
+}
@@ -0,0 +1,132 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	httpDelivery "backend/internal/delivery/http"
+	"backend/internal/model"
+	"backend/tests/mocks"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// setupSessionTestApp creates a Fiber app with SessionController for testing.
+func setupSessionTestApp(mockUseCase *mocks.MockSessionUseCase) *fiber.App {
+	app := fiber.New()
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	controller := httpDelivery.NewSessionController(log, mockUseCase)
+
+	// Middleware to set auth context for testing
+	app.Use(func(c *fiber.Ctx) error {
+		userID := uint(1)
+		auth := &model.Auth{
+			UserID:   &userID,
+			Username: "testuser",
+			Role:     "user",
+			FamilyID: "current",
+		}
+		c.Locals("auth", auth)
+		return c.Next()
+	})
+
+	app.Get("/users/sessions", controller.List)
+	app.Delete("/users/sessions/others", controller.RevokeOthers)
+	app.Delete("/users/sessions/:id", controller.Revoke)
+
+	return app
+}
+
+// TestListSessions_Success tests listing the active sessions of the current user.
+func TestListSessions_Success(t *testing.T) {
+	mockUseCase := new(mocks.MockSessionUseCase)
+	app := setupSessionTestApp(mockUseCase)
+
+	expectedResponse := []model.SessionResponse{
+		{ID: "current", Device: "Chrome on Windows", IPAddress: "10.0.0.1", LastSeenAt: time.Now(), Current: true},
+		{ID: "phone", Device: "Safari on iOS", IPAddress: "10.0.0.2", LastSeenAt: time.Now().Add(-time.Hour)},
+	}
+
+	mockUseCase.On("List", mock.Anything, mock.Anything).Return(expectedResponse, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/users/sessions", nil)
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&result)
+
+	data := result["data"].([]interface{})
+	assert.Len(t, data, 2)
+	assert.Equal(t, true, data[0].(map[string]interface{})["current"])
+	assert.Equal(t, "Safari on iOS", data[1].(map[string]interface{})["device"])
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestRevokeSession_Success tests revoking a session of the current user.
+func TestRevokeSession_Success(t *testing.T) {
+	mockUseCase := new(mocks.MockSessionUseCase)
+	app := setupSessionTestApp(mockUseCase)
+
+	mockUseCase.On("Revoke", mock.Anything, mock.Anything, "phone").Return(nil)
+
+	req := httptest.NewRequest(http.MethodDelete, "/users/sessions/phone", nil)
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestRevokeSession_NotFound tests revoking a session that does not belong to the current user.
+func TestRevokeSession_NotFound(t *testing.T) {
+	mockUseCase := new(mocks.MockSessionUseCase)
+	app := setupSessionTestApp(mockUseCase)
+
+	mockUseCase.On("Revoke", mock.Anything, mock.Anything, "unknown").
+		Return(fiber.NewError(fiber.StatusNotFound, "Session not found"))
+
+	req := httptest.NewRequest(http.MethodDelete, "/users/sessions/unknown", nil)
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestRevokeOtherSessions_Success tests revoking every other session of the current user.
+func TestRevokeOtherSessions_Success(t *testing.T) {
+	mockUseCase := new(mocks.MockSessionUseCase)
+	app := setupSessionTestApp(mockUseCase)
+
+	mockUseCase.On("RevokeOthers", mock.Anything, mock.Anything).
+		Return(&model.RevokeSessionsResponse{Revoked: 2}, nil)
+
+	req := httptest.NewRequest(http.MethodDelete, "/users/sessions/others", nil)
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&result)
+
+	data := result["data"].(map[string]interface{})
+	assert.Equal(t, float64(2), data["revoked"])
+
+	mockUseCase.AssertExpectations(t)
+}
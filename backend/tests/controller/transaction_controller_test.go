@@ -42,6 +42,9 @@ func setupTransactionTestApp(mockUseCase *mocks.MockTransactionUseCase, role str
 
 	app.Post("/transactions/topup", controller.TopUp)
 	app.Post("/transactions/transfer", controller.Transfer)
+	app.Post("/transactions/withdraw", controller.Withdraw)
+	app.Post("/transactions/:id/settle", controller.SettleWithdraw)
+	app.Post("/transactions/:id/reverse", controller.Reverse)
 	app.Get("/transactions", controller.GetMyTransactions)
 
 	return app
@@ -57,7 +60,7 @@ func TestTopUp_Success(t *testing.T) {
 		ID:                1,
 		Type:              "top_up",
 		Amount:            decimal.NewFromInt(100000),
-		ToWalletID:        toWalletID,
+		ToWalletID:        &toWalletID,
 		PerformedByUserID: 1,
 		Status:            "completed",
 		CreatedAt:         time.Now(),
@@ -139,19 +142,20 @@ func TestTransfer_Success(t *testing.T) {
 		Type:              "transfer",
 		Amount:            decimal.NewFromInt(50000),
 		FromWalletID:      &fromWalletID,
-		ToWalletID:        toWalletID,
+		ToWalletID:        &toWalletID,
 		PerformedByUserID: 1,
 		Status:            "completed",
 		CreatedAt:         time.Now(),
 	}
 
 	mockUseCase.On("Transfer", mock.Anything, mock.Anything, mock.MatchedBy(func(req *model.TransferRequest) bool {
-		return req.ToUserID == 2 && req.Amount.Equal(decimal.NewFromInt(50000))
+		return req.ToUserID == 2 && req.Amount.Equal(decimal.NewFromInt(50000)) && req.PIN == "123456"
 	})).Return(expectedResponse, nil)
 
 	reqBody := map[string]interface{}{
 		"to_user_id": 2,
 		"amount":     50000,
+		"pin":        "123456",
 	}
 	body, _ := json.Marshal(reqBody)
 
@@ -172,6 +176,29 @@ func TestTransfer_Success(t *testing.T) {
 	mockUseCase.AssertExpectations(t)
 }
 
+// TestTransfer_InvalidPIN tests that a wrong transaction PIN rejects the transfer.
+func TestTransfer_InvalidPIN(t *testing.T) {
+	mockUseCase := new(mocks.MockTransactionUseCase)
+	app := setupTransactionTestApp(mockUseCase, "user")
+
+	mockUseCase.On("Transfer", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, fiber.NewError(fiber.StatusForbidden, "Invalid transaction PIN, 4 attempts left"))
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"to_user_id": 2,
+		"amount":     50000,
+		"pin":        "000000",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/transactions/transfer", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	mockUseCase.AssertExpectations(t)
+}
+
 // TestTransfer_InvalidRequest tests transfer with invalid request body.
 func TestTransfer_InvalidRequest(t *testing.T) {
 	mockUseCase := new(mocks.MockTransactionUseCase)
@@ -278,3 +305,257 @@ func TestGetMyTransactions_WithPagination(t *testing.T) {
 
 	mockUseCase.AssertExpectations(t)
 }
+
+// TestTransfer_IdempotencyKeyForwarded tests that the Idempotency-Key header is passed to the use case.
+func TestTransfer_IdempotencyKeyForwarded(t *testing.T) {
+	mockUseCase := new(mocks.MockTransactionUseCase)
+	app := setupTransactionTestApp(mockUseCase, "user")
+
+	toWalletID := uint(2)
+	expectedResponse := &model.TransactionResponse{
+		ID:                1,
+		Type:              "transfer",
+		Amount:            decimal.NewFromInt(50000),
+		ToWalletID:        &toWalletID,
+		PerformedByUserID: 1,
+		Status:            "completed",
+		CreatedAt:         time.Now(),
+	}
+
+	mockUseCase.On("Transfer", mock.Anything, mock.Anything, mock.MatchedBy(func(req *model.TransferRequest) bool {
+		return req.IdempotencyKey == "c0ffee-1234"
+	})).Return(expectedResponse, nil)
+
+	reqBody := map[string]interface{}{
+		"to_user_id": 2,
+		"amount":     50000,
+	}
+	body, _ := json.Marshal(reqBody)
+
+	req := httptest.NewRequest(http.MethodPost, "/transactions/transfer", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", "c0ffee-1234")
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestTopUp_IdempotencyKeyConflict tests top-up when the idempotency key was used with a different payload.
+func TestTopUp_IdempotencyKeyConflict(t *testing.T) {
+	mockUseCase := new(mocks.MockTransactionUseCase)
+	app := setupTransactionTestApp(mockUseCase, "super_admin")
+
+	mockUseCase.On("TopUp", mock.Anything, mock.Anything, mock.MatchedBy(func(req *model.TopUpRequest) bool {
+		return req.IdempotencyKey == "c0ffee-1234"
+	})).Return(nil, fiber.NewError(fiber.StatusConflict, "Idempotency key already used with a different request"))
+
+	reqBody := map[string]interface{}{
+		"to_user_id": 2,
+		"amount":     100000,
+	}
+	body, _ := json.Marshal(reqBody)
+
+	req := httptest.NewRequest(http.MethodPost, "/transactions/topup", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", "c0ffee-1234")
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestWithdraw_Success tests successful withdraw request creating a pending transaction.
+func TestWithdraw_Success(t *testing.T) {
+	mockUseCase := new(mocks.MockTransactionUseCase)
+	app := setupTransactionTestApp(mockUseCase, "user")
+
+	fromWalletID := uint(1)
+	expectedResponse := &model.TransactionResponse{
+		ID:                3,
+		Type:              "withdraw",
+		Amount:            decimal.NewFromInt(25000),
+		FromWalletID:      &fromWalletID,
+		PerformedByUserID: 1,
+		Status:            "pending",
+		CreatedAt:         time.Now(),
+	}
+
+	mockUseCase.On("Withdraw", mock.Anything, mock.Anything, mock.MatchedBy(func(req *model.WithdrawRequest) bool {
+		return req.Amount.Equal(decimal.NewFromInt(25000))
+	})).Return(expectedResponse, nil)
+
+	reqBody := map[string]interface{}{
+		"amount": 25000,
+	}
+	body, _ := json.Marshal(reqBody)
+
+	req := httptest.NewRequest(http.MethodPost, "/transactions/withdraw", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var result map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&result)
+
+	data := result["data"].(map[string]interface{})
+	assert.Equal(t, "withdraw", data["type"])
+	assert.Equal(t, "pending", data["status"])
+	assert.NotContains(t, data, "to_wallet_id")
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestWithdraw_InsufficientBalance tests withdraw when available balance is insufficient.
+func TestWithdraw_InsufficientBalance(t *testing.T) {
+	mockUseCase := new(mocks.MockTransactionUseCase)
+	app := setupTransactionTestApp(mockUseCase, "user")
+
+	mockUseCase.On("Withdraw", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, fiber.NewError(fiber.StatusBadRequest, "Insufficient balance"))
+
+	reqBody := map[string]interface{}{
+		"amount": 1000000,
+	}
+	body, _ := json.Marshal(reqBody)
+
+	req := httptest.NewRequest(http.MethodPost, "/transactions/withdraw", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestSettleWithdraw_Success tests completing a pending withdraw by super admin.
+func TestSettleWithdraw_Success(t *testing.T) {
+	mockUseCase := new(mocks.MockTransactionUseCase)
+	app := setupTransactionTestApp(mockUseCase, "super_admin")
+
+	fromWalletID := uint(4)
+	expectedResponse := &model.TransactionResponse{
+		ID:                3,
+		Type:              "withdraw",
+		Amount:            decimal.NewFromInt(25000),
+		FromWalletID:      &fromWalletID,
+		PerformedByUserID: 4,
+		Status:            "completed",
+		CreatedAt:         time.Now(),
+	}
+
+	mockUseCase.On("SettleWithdraw", mock.Anything, mock.Anything, mock.MatchedBy(func(req *model.SettleWithdrawRequest) bool {
+		return req.TransactionID == 3 && req.Status == "completed"
+	})).Return(expectedResponse, nil)
+
+	reqBody := map[string]interface{}{
+		"status": "completed",
+	}
+	body, _ := json.Marshal(reqBody)
+
+	req := httptest.NewRequest(http.MethodPost, "/transactions/3/settle", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&result)
+
+	data := result["data"].(map[string]interface{})
+	assert.Equal(t, "completed", data["status"])
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestSettleWithdraw_InvalidID tests settling a withdraw with a non-numeric transaction ID.
+func TestSettleWithdraw_InvalidID(t *testing.T) {
+	mockUseCase := new(mocks.MockTransactionUseCase)
+	app := setupTransactionTestApp(mockUseCase, "super_admin")
+
+	body, _ := json.Marshal(map[string]interface{}{"status": "failed"})
+
+	req := httptest.NewRequest(http.MethodPost, "/transactions/abc/settle", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	mockUseCase.AssertNotCalled(t, "SettleWithdraw", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestReverse_PartialSuccess tests partially reversing a transfer by super admin.
+func TestReverse_PartialSuccess(t *testing.T) {
+	mockUseCase := new(mocks.MockTransactionUseCase)
+	app := setupTransactionTestApp(mockUseCase, "super_admin")
+
+	fromWalletID := uint(2)
+	toWalletID := uint(1)
+	reversedTransactionID := uint(7)
+	expectedResponse := &model.TransactionResponse{
+		ID:                    8,
+		Type:                  "reversal",
+		Amount:                decimal.NewFromInt(10000),
+		FromWalletID:          &fromWalletID,
+		ToWalletID:            &toWalletID,
+		PerformedByUserID:     1,
+		ReversedTransactionID: &reversedTransactionID,
+		Status:                "completed",
+		CreatedAt:             time.Now(),
+	}
+
+	mockUseCase.On("Reverse", mock.Anything, mock.Anything, mock.MatchedBy(func(req *model.ReverseTransactionRequest) bool {
+		return req.TransactionID == 7 && req.Amount.Equal(decimal.NewFromInt(10000))
+	})).Return(expectedResponse, nil)
+
+	reqBody := map[string]interface{}{
+		"amount":      10000,
+		"description": "Salah kirim",
+	}
+	body, _ := json.Marshal(reqBody)
+
+	req := httptest.NewRequest(http.MethodPost, "/transactions/7/reverse", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var result map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&result)
+
+	data := result["data"].(map[string]interface{})
+	assert.Equal(t, "reversal", data["type"])
+	assert.Equal(t, float64(7), data["reversed_transaction_id"])
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestReverse_ExceedsOriginalAmount tests reversing more than the original amount.
+func TestReverse_ExceedsOriginalAmount(t *testing.T) {
+	mockUseCase := new(mocks.MockTransactionUseCase)
+	app := setupTransactionTestApp(mockUseCase, "super_admin")
+
+	mockUseCase.On("Reverse", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, fiber.NewError(fiber.StatusBadRequest, "Amount exceeds reversible amount of 50000"))
+
+	body, _ := json.Marshal(map[string]interface{}{"amount": 999999})
+
+	req := httptest.NewRequest(http.MethodPost, "/transactions/7/reverse", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	mockUseCase.AssertExpectations(t)
+}
@@ -0,0 +1,127 @@
+package controller_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	httpDelivery "backend/internal/delivery/http"
+	"backend/internal/model"
+	"backend/tests/mocks"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// setupTransactionPINTestApp creates a Fiber app with TransactionPINController for testing.
+func setupTransactionPINTestApp(mockUseCase *mocks.MockTransactionPINUseCase) *fiber.App {
+	app := fiber.New()
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	controller := httpDelivery.NewTransactionPINController(log, mockUseCase)
+
+	app.Use(authenticateAs(1, "user"))
+	app.Get("/users/me/pin", controller.Status)
+	app.Post("/users/me/pin", controller.Set)
+	app.Put("/users/me/pin", controller.Change)
+	app.Post("/users/me/pin/reset", controller.Reset)
+
+	return app
+}
+
+// TestTransactionPINStatus_Locked tests the status of a PIN locked after too many wrong attempts.
+func TestTransactionPINStatus_Locked(t *testing.T) {
+	mockUseCase := new(mocks.MockTransactionPINUseCase)
+	app := setupTransactionPINTestApp(mockUseCase)
+
+	lockedUntil := time.Now().Add(10 * time.Minute)
+	mockUseCase.On("Status", mock.Anything, mock.Anything).
+		Return(&model.TransactionPINStatusResponse{IsSet: true, LockedUntil: &lockedUntil}, nil)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/users/me/pin", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&result)
+
+	data := result["data"].(map[string]interface{})
+	assert.Equal(t, true, data["is_set"])
+	assert.NotEmpty(t, data["locked_until"])
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestTransactionPINSet_Success tests setting the first PIN.
+func TestTransactionPINSet_Success(t *testing.T) {
+	mockUseCase := new(mocks.MockTransactionPINUseCase)
+	app := setupTransactionPINTestApp(mockUseCase)
+
+	mockUseCase.On("Set", mock.Anything, mock.Anything, mock.MatchedBy(func(req *model.TransactionPINRequest) bool {
+		return req.Password == "password123" && req.PIN == "123456"
+	})).Return(nil)
+
+	resp, err := app.Test(postJSON("/users/me/pin", map[string]string{"password": "password123", "pin": "123456"}))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestTransactionPINSet_AlreadySet tests that a PIN cannot be set twice.
+func TestTransactionPINSet_AlreadySet(t *testing.T) {
+	mockUseCase := new(mocks.MockTransactionPINUseCase)
+	app := setupTransactionPINTestApp(mockUseCase)
+
+	mockUseCase.On("Set", mock.Anything, mock.Anything, mock.Anything).
+		Return(fiber.NewError(fiber.StatusConflict, "Transaction PIN is already set"))
+
+	resp, err := app.Test(postJSON("/users/me/pin", map[string]string{"password": "password123", "pin": "123456"}))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestTransactionPINChange_Locked tests changing a PIN that is locked.
+func TestTransactionPINChange_Locked(t *testing.T) {
+	mockUseCase := new(mocks.MockTransactionPINUseCase)
+	app := setupTransactionPINTestApp(mockUseCase)
+
+	mockUseCase.On("Change", mock.Anything, mock.Anything, mock.MatchedBy(func(req *model.ChangeTransactionPINRequest) bool {
+		return req.CurrentPIN == "123456" && req.NewPIN == "654321"
+	})).Return(fiber.NewError(fiber.StatusTooManyRequests, "Transaction PIN is locked"))
+
+	body, _ := json.Marshal(map[string]string{"current_pin": "123456", "new_pin": "654321"})
+	req := httptest.NewRequest(http.MethodPut, "/users/me/pin", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestTransactionPINReset_Success tests resetting a forgotten PIN with the password.
+func TestTransactionPINReset_Success(t *testing.T) {
+	mockUseCase := new(mocks.MockTransactionPINUseCase)
+	app := setupTransactionPINTestApp(mockUseCase)
+
+	mockUseCase.On("Reset", mock.Anything, mock.Anything, mock.MatchedBy(func(req *model.TransactionPINRequest) bool {
+		return req.Password == "password123" && req.PIN == "654321"
+	})).Return(nil)
+
+	resp, err := app.Test(postJSON("/users/me/pin/reset", map[string]string{"password": "password123", "pin": "654321"}))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	mockUseCase.AssertExpectations(t)
+}
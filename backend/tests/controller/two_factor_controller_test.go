@@ -0,0 +1,216 @@
+package controller_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	httpDelivery "backend/internal/delivery/http"
+	"backend/internal/delivery/http/middleware"
+	"backend/internal/model"
+	"backend/internal/usecase"
+	"backend/tests/mocks"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// setupTwoFactorTestApp creates a Fiber app with TwoFactorController for testing.
+func setupTwoFactorTestApp(mockUseCase *mocks.MockTwoFactorUseCase) *fiber.App {
+	app := fiber.New()
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	controller := httpDelivery.NewTwoFactorController(log, viper.New(), mockUseCase)
+
+	app.Post("/users/login/2fa", controller.Login)
+	app.Get("/users/me/2fa", authenticateAs(1, "user"), controller.Status)
+	app.Post("/users/me/2fa/setup", authenticateAs(1, "user"), controller.Setup)
+	app.Post("/users/me/2fa/confirm", authenticateAs(1, "user"), controller.Confirm)
+	app.Post("/users/me/2fa/recovery-codes", authenticateAs(1, "user"), controller.RegenerateRecoveryCodes)
+	app.Post("/users/me/2fa/disable", authenticateAs(1, "user"), controller.Disable)
+
+	return app
+}
+
+func postJSON(path string, payload interface{}) *http.Request {
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+// TestTwoFactorSetup_Success tests starting enrollment.
+func TestTwoFactorSetup_Success(t *testing.T) {
+	mockUseCase := new(mocks.MockTwoFactorUseCase)
+	app := setupTwoFactorTestApp(mockUseCase)
+
+	mockUseCase.On("Setup", mock.Anything, mock.MatchedBy(func(auth *model.Auth) bool {
+		return *auth.UserID == 1
+	})).Return(&model.TwoFactorSetupResponse{
+		Secret:          "JBSWY3DPEHPK3PXP",
+		ProvisioningURI: "otpauth://totp/Wallet%20App:testuser?secret=JBSWY3DPEHPK3PXP",
+	}, nil)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/users/me/2fa/setup", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&result)
+
+	data := result["data"].(map[string]interface{})
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", data["secret"])
+	assert.Contains(t, data["provisioning_uri"], "otpauth://totp/")
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestTwoFactorConfirm_Success tests that confirming returns the recovery codes.
+func TestTwoFactorConfirm_Success(t *testing.T) {
+	mockUseCase := new(mocks.MockTwoFactorUseCase)
+	app := setupTwoFactorTestApp(mockUseCase)
+
+	mockUseCase.On("Confirm", mock.Anything, mock.Anything, mock.MatchedBy(func(req *model.TwoFactorCodeRequest) bool {
+		return req.Code == "123456"
+	})).Return(&model.RecoveryCodesResponse{RecoveryCodes: []string{"abcde-fghjk", "mnpqr-stuvw"}}, nil)
+
+	resp, err := app.Test(postJSON("/users/me/2fa/confirm", map[string]string{"code": "123456"}))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&result)
+
+	data := result["data"].(map[string]interface{})
+	assert.Len(t, data["recovery_codes"], 2)
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestTwoFactorConfirm_InvalidCode tests confirming with a wrong code.
+func TestTwoFactorConfirm_InvalidCode(t *testing.T) {
+	mockUseCase := new(mocks.MockTwoFactorUseCase)
+	app := setupTwoFactorTestApp(mockUseCase)
+
+	mockUseCase.On("Confirm", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, fiber.NewError(fiber.StatusBadRequest, "Invalid two-factor code"))
+
+	resp, err := app.Test(postJSON("/users/me/2fa/confirm", map[string]string{"code": "000000"}))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestTwoFactorConfirm_Locked tests confirming after too many wrong codes.
+func TestTwoFactorConfirm_Locked(t *testing.T) {
+	mockUseCase := new(mocks.MockTwoFactorUseCase)
+	app := setupTwoFactorTestApp(mockUseCase)
+
+	mockUseCase.On("Confirm", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, usecase.NewCodedError(fiber.StatusTooManyRequests, usecase.ErrorCodeTwoFactorLocked, "Too many wrong two-factor codes, try again in 15m0s", 15*time.Minute))
+
+	resp, err := app.Test(postJSON("/users/me/2fa/confirm", map[string]string{"code": "000000"}))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestTwoFactorDisable_RequiredRole tests that a role that requires two-factor authentication cannot disable it.
+func TestTwoFactorDisable_RequiredRole(t *testing.T) {
+	mockUseCase := new(mocks.MockTwoFactorUseCase)
+	app := setupTwoFactorTestApp(mockUseCase)
+
+	mockUseCase.On("Disable", mock.Anything, mock.Anything, mock.MatchedBy(func(req *model.TwoFactorDisableRequest) bool {
+		return req.Password == "password123" && req.Code == "123456"
+	})).Return(fiber.NewError(fiber.StatusForbidden, "Two-factor authentication is required for your role"))
+
+	resp, err := app.Test(postJSON("/users/me/2fa/disable", map[string]string{"password": "password123", "code": "123456"}))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestTwoFactorLogin_Success tests that completing a challenge returns tokens and sets the cookies.
+func TestTwoFactorLogin_Success(t *testing.T) {
+	mockUseCase := new(mocks.MockTwoFactorUseCase)
+	app := setupTwoFactorTestApp(mockUseCase)
+
+	mockUseCase.On("Login", mock.Anything, mock.MatchedBy(func(req *model.TwoFactorLoginRequest) bool {
+		return req.ChallengeToken == "challenge-token" && req.Code == "abcde-fghjk" && req.IPAddress != ""
+	})).Return(&model.UserResponse{ID: 1, Username: "testuser", Token: "access-token", RefreshToken: "refresh-token"}, nil)
+
+	resp, err := app.Test(postJSON("/users/login/2fa", map[string]string{"challenge_token": "challenge-token", "code": "abcde-fghjk"}))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	cookies := map[string]string{}
+	for _, cookie := range resp.Cookies() {
+		cookies[cookie.Name] = cookie.Value
+	}
+	assert.Equal(t, "access-token", cookies["jwt"])
+	assert.Equal(t, "refresh-token", cookies["refresh_token"])
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestTwoFactorLogin_InvalidChallenge tests completing an expired or unknown challenge.
+func TestTwoFactorLogin_InvalidChallenge(t *testing.T) {
+	mockUseCase := new(mocks.MockTwoFactorUseCase)
+	app := setupTwoFactorTestApp(mockUseCase)
+
+	mockUseCase.On("Login", mock.Anything, mock.Anything).
+		Return(nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired login challenge"))
+
+	resp, err := app.Test(postJSON("/users/login/2fa", map[string]string{"challenge_token": "expired", "code": "123456"}))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestTwoFactorRequired tests that the middleware only lets sessions of required roles through after two-factor authentication.
+func TestTwoFactorRequired(t *testing.T) {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	tests := []struct {
+		name      string
+		role      string
+		twoFactor bool
+		expected  int
+	}{
+		{name: "super admin without two-factor", role: "super_admin", expected: http.StatusForbidden},
+		{name: "super admin with two-factor", role: "super_admin", twoFactor: true, expected: http.StatusOK},
+		{name: "user without two-factor", role: "user", expected: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Use(func(c *fiber.Ctx) error {
+				userID := uint(1)
+				c.Locals("auth", &model.Auth{UserID: &userID, Role: tt.role, TwoFactor: tt.twoFactor})
+				return c.Next()
+			})
+			app.Use(middleware.NewTwoFactorRequired(log, []string{"super_admin"}))
+			app.Get("/admin/reconciliation", func(c *fiber.Ctx) error {
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/admin/reconciliation", nil))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, resp.StatusCode)
+		})
+	}
+}
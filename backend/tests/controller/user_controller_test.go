@@ -7,9 +7,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"backend/internal/config"
 	httpDelivery "backend/internal/delivery/http"
 	"backend/internal/model"
+	"backend/internal/usecase"
 	"backend/tests/mocks"
 
 	"github.com/gofiber/fiber/v2"
@@ -29,10 +32,25 @@ func setupUserTestApp(mockUseCase *mocks.MockUserUseCase) *fiber.App {
 
 	app.Post("/users/register", controller.Register)
 	app.Post("/users/login", controller.Login)
+	app.Post("/users/refresh", controller.Refresh)
+	app.Post("/users/password-reset", controller.ResetPassword)
+	app.Post("/users/logout", authenticateAs(1, "user"), controller.Logout)
+	app.Put("/users/me/password", authenticateAs(1, "user"), controller.ChangePassword)
+	app.Post("/admin/users/:id/password-reset", authenticateAs(9, "admin"), controller.IssuePasswordReset)
+	app.Post("/admin/users/:id/unlock", authenticateAs(9, "admin"), controller.Unlock)
 
 	return app
 }
 
+// authenticateAs sets the auth context of the current session for testing.
+func authenticateAs(userID uint, role string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals("auth", &model.Auth{UserID: &userID, Username: "testuser", Role: role, FamilyID: "current"})
+		c.Locals("token", "access-token")
+		return c.Next()
+	}
+}
+
 // TestRegister_Success tests successful user registration.
 func TestRegister_Success(t *testing.T) {
 	mockUseCase := new(mocks.MockUserUseCase)
@@ -114,10 +132,12 @@ func TestLogin_Success(t *testing.T) {
 	mockUseCase := new(mocks.MockUserUseCase)
 	app := setupUserTestApp(mockUseCase)
 
-	expectedResponse := &model.UserResponse{
-		ID:       1,
-		Username: "testuser",
-		Token:    "jwt-token-here",
+	expectedResponse := &model.LoginResponse{
+		UserResponse: &model.UserResponse{
+			ID:       1,
+			Username: "testuser",
+			Token:    "jwt-token-here",
+		},
 	}
 
 	mockUseCase.On("Login", mock.Anything, mock.MatchedBy(func(req *model.UserLoginRequest) bool {
@@ -144,6 +164,42 @@ func TestLogin_Success(t *testing.T) {
 	assert.Equal(t, float64(1), data["id"])
 	assert.Equal(t, "testuser", data["username"])
 	assert.Equal(t, "jwt-token-here", data["token"])
+	assert.Equal(t, false, data["two_factor_required"])
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestLogin_TwoFactorRequired tests that a login with two-factor authentication returns a challenge without cookies.
+func TestLogin_TwoFactorRequired(t *testing.T) {
+	mockUseCase := new(mocks.MockUserUseCase)
+	app := setupUserTestApp(mockUseCase)
+
+	expiresAt := time.Now().Add(5 * time.Minute)
+	mockUseCase.On("Login", mock.Anything, mock.Anything).Return(&model.LoginResponse{
+		TwoFactorRequired:  true,
+		ChallengeToken:     "challenge-token",
+		ChallengeExpiresAt: &expiresAt,
+	}, nil)
+
+	body, _ := json.Marshal(map[string]string{
+		"username": "testuser",
+		"password": "password123",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/users/login", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, resp.Cookies())
+
+	var result map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&result)
+
+	data := result["data"].(map[string]interface{})
+	assert.Equal(t, true, data["two_factor_required"])
+	assert.Equal(t, "challenge-token", data["challenge_token"])
+	assert.NotContains(t, data, "token")
 
 	mockUseCase.AssertExpectations(t)
 }
@@ -184,3 +240,367 @@ func TestLogin_InvalidCredentials(t *testing.T) {
 
 	mockUseCase.AssertExpectations(t)
 }
+
+// TestLogin_AccountLocked tests that a lockout reaches the client with its error code and Retry-After.
+func TestLogin_AccountLocked(t *testing.T) {
+	mockUseCase := new(mocks.MockUserUseCase)
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	controller := httpDelivery.NewUserController(log, viper.New(), mockUseCase)
+
+	app := fiber.New(fiber.Config{ErrorHandler: config.NewErrorHandler()})
+	app.Post("/users/login", controller.Login)
+
+	mockUseCase.On("Login", mock.Anything, mock.Anything).
+		Return(nil, usecase.NewCodedError(fiber.StatusTooManyRequests, usecase.ErrorCodeAccountLocked, "Account is locked after too many failed logins, try again in 15m0s", 15*time.Minute))
+
+	body, _ := json.Marshal(map[string]string{
+		"username": "testuser",
+		"password": "wrongpassword",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/users/login", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "900", resp.Header.Get(fiber.HeaderRetryAfter))
+
+	var response map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&response)
+	assert.Equal(t, usecase.ErrorCodeAccountLocked, response["code"])
+	assert.Equal(t, float64(900), response["retry_after"])
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestRefresh_Success tests rotating a refresh token sent in the request body.
+func TestRefresh_Success(t *testing.T) {
+	mockUseCase := new(mocks.MockUserUseCase)
+	app := setupUserTestApp(mockUseCase)
+
+	expectedResponse := &model.UserResponse{
+		ID:                    1,
+		Username:              "testuser",
+		Token:                 "new-access-token",
+		TokenExpiresAt:        time.Now().Add(15 * time.Minute),
+		RefreshToken:          "new-refresh-token",
+		RefreshTokenExpiresAt: time.Now().Add(720 * time.Hour),
+	}
+
+	mockUseCase.On("Refresh", mock.Anything, mock.MatchedBy(func(req *model.RefreshTokenRequest) bool {
+		return req.RefreshToken == "old-refresh-token"
+	})).Return(expectedResponse, nil)
+
+	body, _ := json.Marshal(map[string]string{"refresh_token": "old-refresh-token"})
+
+	req := httptest.NewRequest(http.MethodPost, "/users/refresh", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	cookies := map[string]*http.Cookie{}
+	for _, cookie := range resp.Cookies() {
+		cookies[cookie.Name] = cookie
+	}
+	if assert.Contains(t, cookies, "jwt") && assert.Contains(t, cookies, "refresh_token") {
+		assert.Equal(t, "new-access-token", cookies["jwt"].Value)
+		assert.Equal(t, "new-refresh-token", cookies["refresh_token"].Value)
+		assert.Equal(t, "/users", cookies["refresh_token"].Path)
+	}
+
+	var result map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&result)
+
+	data := result["data"].(map[string]interface{})
+	assert.Equal(t, "new-access-token", data["token"])
+	assert.Equal(t, "new-refresh-token", data["refresh_token"])
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestRefresh_FromCookie tests rotating a refresh token sent in the refresh_token cookie.
+func TestRefresh_FromCookie(t *testing.T) {
+	mockUseCase := new(mocks.MockUserUseCase)
+	app := setupUserTestApp(mockUseCase)
+
+	mockUseCase.On("Refresh", mock.Anything, mock.MatchedBy(func(req *model.RefreshTokenRequest) bool {
+		return req.RefreshToken == "cookie-refresh-token"
+	})).Return(&model.UserResponse{ID: 1, Username: "testuser", Token: "new-access-token", RefreshToken: "new-refresh-token"}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/users/refresh", nil)
+	req.Header.Set("Cookie", "refresh_token=cookie-refresh-token")
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestRefresh_ReusedToken tests that a rejected refresh token returns unauthorized.
+func TestRefresh_ReusedToken(t *testing.T) {
+	mockUseCase := new(mocks.MockUserUseCase)
+	app := setupUserTestApp(mockUseCase)
+
+	mockUseCase.On("Refresh", mock.Anything, mock.Anything).
+		Return(nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid refresh token"))
+
+	body, _ := json.Marshal(map[string]string{"refresh_token": "rotated-refresh-token"})
+
+	req := httptest.NewRequest(http.MethodPost, "/users/refresh", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestLogout_RevokesSession tests that logout ends the session on the server and clears the cookies.
+func TestLogout_RevokesSession(t *testing.T) {
+	mockUseCase := new(mocks.MockUserUseCase)
+	app := setupUserTestApp(mockUseCase)
+
+	mockUseCase.On("Logout", mock.Anything, mock.MatchedBy(func(auth *model.Auth) bool {
+		return auth.FamilyID == "current"
+	}), "access-token").Return(nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/users/logout", nil)
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	cleared := map[string]bool{}
+	for _, cookie := range resp.Cookies() {
+		if cookie.Value == "" || cookie.Value == "deleted" {
+			cleared[cookie.Name] = true
+		}
+	}
+	assert.True(t, cleared["jwt"])
+	assert.True(t, cleared["refresh_token"])
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestLogout_Error tests that a failed session revocation is reported.
+func TestLogout_Error(t *testing.T) {
+	mockUseCase := new(mocks.MockUserUseCase)
+	app := setupUserTestApp(mockUseCase)
+
+	mockUseCase.On("Logout", mock.Anything, mock.Anything, "access-token").Return(fiber.ErrInternalServerError)
+
+	req := httptest.NewRequest(http.MethodPost, "/users/logout", nil)
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestChangePassword_Success tests changing the password of the current user.
+func TestChangePassword_Success(t *testing.T) {
+	mockUseCase := new(mocks.MockUserUseCase)
+	app := setupUserTestApp(mockUseCase)
+
+	mockUseCase.On("ChangePassword", mock.Anything, mock.MatchedBy(func(auth *model.Auth) bool {
+		return auth.FamilyID == "current"
+	}), mock.MatchedBy(func(req *model.ChangePasswordRequest) bool {
+		return req.CurrentPassword == "password123" && req.NewPassword == "newpassword456"
+	})).Return(nil)
+
+	body, _ := json.Marshal(map[string]string{
+		"current_password": "password123",
+		"new_password":     "newpassword456",
+	})
+
+	req := httptest.NewRequest(http.MethodPut, "/users/me/password", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestChangePassword_WrongCurrentPassword tests changing the password with an incorrect current password.
+func TestChangePassword_WrongCurrentPassword(t *testing.T) {
+	mockUseCase := new(mocks.MockUserUseCase)
+	app := setupUserTestApp(mockUseCase)
+
+	mockUseCase.On("ChangePassword", mock.Anything, mock.Anything, mock.Anything).
+		Return(fiber.NewError(fiber.StatusBadRequest, "Current password is incorrect"))
+
+	body, _ := json.Marshal(map[string]string{
+		"current_password": "wrongpassword",
+		"new_password":     "newpassword456",
+	})
+
+	req := httptest.NewRequest(http.MethodPut, "/users/me/password", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestChangePassword_Locked tests that a locked password check returns 429 with the lockout code and Retry-After.
+func TestChangePassword_Locked(t *testing.T) {
+	mockUseCase := new(mocks.MockUserUseCase)
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	controller := httpDelivery.NewUserController(log, viper.New(), mockUseCase)
+
+	app := fiber.New(fiber.Config{ErrorHandler: config.NewErrorHandler()})
+	app.Put("/users/me/password", authenticateAs(1, "user"), controller.ChangePassword)
+
+	mockUseCase.On("ChangePassword", mock.Anything, mock.Anything, mock.Anything).
+		Return(usecase.NewCodedError(fiber.StatusTooManyRequests, usecase.ErrorCodePasswordLocked, "Password check is locked for 15m0s after too many wrong attempts", 15*time.Minute))
+
+	body, _ := json.Marshal(map[string]string{
+		"current_password": "wrongpassword",
+		"new_password":     "newpassword456",
+	})
+	req := httptest.NewRequest(http.MethodPut, "/users/me/password", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "900", resp.Header.Get(fiber.HeaderRetryAfter))
+
+	var response map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&response)
+	assert.Equal(t, usecase.ErrorCodePasswordLocked, response["code"])
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestIssuePasswordReset_Success tests an admin issuing a password reset token.
+func TestIssuePasswordReset_Success(t *testing.T) {
+	mockUseCase := new(mocks.MockUserUseCase)
+	app := setupUserTestApp(mockUseCase)
+
+	mockUseCase.On("IssuePasswordReset", mock.Anything, mock.MatchedBy(func(auth *model.Auth) bool {
+		return auth.Role == "admin"
+	}), uint(5)).Return(&model.PasswordResetTokenResponse{UserID: 5, Token: "reset-token", ExpiresAt: time.Now().Add(time.Hour)}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/users/5/password-reset", nil)
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var result map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&result)
+
+	data := result["data"].(map[string]interface{})
+	assert.Equal(t, "reset-token", data["token"])
+	assert.Equal(t, float64(5), data["user_id"])
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestIssuePasswordReset_Forbidden tests issuing a password reset for a user the admin may not reset.
+func TestIssuePasswordReset_Forbidden(t *testing.T) {
+	mockUseCase := new(mocks.MockUserUseCase)
+	app := setupUserTestApp(mockUseCase)
+
+	mockUseCase.On("IssuePasswordReset", mock.Anything, mock.Anything, uint(1)).
+		Return(nil, fiber.NewError(fiber.StatusForbidden, "Only super admins can reset the password of an admin"))
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/users/1/password-reset", nil)
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestUnlock_Success tests an admin lifting the login lockout of a user.
+func TestUnlock_Success(t *testing.T) {
+	mockUseCase := new(mocks.MockUserUseCase)
+	app := setupUserTestApp(mockUseCase)
+
+	mockUseCase.On("Unlock", mock.Anything, mock.MatchedBy(func(auth *model.Auth) bool {
+		return *auth.UserID == 9 && auth.Role == "admin"
+	}), uint(2)).Return(nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/users/2/unlock", nil)
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestUnlock_InvalidID tests unlocking with a user ID that is not a number.
+func TestUnlock_InvalidID(t *testing.T) {
+	mockUseCase := new(mocks.MockUserUseCase)
+	app := setupUserTestApp(mockUseCase)
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/users/abc/unlock", nil)
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	mockUseCase.AssertNotCalled(t, "Unlock", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestResetPassword_Success tests redeeming a password reset token.
+func TestResetPassword_Success(t *testing.T) {
+	mockUseCase := new(mocks.MockUserUseCase)
+	app := setupUserTestApp(mockUseCase)
+
+	mockUseCase.On("ResetPassword", mock.Anything, mock.MatchedBy(func(req *model.ResetPasswordRequest) bool {
+		return req.Token == "reset-token" && req.NewPassword == "newpassword456"
+	})).Return(nil)
+
+	body, _ := json.Marshal(map[string]string{
+		"token":        "reset-token",
+		"new_password": "newpassword456",
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/users/password-reset", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestResetPassword_InvalidToken tests redeeming a reset token that was already used.
+func TestResetPassword_InvalidToken(t *testing.T) {
+	mockUseCase := new(mocks.MockUserUseCase)
+	app := setupUserTestApp(mockUseCase)
+
+	mockUseCase.On("ResetPassword", mock.Anything, mock.Anything).
+		Return(fiber.NewError(fiber.StatusBadRequest, "Invalid or expired reset token"))
+
+	body, _ := json.Marshal(map[string]string{
+		"token":        "used-token",
+		"new_password": "newpassword456",
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/users/password-reset", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	mockUseCase.AssertExpectations(t)
+}
@@ -0,0 +1,257 @@
+package controller_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	httpDelivery "backend/internal/delivery/http"
+	"backend/internal/model"
+	"backend/tests/mocks"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// setupWebhookTestApp creates a Fiber app with WebhookController for testing.
+func setupWebhookTestApp(mockUseCase *mocks.MockWebhookUseCase) *fiber.App {
+	app := fiber.New()
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	controller := httpDelivery.NewWebhookController(log, mockUseCase)
+
+	// Middleware to set auth context for testing
+	app.Use(func(c *fiber.Ctx) error {
+		userID := uint(1)
+		auth := &model.Auth{
+			UserID:   &userID,
+			Username: "testuser",
+			Role:     "user",
+		}
+		c.Locals("auth", auth)
+		return c.Next()
+	})
+
+	app.Post("/webhooks", controller.Create)
+	app.Get("/webhooks", controller.List)
+	app.Delete("/webhooks/:id", controller.Delete)
+	app.Get("/webhooks/:id/deliveries", controller.GetDeliveries)
+	app.Post("/webhooks/:id/deliveries/:deliveryId/redeliver", controller.Redeliver)
+
+	return app
+}
+
+// TestCreateWebhook_Success tests registering a webhook endpoint.
+func TestCreateWebhook_Success(t *testing.T) {
+	mockUseCase := new(mocks.MockWebhookUseCase)
+	app := setupWebhookTestApp(mockUseCase)
+
+	expectedResponse := &model.WebhookResponse{
+		ID:         1,
+		URL:        "https://example.com/hooks",
+		EventTypes: []string{"transaction"},
+		IsActive:   true,
+		Secret:     "secret",
+		CreatedAt:  time.Now(),
+	}
+
+	mockUseCase.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(req *model.CreateWebhookRequest) bool {
+		return req.URL == "https://example.com/hooks" && len(req.EventTypes) == 1 && req.EventTypes[0] == "transaction"
+	})).Return(expectedResponse, nil)
+
+	reqBody := map[string]interface{}{
+		"url":         "https://example.com/hooks",
+		"event_types": []string{"transaction"},
+	}
+	body, _ := json.Marshal(reqBody)
+
+	req := httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var result map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&result)
+
+	data := result["data"].(map[string]interface{})
+	assert.Equal(t, "secret", data["secret"])
+	assert.Equal(t, true, data["is_active"])
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestCreateWebhook_InvalidRequest tests registering a webhook with invalid request body.
+func TestCreateWebhook_InvalidRequest(t *testing.T) {
+	mockUseCase := new(mocks.MockWebhookUseCase)
+	app := setupWebhookTestApp(mockUseCase)
+
+	req := httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewReader([]byte("invalid json")))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+// TestListWebhooks_Success tests listing webhook endpoints.
+func TestListWebhooks_Success(t *testing.T) {
+	mockUseCase := new(mocks.MockWebhookUseCase)
+	app := setupWebhookTestApp(mockUseCase)
+
+	expectedResponse := []model.WebhookResponse{
+		{ID: 1, URL: "https://example.com/a", EventTypes: []string{"transaction"}, IsActive: true},
+		{ID: 2, URL: "https://example.com/b", EventTypes: []string{"wallet_update"}, IsActive: true},
+	}
+
+	mockUseCase.On("List", mock.Anything, mock.Anything).Return(expectedResponse, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/webhooks", nil)
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&result)
+
+	data := result["data"].([]interface{})
+	assert.Len(t, data, 2)
+	assert.Nil(t, data[0].(map[string]interface{})["secret"])
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestDeleteWebhook_Success tests deleting a webhook endpoint.
+func TestDeleteWebhook_Success(t *testing.T) {
+	mockUseCase := new(mocks.MockWebhookUseCase)
+	app := setupWebhookTestApp(mockUseCase)
+
+	mockUseCase.On("Delete", mock.Anything, mock.Anything, uint(5)).Return(nil)
+
+	req := httptest.NewRequest(http.MethodDelete, "/webhooks/5", nil)
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestDeleteWebhook_NotFound tests deleting a webhook endpoint of another user.
+func TestDeleteWebhook_NotFound(t *testing.T) {
+	mockUseCase := new(mocks.MockWebhookUseCase)
+	app := setupWebhookTestApp(mockUseCase)
+
+	mockUseCase.On("Delete", mock.Anything, mock.Anything, uint(5)).
+		Return(fiber.NewError(fiber.StatusNotFound, "Webhook not found"))
+
+	req := httptest.NewRequest(http.MethodDelete, "/webhooks/5", nil)
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestGetWebhookDeliveries_Success tests getting the delivery log with pagination.
+func TestGetWebhookDeliveries_Success(t *testing.T) {
+	mockUseCase := new(mocks.MockWebhookUseCase)
+	app := setupWebhookTestApp(mockUseCase)
+
+	responseStatus := 500
+	expectedResponse := &model.WebhookDeliveryListResponse{
+		Deliveries: []model.WebhookDeliveryResponse{
+			{ID: 9, WebhookID: 5, EventID: 3, EventType: "transaction", Status: "pending", Attempts: 1, ResponseStatus: &responseStatus},
+		},
+		Total: 1,
+		Page:  2,
+		Limit: 5,
+	}
+
+	mockUseCase.On("GetDeliveries", mock.Anything, mock.Anything, uint(5), 2, 5).Return(expectedResponse, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/webhooks/5/deliveries?page=2&limit=5", nil)
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&result)
+
+	data := result["data"].(map[string]interface{})
+	assert.Equal(t, float64(1), data["total"])
+	delivery := data["deliveries"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, float64(500), delivery["response_status"])
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestGetWebhookDeliveries_InvalidID tests getting deliveries with an invalid webhook ID.
+func TestGetWebhookDeliveries_InvalidID(t *testing.T) {
+	mockUseCase := new(mocks.MockWebhookUseCase)
+	app := setupWebhookTestApp(mockUseCase)
+
+	req := httptest.NewRequest(http.MethodGet, "/webhooks/abc/deliveries", nil)
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+// TestRedeliverWebhook_Success tests queueing a delivery again.
+func TestRedeliverWebhook_Success(t *testing.T) {
+	mockUseCase := new(mocks.MockWebhookUseCase)
+	app := setupWebhookTestApp(mockUseCase)
+
+	expectedResponse := &model.WebhookDeliveryResponse{
+		ID:        9,
+		WebhookID: 5,
+		EventID:   3,
+		EventType: "transaction",
+		Status:    "pending",
+	}
+
+	mockUseCase.On("Redeliver", mock.Anything, mock.Anything, uint(5), uint(9)).Return(expectedResponse, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/5/deliveries/9/redeliver", nil)
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	var result map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&result)
+
+	data := result["data"].(map[string]interface{})
+	assert.Equal(t, "pending", data["status"])
+
+	mockUseCase.AssertExpectations(t)
+}
+
+// TestRedeliverWebhook_DeliveryNotFound tests redelivering an unknown delivery.
+func TestRedeliverWebhook_DeliveryNotFound(t *testing.T) {
+	mockUseCase := new(mocks.MockWebhookUseCase)
+	app := setupWebhookTestApp(mockUseCase)
+
+	mockUseCase.On("Redeliver", mock.Anything, mock.Anything, uint(5), uint(99)).
+		Return(nil, fiber.NewError(fiber.StatusNotFound, "Webhook delivery not found"))
+
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/5/deliveries/99/redeliver", nil)
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	mockUseCase.AssertExpectations(t)
+}
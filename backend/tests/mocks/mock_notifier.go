@@ -0,0 +1,27 @@
+package mocks
+
+import (
+	"backend/internal/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockNotifier is a mock implementation of NotifierInterface.
+type MockNotifier struct {
+	mock.Mock
+}
+
+func (m *MockNotifier) NotifyTransaction(userID uint, eventKey string, notification *model.TransactionNotification) error {
+	args := m.Called(userID, eventKey, notification)
+	return args.Error(0)
+}
+
+func (m *MockNotifier) NotifyWalletUpdate(userID uint, eventKey string, notification *model.WalletUpdateNotification) error {
+	args := m.Called(userID, eventKey, notification)
+	return args.Error(0)
+}
+
+func (m *MockNotifier) NotifyAdminTransaction(notification *model.AdminTransactionNotification) error {
+	args := m.Called(notification)
+	return args.Error(0)
+}
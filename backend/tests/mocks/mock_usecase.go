@@ -22,7 +22,15 @@ func (m *MockUserUseCase) Create(ctx context.Context, request *model.UserRegistr
 	return args.Get(0).(*model.UserResponse), args.Error(1)
 }
 
-func (m *MockUserUseCase) Login(ctx context.Context, request *model.UserLoginRequest) (*model.UserResponse, error) {
+func (m *MockUserUseCase) Login(ctx context.Context, request *model.UserLoginRequest) (*model.LoginResponse, error) {
+	args := m.Called(ctx, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.LoginResponse), args.Error(1)
+}
+
+func (m *MockUserUseCase) Refresh(ctx context.Context, request *model.RefreshTokenRequest) (*model.UserResponse, error) {
 	args := m.Called(ctx, request)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -30,6 +38,34 @@ func (m *MockUserUseCase) Login(ctx context.Context, request *model.UserLoginReq
 	return args.Get(0).(*model.UserResponse), args.Error(1)
 }
 
+func (m *MockUserUseCase) Logout(ctx context.Context, auth *model.Auth, token string) error {
+	args := m.Called(ctx, auth, token)
+	return args.Error(0)
+}
+
+func (m *MockUserUseCase) ChangePassword(ctx context.Context, auth *model.Auth, request *model.ChangePasswordRequest) error {
+	args := m.Called(ctx, auth, request)
+	return args.Error(0)
+}
+
+func (m *MockUserUseCase) IssuePasswordReset(ctx context.Context, auth *model.Auth, userID uint) (*model.PasswordResetTokenResponse, error) {
+	args := m.Called(ctx, auth, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PasswordResetTokenResponse), args.Error(1)
+}
+
+func (m *MockUserUseCase) ResetPassword(ctx context.Context, request *model.ResetPasswordRequest) error {
+	args := m.Called(ctx, request)
+	return args.Error(0)
+}
+
+func (m *MockUserUseCase) Unlock(ctx context.Context, auth *model.Auth, userID uint) error {
+	args := m.Called(ctx, auth, userID)
+	return args.Error(0)
+}
+
 func (m *MockUserUseCase) GetProfile(ctx context.Context, userID uint) (*model.UserProfileResponse, error) {
 	args := m.Called(ctx, userID)
 	if args.Get(0) == nil {
@@ -38,6 +74,16 @@ func (m *MockUserUseCase) GetProfile(ctx context.Context, userID uint) (*model.U
 	return args.Get(0).(*model.UserProfileResponse), args.Error(1)
 }
 
+func (m *MockUserUseCase) CheckActive(ctx context.Context, userID uint) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockUserUseCase) CheckPermission(ctx context.Context, userID uint, permission entity.Permission) error {
+	args := m.Called(ctx, userID, permission)
+	return args.Error(0)
+}
+
 // MockWalletUseCase is a mock implementation of WalletUseCaseInterface.
 type MockWalletUseCase struct {
 	mock.Mock
@@ -80,6 +126,30 @@ func (m *MockTransactionUseCase) Transfer(ctx context.Context, auth *model.Auth,
 	return args.Get(0).(*model.TransactionResponse), args.Error(1)
 }
 
+func (m *MockTransactionUseCase) Withdraw(ctx context.Context, auth *model.Auth, request *model.WithdrawRequest) (*model.TransactionResponse, error) {
+	args := m.Called(ctx, auth, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TransactionResponse), args.Error(1)
+}
+
+func (m *MockTransactionUseCase) SettleWithdraw(ctx context.Context, auth *model.Auth, request *model.SettleWithdrawRequest) (*model.TransactionResponse, error) {
+	args := m.Called(ctx, auth, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TransactionResponse), args.Error(1)
+}
+
+func (m *MockTransactionUseCase) Reverse(ctx context.Context, auth *model.Auth, request *model.ReverseTransactionRequest) (*model.TransactionResponse, error) {
+	args := m.Called(ctx, auth, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TransactionResponse), args.Error(1)
+}
+
 func (m *MockTransactionUseCase) GetTransactionsByUserID(ctx context.Context, userID uint, page, limit int) (*model.TransactionListResponse, error) {
 	args := m.Called(ctx, userID, page, limit)
 	if args.Get(0) == nil {
@@ -88,6 +158,14 @@ func (m *MockTransactionUseCase) GetTransactionsByUserID(ctx context.Context, us
 	return args.Get(0).(*model.TransactionListResponse), args.Error(1)
 }
 
+func (m *MockTransactionUseCase) GetTransactionsByWalletID(ctx context.Context, walletID uint, page, limit int) (*model.TransactionListResponse, error) {
+	args := m.Called(ctx, walletID, page, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TransactionListResponse), args.Error(1)
+}
+
 // MockWalletMutationUseCase is a mock implementation of WalletMutationUseCaseInterface.
 type MockWalletMutationUseCase struct {
 	mock.Mock
@@ -100,3 +178,270 @@ func (m *MockWalletMutationUseCase) GetMutationsByUserID(ctx context.Context, us
 	}
 	return args.Get(0).(*model.WalletMutationListResponse), args.Error(1)
 }
+
+func (m *MockWalletMutationUseCase) GetMutationsByWalletID(ctx context.Context, walletID uint, page, limit int) (*model.WalletMutationListResponse, error) {
+	args := m.Called(ctx, walletID, page, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.WalletMutationListResponse), args.Error(1)
+}
+
+// MockAuditLogUseCase is a mock implementation of AuditLogUseCaseInterface.
+type MockAuditLogUseCase struct {
+	mock.Mock
+}
+
+func (m *MockAuditLogUseCase) Record(ctx context.Context, request *model.AuditLogRequest) error {
+	args := m.Called(ctx, request)
+	return args.Error(0)
+}
+
+// MockReconciliationUseCase is a mock implementation of ReconciliationUseCaseInterface.
+type MockReconciliationUseCase struct {
+	mock.Mock
+}
+
+func (m *MockReconciliationUseCase) GetReport(ctx context.Context, auth *model.Auth) (*model.ReconciliationReport, error) {
+	args := m.Called(ctx, auth)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ReconciliationReport), args.Error(1)
+}
+
+// MockWebhookUseCase is a mock implementation of WebhookUseCaseInterface.
+type MockWebhookUseCase struct {
+	mock.Mock
+}
+
+func (m *MockWebhookUseCase) Create(ctx context.Context, auth *model.Auth, request *model.CreateWebhookRequest) (*model.WebhookResponse, error) {
+	args := m.Called(ctx, auth, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.WebhookResponse), args.Error(1)
+}
+
+func (m *MockWebhookUseCase) List(ctx context.Context, auth *model.Auth) ([]model.WebhookResponse, error) {
+	args := m.Called(ctx, auth)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.WebhookResponse), args.Error(1)
+}
+
+func (m *MockWebhookUseCase) Delete(ctx context.Context, auth *model.Auth, webhookID uint) error {
+	args := m.Called(ctx, auth, webhookID)
+	return args.Error(0)
+}
+
+func (m *MockWebhookUseCase) GetDeliveries(ctx context.Context, auth *model.Auth, webhookID uint, page, limit int) (*model.WebhookDeliveryListResponse, error) {
+	args := m.Called(ctx, auth, webhookID, page, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.WebhookDeliveryListResponse), args.Error(1)
+}
+
+func (m *MockWebhookUseCase) Redeliver(ctx context.Context, auth *model.Auth, webhookID uint, deliveryID uint) (*model.WebhookDeliveryResponse, error) {
+	args := m.Called(ctx, auth, webhookID, deliveryID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.WebhookDeliveryResponse), args.Error(1)
+}
+
+// MockNotificationUseCase is a mock implementation of NotificationUseCaseInterface.
+type MockNotificationUseCase struct {
+	mock.Mock
+}
+
+func (m *MockNotificationUseCase) List(ctx context.Context, auth *model.Auth, unreadOnly bool, page, limit int) (*model.NotificationListResponse, error) {
+	args := m.Called(ctx, auth, unreadOnly, page, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.NotificationListResponse), args.Error(1)
+}
+
+func (m *MockNotificationUseCase) CountUnread(ctx context.Context, auth *model.Auth) (*model.UnreadNotificationCountResponse, error) {
+	args := m.Called(ctx, auth)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.UnreadNotificationCountResponse), args.Error(1)
+}
+
+func (m *MockNotificationUseCase) Mark(ctx context.Context, auth *model.Auth, notificationID uint, read bool) (*model.NotificationResponse, error) {
+	args := m.Called(ctx, auth, notificationID, read)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.NotificationResponse), args.Error(1)
+}
+
+func (m *MockNotificationUseCase) MarkBulk(ctx context.Context, auth *model.Auth, request *model.MarkNotificationsRequest, read bool) (*model.MarkNotificationsResponse, error) {
+	args := m.Called(ctx, auth, request, read)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.MarkNotificationsResponse), args.Error(1)
+}
+
+// MockAdminUserUseCase is a mock implementation of AdminUserUseCaseInterface.
+type MockAdminUserUseCase struct {
+	mock.Mock
+}
+
+func (m *MockAdminUserUseCase) Search(ctx context.Context, request *model.AdminUserSearchRequest) (*model.AdminUserListResponse, error) {
+	args := m.Called(ctx, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.AdminUserListResponse), args.Error(1)
+}
+
+func (m *MockAdminUserUseCase) Get(ctx context.Context, userID uint) (*model.AdminUserDetailResponse, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.AdminUserDetailResponse), args.Error(1)
+}
+
+func (m *MockAdminUserUseCase) ChangeRole(ctx context.Context, auth *model.Auth, userID uint, request *model.ChangeRoleRequest) (*model.AdminUserResponse, error) {
+	args := m.Called(ctx, auth, userID, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.AdminUserResponse), args.Error(1)
+}
+
+func (m *MockAdminUserUseCase) Disable(ctx context.Context, auth *model.Auth, userID uint) (*model.AdminUserResponse, error) {
+	args := m.Called(ctx, auth, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.AdminUserResponse), args.Error(1)
+}
+
+func (m *MockAdminUserUseCase) Enable(ctx context.Context, auth *model.Auth, userID uint) (*model.AdminUserResponse, error) {
+	args := m.Called(ctx, auth, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.AdminUserResponse), args.Error(1)
+}
+
+func (m *MockAdminUserUseCase) ForceLogout(ctx context.Context, auth *model.Auth, userID uint) (*model.RevokeSessionsResponse, error) {
+	args := m.Called(ctx, auth, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.RevokeSessionsResponse), args.Error(1)
+}
+
+// MockSessionUseCase is a mock implementation of SessionUseCaseInterface.
+type MockSessionUseCase struct {
+	mock.Mock
+}
+
+func (m *MockSessionUseCase) List(ctx context.Context, auth *model.Auth) ([]model.SessionResponse, error) {
+	args := m.Called(ctx, auth)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.SessionResponse), args.Error(1)
+}
+
+func (m *MockSessionUseCase) Revoke(ctx context.Context, auth *model.Auth, sessionID string) error {
+	args := m.Called(ctx, auth, sessionID)
+	return args.Error(0)
+}
+
+func (m *MockSessionUseCase) RevokeOthers(ctx context.Context, auth *model.Auth) (*model.RevokeSessionsResponse, error) {
+	args := m.Called(ctx, auth)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.RevokeSessionsResponse), args.Error(1)
+}
+
+// MockTwoFactorUseCase is a mock implementation of TwoFactorUseCaseInterface.
+type MockTwoFactorUseCase struct {
+	mock.Mock
+}
+
+func (m *MockTwoFactorUseCase) Status(ctx context.Context, auth *model.Auth) (*model.TwoFactorStatusResponse, error) {
+	args := m.Called(ctx, auth)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TwoFactorStatusResponse), args.Error(1)
+}
+
+func (m *MockTwoFactorUseCase) Setup(ctx context.Context, auth *model.Auth) (*model.TwoFactorSetupResponse, error) {
+	args := m.Called(ctx, auth)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TwoFactorSetupResponse), args.Error(1)
+}
+
+func (m *MockTwoFactorUseCase) Confirm(ctx context.Context, auth *model.Auth, request *model.TwoFactorCodeRequest) (*model.RecoveryCodesResponse, error) {
+	args := m.Called(ctx, auth, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.RecoveryCodesResponse), args.Error(1)
+}
+
+func (m *MockTwoFactorUseCase) RegenerateRecoveryCodes(ctx context.Context, auth *model.Auth, request *model.TwoFactorCodeRequest) (*model.RecoveryCodesResponse, error) {
+	args := m.Called(ctx, auth, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.RecoveryCodesResponse), args.Error(1)
+}
+
+func (m *MockTwoFactorUseCase) Disable(ctx context.Context, auth *model.Auth, request *model.TwoFactorDisableRequest) error {
+	args := m.Called(ctx, auth, request)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorUseCase) Login(ctx context.Context, request *model.TwoFactorLoginRequest) (*model.UserResponse, error) {
+	args := m.Called(ctx, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.UserResponse), args.Error(1)
+}
+
+// MockTransactionPINUseCase is a mock implementation of TransactionPINUseCaseInterface.
+type MockTransactionPINUseCase struct {
+	mock.Mock
+}
+
+func (m *MockTransactionPINUseCase) Status(ctx context.Context, auth *model.Auth) (*model.TransactionPINStatusResponse, error) {
+	args := m.Called(ctx, auth)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TransactionPINStatusResponse), args.Error(1)
+}
+
+func (m *MockTransactionPINUseCase) Set(ctx context.Context, auth *model.Auth, request *model.TransactionPINRequest) error {
+	args := m.Called(ctx, auth, request)
+	return args.Error(0)
+}
+
+func (m *MockTransactionPINUseCase) Change(ctx context.Context, auth *model.Auth, request *model.ChangeTransactionPINRequest) error {
+	args := m.Called(ctx, auth, request)
+	return args.Error(0)
+}
+
+func (m *MockTransactionPINUseCase) Reset(ctx context.Context, auth *model.Auth, request *model.TransactionPINRequest) error {
+	args := m.Called(ctx, auth, request)
+	return args.Error(0)
+}
@@ -0,0 +1,208 @@
+package util_test
+
+import (
+	"backend/internal/config"
+	"backend/internal/util"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAttemptLimiter tests that a key is locked after the maximum number of failures and unlocked by Reset.
+func TestAttemptLimiter(t *testing.T) {
+	viperConfig := config.NewViper()
+	if viperConfig.GetString("REDIS_HOST") == "" {
+		t.Skip("REDIS_HOST is not configured, skipping attempt limiter test")
+	}
+	client := config.NewRedisClient(viperConfig)
+	t.Cleanup(func() { client.Close() })
+
+	ctx := context.Background()
+	limiter := util.NewAttemptLimiter(client, "attempt_limiter_test", 3, time.Minute)
+	key := fmt.Sprint(time.Now().UnixNano())
+
+	remaining, err := limiter.RecordFailure(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, 2, remaining)
+
+	remaining, err = limiter.RecordFailure(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, 1, remaining)
+
+	lockedFor, err := limiter.LockedFor(ctx, key)
+	require.NoError(t, err)
+	assert.Zero(t, lockedFor)
+
+	remaining, err = limiter.RecordFailure(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, 0, remaining)
+
+	lockedFor, err = limiter.LockedFor(ctx, key)
+	require.NoError(t, err)
+	assert.InDelta(t, time.Minute, lockedFor, float64(5*time.Second))
+
+	require.NoError(t, limiter.Reset(ctx, key))
+	lockedFor, err = limiter.LockedFor(ctx, key)
+	require.NoError(t, err)
+	assert.Zero(t, lockedFor)
+
+	// The count starts over after a reset
+	remaining, err = limiter.RecordFailure(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, 2, remaining)
+}
+
+// TestAttemptLimiter_ProgressiveDelay tests that failures from DelayAfter on delay the next attempt, doubling each time.
+func TestAttemptLimiter_ProgressiveDelay(t *testing.T) {
+	viperConfig := config.NewViper()
+	if viperConfig.GetString("REDIS_HOST") == "" {
+		t.Skip("REDIS_HOST is not configured, skipping attempt limiter test")
+	}
+	client := config.NewRedisClient(viperConfig)
+	t.Cleanup(func() { client.Close() })
+
+	ctx := context.Background()
+	limiter := util.NewAttemptLimiter(client, "attempt_limiter_delay_test", 5, time.Minute).
+		WithProgressiveDelay(2, time.Second)
+	key := fmt.Sprint(time.Now().UnixNano())
+
+	_, err := limiter.RecordFailure(ctx, key)
+	require.NoError(t, err)
+	delayedFor, err := limiter.DelayedFor(ctx, key)
+	require.NoError(t, err)
+	assert.Zero(t, delayedFor)
+
+	_, err = limiter.RecordFailure(ctx, key)
+	require.NoError(t, err)
+	delayedFor, err = limiter.DelayedFor(ctx, key)
+	require.NoError(t, err)
+	assert.InDelta(t, time.Second, delayedFor, float64(200*time.Millisecond))
+
+	_, err = limiter.RecordFailure(ctx, key)
+	require.NoError(t, err)
+	delayedFor, err = limiter.DelayedFor(ctx, key)
+	require.NoError(t, err)
+	assert.InDelta(t, 2*time.Second, delayedFor, float64(200*time.Millisecond))
+
+	require.NoError(t, limiter.Reset(ctx, key))
+	delayedFor, err = limiter.DelayedFor(ctx, key)
+	require.NoError(t, err)
+	assert.Zero(t, delayedFor)
+}
+
+// TestAttemptLimiter_Begin tests that attempts are counted before they are checked and refused once the key is locked.
+func TestAttemptLimiter_Begin(t *testing.T) {
+	viperConfig := config.NewViper()
+	if viperConfig.GetString("REDIS_HOST") == "" {
+		t.Skip("REDIS_HOST is not configured, skipping attempt limiter test")
+	}
+	client := config.NewRedisClient(viperConfig)
+	t.Cleanup(func() { client.Close() })
+
+	ctx := context.Background()
+	limiter := util.NewAttemptLimiter(client, "attempt_limiter_begin_test", 3, time.Minute)
+	key := fmt.Sprint(time.Now().UnixNano())
+
+	// Three attempts in flight at once use up every attempt, the fourth is refused before it is checked
+	for _, left := range []int{2, 1, 0} {
+		attempt, err := limiter.Begin(ctx, key)
+		require.NoError(t, err)
+		assert.True(t, attempt.Allowed())
+		assert.Equal(t, left, attempt.Left)
+	}
+
+	attempt, err := limiter.Begin(ctx, key)
+	require.NoError(t, err)
+	assert.True(t, attempt.Locked)
+	assert.InDelta(t, time.Minute, attempt.Wait, float64(5*time.Second))
+
+	// A successful attempt resets the key
+	require.NoError(t, limiter.Reset(ctx, key))
+	attempt, err = limiter.Begin(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, 2, attempt.Left)
+}
+
+// TestAttemptLimiter_Release tests that a released attempt is taken back without forgetting other failures.
+func TestAttemptLimiter_Release(t *testing.T) {
+	viperConfig := config.NewViper()
+	if viperConfig.GetString("REDIS_HOST") == "" {
+		t.Skip("REDIS_HOST is not configured, skipping attempt limiter test")
+	}
+	client := config.NewRedisClient(viperConfig)
+	t.Cleanup(func() { client.Close() })
+
+	ctx := context.Background()
+	limiter := util.NewAttemptLimiter(client, "attempt_limiter_release_test", 3, time.Minute)
+	key := fmt.Sprint(time.Now().UnixNano())
+
+	_, err := limiter.RecordFailure(ctx, key)
+	require.NoError(t, err)
+
+	attempt, err := limiter.Begin(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, 1, attempt.Left)
+	require.NoError(t, limiter.Release(ctx, key, attempt))
+
+	// The last attempt locks the key, releasing it lifts the lock again
+	attempt, err = limiter.Begin(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, 1, attempt.Left)
+	attempt, err = limiter.Begin(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, 0, attempt.Left)
+	require.NoError(t, limiter.Release(ctx, key, attempt))
+
+	lockedFor, err := limiter.LockedFor(ctx, key)
+	require.NoError(t, err)
+	assert.Zero(t, lockedFor)
+
+	remaining, err := limiter.RecordFailure(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, 0, remaining)
+}
+
+// TestAttemptLimiter_SuccessLiftsDelay tests that an attempt right after a successful one is not delayed,
+// whether the success is released or resets the key.
+func TestAttemptLimiter_SuccessLiftsDelay(t *testing.T) {
+	viperConfig := config.NewViper()
+	if viperConfig.GetString("REDIS_HOST") == "" {
+		t.Skip("REDIS_HOST is not configured, skipping attempt limiter test")
+	}
+	client := config.NewRedisClient(viperConfig)
+	t.Cleanup(func() { client.Close() })
+
+	ctx := context.Background()
+	limiter := util.NewAttemptLimiter(client, "attempt_limiter_success_delay_test", 5, time.Minute).
+		WithProgressiveDelay(2, time.Second)
+
+	for name, succeed := range map[string]func(key string, attempt *util.Attempt) error{
+		"release": func(key string, attempt *util.Attempt) error { return limiter.Release(ctx, key, attempt) },
+		"reset":   func(key string, attempt *util.Attempt) error { return limiter.Reset(ctx, key) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			key := fmt.Sprint(time.Now().UnixNano())
+
+			_, err := limiter.RecordFailure(ctx, key)
+			require.NoError(t, err)
+
+			// Counted up front, this attempt reaches DelayAfter and starts a delay before it succeeds
+			attempt, err := limiter.Begin(ctx, key)
+			require.NoError(t, err)
+			require.True(t, attempt.Allowed())
+			require.NoError(t, succeed(key, attempt))
+
+			delayedFor, err := limiter.DelayedFor(ctx, key)
+			require.NoError(t, err)
+			assert.Zero(t, delayedFor)
+
+			attempt, err = limiter.Begin(ctx, key)
+			require.NoError(t, err)
+			assert.True(t, attempt.Allowed())
+		})
+	}
+}
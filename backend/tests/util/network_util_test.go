@@ -0,0 +1,42 @@
+package util_test
+
+import (
+	"backend/internal/util"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestIsPublicIP tests that internal address ranges are not treated as public.
+func TestIsPublicIP(t *testing.T) {
+	for _, address := range []string{"8.8.8.8", "1.1.1.1", "2606:4700:4700::1111"} {
+		assert.True(t, util.IsPublicIP(net.ParseIP(address)), address)
+	}
+	for _, address := range []string{
+		"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "0.0.0.0",
+		"100.64.0.1", "224.0.0.1", "::1", "::", "fe80::1", "fd00::1", "::ffff:127.0.0.1",
+	} {
+		assert.False(t, util.IsPublicIP(net.ParseIP(address)), address)
+	}
+}
+
+// TestCheckPublicURL tests that localhost and internal IP literals are refused when registering a URL.
+func TestCheckPublicURL(t *testing.T) {
+	assert.NoError(t, util.CheckPublicURL("https://hooks.example.com/wallet"))
+	assert.NoError(t, util.CheckPublicURL("https://8.8.8.8/wallet"))
+
+	for _, rawURL := range []string{
+		"https://localhost/hook", "https://api.localhost/hook", "https://127.0.0.1:8080/hook",
+		"https://169.254.169.254/latest/meta-data", "https://10.0.0.5/hook", "https://[::1]/hook",
+	} {
+		assert.ErrorIs(t, util.CheckPublicURL(rawURL), util.ErrNonPublicAddress, rawURL)
+	}
+}
+
+// TestPublicDialControl tests the connect-time check on resolved addresses.
+func TestPublicDialControl(t *testing.T) {
+	assert.NoError(t, util.PublicDialControl("tcp4", "8.8.8.8:443", nil))
+	assert.ErrorIs(t, util.PublicDialControl("tcp4", "127.0.0.1:443", nil), util.ErrNonPublicAddress)
+	assert.ErrorIs(t, util.PublicDialControl("tcp6", "[fe80::1]:443", nil), util.ErrNonPublicAddress)
+}
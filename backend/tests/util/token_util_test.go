@@ -0,0 +1,243 @@
+package util_test
+
+import (
+	"backend/internal/config"
+	"backend/internal/model"
+	"backend/internal/util"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// These tests run against a real Redis server configured through .env or
+// REDIS_* environment variables. Without REDIS_HOST they are skipped.
+
+// setupTokenUtil connects to the configured Redis server or skips the test.
+func setupTokenUtil(t *testing.T) *util.TokenUtil {
+	t.Helper()
+
+	viperConfig := config.NewViper()
+	if viperConfig.GetString("REDIS_HOST") == "" {
+		t.Skip("REDIS_HOST is not configured, skipping token test")
+	}
+
+	client := config.NewRedisClient(viperConfig)
+	t.Cleanup(func() { client.Close() })
+
+	return util.NewTokenUtil("test-secret", client, time.Minute, time.Hour, time.Hour)
+}
+
+func testAuth() *model.Auth {
+	return testAuthFor(42)
+}
+
+func testAuthFor(userID uint) *model.Auth {
+	return &model.Auth{UserID: &userID, Username: "tokenuser", Role: "user"}
+}
+
+func testSession() *model.SessionInfo {
+	return &model.SessionInfo{DeviceName: "Chrome on Windows", UserAgent: "Mozilla/5.0", IPAddress: "10.0.0.1"}
+}
+
+// TestTokenUtil_RotateRefreshToken tests that a refresh token rotates into a new pair of the same family.
+func TestTokenUtil_RotateRefreshToken(t *testing.T) {
+	tokenUtil := setupTokenUtil(t)
+	ctx := context.Background()
+
+	first, err := tokenUtil.CreateTokenPair(ctx, testAuth(), testSession())
+	require.NoError(t, err)
+
+	auth, err := tokenUtil.ParseToken(ctx, first.AccessToken)
+	require.NoError(t, err)
+	assert.NotEmpty(t, auth.FamilyID)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), first.AccessTokenExpiresAt, 5*time.Second)
+
+	userID, familyID, err := tokenUtil.RotateRefreshToken(ctx, first.RefreshToken)
+	require.NoError(t, err)
+	assert.Equal(t, uint(42), userID)
+	assert.Equal(t, auth.FamilyID, familyID)
+
+	second, err := tokenUtil.CreateTokenPairInFamily(ctx, testAuth(), familyID, "10.0.0.2")
+	require.NoError(t, err)
+	assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+
+	_, err = tokenUtil.ParseToken(ctx, second.AccessToken)
+	assert.NoError(t, err)
+}
+
+// TestTokenUtil_RefreshTokenReuseRevokesFamily tests that presenting a rotated refresh token
+// revokes every token of its family.
+func TestTokenUtil_RefreshTokenReuseRevokesFamily(t *testing.T) {
+	tokenUtil := setupTokenUtil(t)
+	ctx := context.Background()
+
+	first, err := tokenUtil.CreateTokenPair(ctx, testAuth(), testSession())
+	require.NoError(t, err)
+
+	_, familyID, err := tokenUtil.RotateRefreshToken(ctx, first.RefreshToken)
+	require.NoError(t, err)
+	second, err := tokenUtil.CreateTokenPairInFamily(ctx, testAuth(), familyID, "10.0.0.2")
+	require.NoError(t, err)
+
+	// The first refresh token was already rotated, presenting it again is treated as theft
+	_, _, err = tokenUtil.RotateRefreshToken(ctx, first.RefreshToken)
+	assert.ErrorIs(t, err, util.ErrRefreshTokenReused)
+
+	_, err = tokenUtil.ParseToken(ctx, second.AccessToken)
+	assert.Error(t, err)
+
+	_, _, err = tokenUtil.RotateRefreshToken(ctx, second.RefreshToken)
+	assert.ErrorIs(t, err, util.ErrRefreshTokenInvalid)
+}
+
+// TestTokenUtil_RotateUnknownRefreshToken tests rotating a refresh token that was never issued.
+func TestTokenUtil_RotateUnknownRefreshToken(t *testing.T) {
+	tokenUtil := setupTokenUtil(t)
+
+	_, _, err := tokenUtil.RotateRefreshToken(context.Background(), "unknown")
+	assert.ErrorIs(t, err, util.ErrRefreshTokenInvalid)
+}
+
+// TestTokenUtil_Sessions tests listing and revoking the sessions of a user.
+func TestTokenUtil_Sessions(t *testing.T) {
+	tokenUtil := setupTokenUtil(t)
+	ctx := context.Background()
+	userID := uint(time.Now().UnixNano() % 1000000000)
+
+	laptop, err := tokenUtil.CreateTokenPair(ctx, testAuthFor(userID), testSession())
+	require.NoError(t, err)
+	phone, err := tokenUtil.CreateTokenPair(ctx, testAuthFor(userID), &model.SessionInfo{DeviceName: "Safari on iOS", IPAddress: "10.0.0.3"})
+	require.NoError(t, err)
+	tablet, err := tokenUtil.CreateTokenPair(ctx, testAuthFor(userID), &model.SessionInfo{DeviceName: "Safari on iPadOS"})
+	require.NoError(t, err)
+
+	laptopAuth, err := tokenUtil.ParseToken(ctx, laptop.AccessToken)
+	require.NoError(t, err)
+	phoneAuth, err := tokenUtil.ParseToken(ctx, phone.AccessToken)
+	require.NoError(t, err)
+
+	sessions, err := tokenUtil.ListSessions(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 3)
+
+	devices := map[string]string{}
+	for _, session := range sessions {
+		devices[session.ID] = session.Device
+	}
+	assert.Equal(t, "Chrome on Windows", devices[laptopAuth.FamilyID])
+	assert.Equal(t, "Safari on iOS", devices[phoneAuth.FamilyID])
+
+	// Another user cannot revoke the session
+	revoked, err := tokenUtil.RevokeSession(ctx, userID+1, phoneAuth.FamilyID)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	revoked, err = tokenUtil.RevokeSession(ctx, userID, phoneAuth.FamilyID)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	_, err = tokenUtil.ParseToken(ctx, phone.AccessToken)
+	assert.Error(t, err)
+	_, _, err = tokenUtil.RotateRefreshToken(ctx, phone.RefreshToken)
+	assert.ErrorIs(t, err, util.ErrRefreshTokenInvalid)
+
+	// Activity on a revoked session does not bring it back
+	require.NoError(t, tokenUtil.TouchSession(ctx, phoneAuth.FamilyID, "10.0.0.3"))
+
+	count, err := tokenUtil.RevokeOtherSessions(ctx, userID, laptopAuth.FamilyID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	_, err = tokenUtil.ParseToken(ctx, tablet.AccessToken)
+	assert.Error(t, err)
+
+	sessions, err = tokenUtil.ListSessions(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, laptopAuth.FamilyID, sessions[0].ID)
+	assert.Equal(t, "10.0.0.1", sessions[0].IPAddress)
+}
+
+// TestTokenUtil_PasswordResetToken tests that a reset token is redeemed once and replaced by a newer one.
+func TestTokenUtil_PasswordResetToken(t *testing.T) {
+	tokenUtil := setupTokenUtil(t)
+	ctx := context.Background()
+	userID := uint(time.Now().UnixNano() % 1000000000)
+
+	first, expiresAt, err := tokenUtil.CreatePasswordResetToken(ctx, userID)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), expiresAt, 5*time.Second)
+
+	// Issuing another token invalidates the first one
+	second, _, err := tokenUtil.CreatePasswordResetToken(ctx, userID)
+	require.NoError(t, err)
+
+	_, err = tokenUtil.ConsumePasswordResetToken(ctx, first)
+	assert.ErrorIs(t, err, util.ErrPasswordResetTokenInvalid)
+
+	redeemedUserID, err := tokenUtil.ConsumePasswordResetToken(ctx, second)
+	require.NoError(t, err)
+	assert.Equal(t, userID, redeemedUserID)
+
+	_, err = tokenUtil.ConsumePasswordResetToken(ctx, second)
+	assert.ErrorIs(t, err, util.ErrPasswordResetTokenInvalid)
+}
+
+// TestTokenUtil_TwoFactorChallenge tests that a login challenge carries its session, allows a limited
+// number of attempts and can only be completed once.
+func TestTokenUtil_TwoFactorChallenge(t *testing.T) {
+	tokenUtil := setupTokenUtil(t)
+	ctx := context.Background()
+
+	token, expiresAt, err := tokenUtil.CreateTwoFactorChallenge(ctx, 42, testSession())
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(util.TwoFactorChallengeTTL), expiresAt, 5*time.Second)
+
+	userID, session, err := tokenUtil.AttemptTwoFactorChallenge(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, uint(42), userID)
+	assert.Equal(t, testSession(), session)
+
+	completed, err := tokenUtil.CompleteTwoFactorChallenge(ctx, token)
+	require.NoError(t, err)
+	assert.True(t, completed)
+
+	completed, err = tokenUtil.CompleteTwoFactorChallenge(ctx, token)
+	require.NoError(t, err)
+	assert.False(t, completed)
+
+	_, _, err = tokenUtil.AttemptTwoFactorChallenge(ctx, token)
+	assert.ErrorIs(t, err, util.ErrTwoFactorChallengeInvalid)
+
+	// A challenge is dropped once its attempts run out
+	token, _, err = tokenUtil.CreateTwoFactorChallenge(ctx, 42, testSession())
+	require.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		_, _, err = tokenUtil.AttemptTwoFactorChallenge(ctx, token)
+		require.NoError(t, err)
+	}
+	_, _, err = tokenUtil.AttemptTwoFactorChallenge(ctx, token)
+	assert.ErrorIs(t, err, util.ErrTwoFactorChallengeInvalid)
+}
+
+// TestDeviceName tests naming devices after their User-Agent header.
+func TestDeviceName(t *testing.T) {
+	tests := []struct {
+		userAgent string
+		expected  string
+	}{
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36", "Chrome on Windows"},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36 Edg/126.0.0.0", "Edge on Windows"},
+		{"Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Mobile/15E148 Safari/604.1", "Safari on iOS"},
+		{"Mozilla/5.0 (X11; Linux x86_64; rv:127.0) Gecko/20100101 Firefox/127.0", "Firefox on Linux"},
+		{"curl/8.5.0", "curl"},
+		{"", "Unknown device"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, util.DeviceName(tt.userAgent), tt.userAgent)
+	}
+}
@@ -0,0 +1,110 @@
+package util_test
+
+import (
+	"backend/internal/util"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfc6238Secret is the SHA-1 key of the RFC 6238 test vectors, "12345678901234567890" in base32.
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// TestTOTPUtil_GenerateCode tests the codes against the RFC 6238 test vectors, truncated to 6 digits.
+func TestTOTPUtil_GenerateCode(t *testing.T) {
+	totpUtil := util.NewTOTPUtil("Wallet App", "test-key")
+
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+	for unix, expected := range vectors {
+		code, err := totpUtil.GenerateCode(rfc6238Secret, time.Unix(unix, 0))
+		require.NoError(t, err)
+		assert.Equal(t, expected, code, "time %d", unix)
+	}
+}
+
+// TestTOTPUtil_Validate tests that a code is accepted one step around the current time and no further.
+func TestTOTPUtil_Validate(t *testing.T) {
+	totpUtil := util.NewTOTPUtil("Wallet App", "test-key")
+	secret, err := totpUtil.GenerateSecret()
+	require.NoError(t, err)
+
+	now := time.Unix(1700000000, 0)
+	code, err := totpUtil.GenerateCode(secret, now)
+	require.NoError(t, err)
+
+	step, ok := totpUtil.Validate(secret, code, now)
+	assert.True(t, ok)
+	assert.Equal(t, now.Unix()/30, step)
+
+	step, ok = totpUtil.Validate(secret, code, now.Add(30*time.Second))
+	assert.True(t, ok, "code of the previous step is accepted")
+	assert.Equal(t, now.Unix()/30, step)
+
+	_, ok = totpUtil.Validate(secret, code, now.Add(90*time.Second))
+	assert.False(t, ok, "code two steps old is rejected")
+
+	_, ok = totpUtil.Validate(secret, "12345", now)
+	assert.False(t, ok)
+	_, ok = totpUtil.Validate("not base32!", code, now)
+	assert.False(t, ok)
+}
+
+// TestTOTPUtil_EncryptSecret tests that secrets round-trip and only decrypt with the same key.
+func TestTOTPUtil_EncryptSecret(t *testing.T) {
+	totpUtil := util.NewTOTPUtil("Wallet App", "test-key")
+
+	encrypted, err := totpUtil.EncryptSecret(rfc6238Secret)
+	require.NoError(t, err)
+	assert.NotContains(t, encrypted, rfc6238Secret)
+
+	secret, err := totpUtil.DecryptSecret(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, rfc6238Secret, secret)
+
+	_, err = util.NewTOTPUtil("Wallet App", "other-key").DecryptSecret(encrypted)
+	assert.ErrorIs(t, err, util.ErrTOTPSecretInvalid)
+	_, err = totpUtil.DecryptSecret("garbage")
+	assert.ErrorIs(t, err, util.ErrTOTPSecretInvalid)
+}
+
+// TestTOTPUtil_ProvisioningURI tests the otpauth URI authenticator apps scan.
+func TestTOTPUtil_ProvisioningURI(t *testing.T) {
+	totpUtil := util.NewTOTPUtil("Wallet App", "test-key")
+
+	uri := totpUtil.ProvisioningURI("alice", rfc6238Secret)
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/Wallet%20App:alice?"))
+	assert.Contains(t, uri, "secret="+rfc6238Secret)
+	assert.Contains(t, uri, "issuer=Wallet+App")
+	assert.Contains(t, uri, "digits=6")
+	assert.Contains(t, uri, "period=30")
+}
+
+// TestTOTPUtil_RecoveryCodes tests that recovery codes are unique and hash the same however they are typed.
+func TestTOTPUtil_RecoveryCodes(t *testing.T) {
+	totpUtil := util.NewTOTPUtil("Wallet App", "test-key")
+
+	codes, err := totpUtil.GenerateRecoveryCodes(10)
+	require.NoError(t, err)
+	require.Len(t, codes, 10)
+
+	seen := map[string]bool{}
+	for _, code := range codes {
+		assert.Regexp(t, `^[a-z0-9]{5}-[a-z0-9]{5}$`, code)
+		assert.False(t, seen[code])
+		seen[code] = true
+	}
+
+	hash := totpUtil.HashRecoveryCode(codes[0])
+	assert.Equal(t, hash, totpUtil.HashRecoveryCode(" "+strings.ToUpper(strings.ReplaceAll(codes[0], "-", ""))+" "))
+	assert.NotEqual(t, hash, totpUtil.HashRecoveryCode(codes[1]))
+}
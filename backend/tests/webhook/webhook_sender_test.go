@@ -0,0 +1,177 @@
+package webhook_test
+
+import (
+	"backend/internal/delivery/webhook"
+	"backend/internal/entity"
+	"backend/internal/util"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// receivedRequest captures what the test receiver got.
+type receivedRequest struct {
+	Header http.Header
+	Body   []byte
+}
+
+// createTestReceiver starts a local https webhook receiver that answers with the given status.
+func createTestReceiver(t *testing.T, status int) (*httptest.Server, chan receivedRequest) {
+	received := make(chan receivedRequest, 1)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- receivedRequest{Header: r.Header.Clone(), Body: body}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, received
+}
+
+// createTestSender creates a Sender for testing that trusts the receiver certificate.
+// The receiver listens on loopback, so the transport of the test server replaces the public-only one.
+func createTestSender(server *httptest.Server) *webhook.Sender {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	sender := webhook.NewSender(log, 5*time.Second)
+	sender.Client.Transport = server.Client().Transport
+	return sender
+}
+
+// createTestDelivery creates a webhook delivery with a transaction payload.
+func createTestDelivery(t *testing.T) *entity.WebhookDelivery {
+	payload, err := json.Marshal(map[string]interface{}{
+		"id":      3,
+		"type":    "transaction",
+		"user_id": 7,
+		"payload": map[string]interface{}{"transaction_id": 42, "amount": "100"},
+	})
+	assert.NoError(t, err)
+	return &entity.WebhookDelivery{
+		ID:            9,
+		OutboxEventID: 3,
+		EventType:     entity.OutboxEventTypeTransaction,
+		Payload:       payload,
+	}
+}
+
+// TestSender_Send_SignsRequest tests that the receiver gets a body it can verify with the shared secret.
+func TestSender_Send_SignsRequest(t *testing.T) {
+	server, received := createTestReceiver(t, http.StatusOK)
+	sender := createTestSender(server)
+	endpoint := &entity.WebhookEndpoint{ID: 5, URL: server.URL, Secret: "top-secret", IsActive: true}
+	delivery := createTestDelivery(t)
+
+	status, err := sender.Send(context.Background(), endpoint, delivery)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+
+	request := <-received
+	assert.JSONEq(t, string(delivery.Payload), string(request.Body))
+	assert.Equal(t, "application/json", request.Header.Get("Content-Type"))
+	assert.Equal(t, "3", request.Header.Get(webhook.HeaderEventID))
+	assert.Equal(t, "transaction", request.Header.Get(webhook.HeaderEventType))
+
+	timestamp, err := strconv.ParseInt(request.Header.Get(webhook.HeaderTimestamp), 10, 64)
+	assert.NoError(t, err)
+	assert.Equal(t, webhook.Sign("top-secret", timestamp, request.Body), request.Header.Get(webhook.HeaderSignature))
+	assert.NotEqual(t, webhook.Sign("other-secret", timestamp, request.Body), request.Header.Get(webhook.HeaderSignature))
+}
+
+// TestSender_Send_NonSuccessStatus tests that a non-2xx answer is reported with its status code.
+func TestSender_Send_NonSuccessStatus(t *testing.T) {
+	server, received := createTestReceiver(t, http.StatusServiceUnavailable)
+	sender := createTestSender(server)
+	endpoint := &entity.WebhookEndpoint{ID: 5, URL: server.URL, Secret: "top-secret", IsActive: true}
+
+	status, err := sender.Send(context.Background(), endpoint, createTestDelivery(t))
+
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	<-received
+}
+
+// TestSender_Send_Unreachable tests that connection failures are returned without a status code.
+func TestSender_Send_Unreachable(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	sender := createTestSender(server)
+	endpoint := &entity.WebhookEndpoint{ID: 5, URL: url, Secret: "top-secret", IsActive: true}
+
+	status, err := sender.Send(context.Background(), endpoint, createTestDelivery(t))
+
+	assert.Error(t, err)
+	assert.Equal(t, 0, status)
+}
+
+// TestSender_Send_RejectsPlainHTTP tests that endpoints without https are not called.
+func TestSender_Send_RejectsPlainHTTP(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true }))
+	t.Cleanup(server.Close)
+
+	sender := createTestSender(server)
+	endpoint := &entity.WebhookEndpoint{ID: 5, URL: server.URL, Secret: "top-secret", IsActive: true}
+
+	status, err := sender.Send(context.Background(), endpoint, createTestDelivery(t))
+
+	assert.Error(t, err)
+	assert.Equal(t, 0, status)
+	assert.False(t, called)
+}
+
+// TestSender_Send_RejectsNonPublicAddress tests that the sender does not connect to loopback addresses.
+func TestSender_Send_RejectsNonPublicAddress(t *testing.T) {
+	called := false
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true }))
+	t.Cleanup(server.Close)
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	sender := webhook.NewSender(log, 5*time.Second)
+	endpoint := &entity.WebhookEndpoint{ID: 5, URL: server.URL, Secret: "top-secret", IsActive: true}
+
+	status, err := sender.Send(context.Background(), endpoint, createTestDelivery(t))
+
+	assert.ErrorIs(t, err, util.ErrNonPublicAddress)
+	assert.Equal(t, 0, status)
+	assert.False(t, called)
+}
+
+// TestSender_Send_DoesNotFollowRedirects tests that a redirect is reported as a failed delivery instead of followed.
+func TestSender_Send_DoesNotFollowRedirects(t *testing.T) {
+	target, received := createTestReceiver(t, http.StatusOK)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	}))
+	t.Cleanup(server.Close)
+
+	sender := createTestSender(server)
+	endpoint := &entity.WebhookEndpoint{ID: 5, URL: server.URL, Secret: "top-secret", IsActive: true}
+
+	status, err := sender.Send(context.Background(), endpoint, createTestDelivery(t))
+
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusTemporaryRedirect, status)
+	assert.Empty(t, received)
+}
+
+// TestSign_KnownValue tests the signature format against a fixed vector.
+func TestSign_KnownValue(t *testing.T) {
+	signature := webhook.Sign("secret", 1700000000, []byte(`{"id":1}`))
+
+	assert.Equal(t, "sha256=", signature[:7])
+	assert.Len(t, signature, 7+64)
+	assert.Equal(t, signature, webhook.Sign("secret", 1700000000, []byte(`{"id":1}`)))
+	assert.NotEqual(t, signature, webhook.Sign("secret", 1700000001, []byte(`{"id":1}`)))
+}
@@ -0,0 +1,122 @@
+package websocket_test
+
+import (
+	"backend/internal/delivery/websocket"
+	"backend/internal/model"
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupSSEApp creates a Fiber app serving the event stream of user ID 1.
+func setupSSEApp(hub *websocket.Hub) *fiber.App {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	config := websocket.DefaultClientConfig()
+	config.PingPeriod = 20 * time.Millisecond
+	handler := websocket.NewSSEHandler(hub, log, config)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		userID := uint(1)
+		c.Locals("auth", &model.Auth{
+			UserID:   &userID,
+			Username: "testuser",
+			Role:     "user",
+		})
+		return c.Next()
+	})
+	app.Get("/events", handler.Stream)
+	return app
+}
+
+// readEvent reads the next event from the stream, skipping heartbeat comments.
+func readEvent(t *testing.T, reader *bufio.Reader) (id string, data string) {
+	t.Helper()
+
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimRight(line, "\n")
+
+		switch {
+		case line == "":
+			if data != "" {
+				return id, data
+			}
+		case strings.HasPrefix(line, "id: "):
+			id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+// TestSSEHandler_StreamReplaysAndDeliversLive tests resuming after Last-Event-ID and receiving live events.
+func TestSSEHandler_StreamReplaysAndDeliversLive(t *testing.T) {
+	hub := createTestHub()
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, hub.BroadcastToUser(1, []byte(fmt.Sprintf(`{"type":"transaction","payload":%d}`, i))))
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	app := setupSSEApp(hub)
+	go func() { _ = app.Listener(listener) }()
+	defer app.Shutdown()
+
+	request, err := http.NewRequest(http.MethodGet, "http://"+listener.Addr().String()+"/events", nil)
+	require.NoError(t, err)
+	request.Header.Set("Last-Event-ID", "1")
+
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(response.Body)
+
+	id, data := readEvent(t, reader)
+	assert.Equal(t, "2", id)
+	assert.JSONEq(t, `{"seq":2,"type":"transaction","payload":2}`, data)
+
+	id, data = readEvent(t, reader)
+	assert.Equal(t, "3", id)
+	assert.JSONEq(t, `{"seq":3,"type":"transaction","payload":3}`, data)
+
+	// Live events arrive once the client is registered
+	assert.Eventually(t, func() bool { return hub.GetConnectionCount(1) == 1 }, time.Second, time.Millisecond)
+	require.NoError(t, hub.BroadcastToUser(1, []byte(`{"type":"wallet_update","payload":4}`)))
+
+	id, data = readEvent(t, reader)
+	assert.Equal(t, "4", id)
+	assert.JSONEq(t, `{"seq":4,"type":"wallet_update","payload":4}`, data)
+
+	// Closing the stream unregisters the client on the next heartbeat
+	require.NoError(t, response.Body.Close())
+	assert.Eventually(t, func() bool { return hub.GetConnectionCount(1) == 0 }, 2*time.Second, 10*time.Millisecond)
+}
+
+// TestSSEHandler_InvalidLastEventID tests rejecting a malformed Last-Event-ID.
+func TestSSEHandler_InvalidLastEventID(t *testing.T) {
+	app := setupSSEApp(createTestHub())
+
+	request := httptest.NewRequest(http.MethodGet, "/events", nil)
+	request.Header.Set("Last-Event-ID", "abc")
+
+	response, err := app.Test(request)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, response.StatusCode)
+}
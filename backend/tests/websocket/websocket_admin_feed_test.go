@@ -0,0 +1,240 @@
+package websocket_test
+
+import (
+	"backend/internal/delivery/websocket"
+	"backend/internal/model"
+	"backend/tests/mocks"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createAdminFeedNotification creates an admin feed transaction for testing.
+func createAdminFeedNotification(transactionType string, amount string) *model.AdminTransactionNotification {
+	fromUserID := uint(2)
+	fromUsername := "bob"
+	return &model.AdminTransactionNotification{
+		TransactionID:       1,
+		TransactionType:     transactionType,
+		Amount:              amount,
+		FromUserID:          &fromUserID,
+		FromUsername:        &fromUsername,
+		ToUserID:            3,
+		ToUsername:          "alice",
+		PerformedByUserID:   2,
+		PerformedByUsername: "bob",
+		CreatedAt:           time.Now().Format(time.RFC3339),
+	}
+}
+
+// TestAdminFeedFilter_Matches tests filtering by transaction type and minimum amount.
+func TestAdminFeedFilter_Matches(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   websocket.AdminFeedFilter
+		n        *model.AdminTransactionNotification
+		expected bool
+	}{
+		{name: "no filters", filter: websocket.AdminFeedFilter{}, n: createAdminFeedNotification("transfer", "1"), expected: true},
+		{name: "type matches", filter: websocket.AdminFeedFilter{TransactionTypes: []string{"top_up"}}, n: createAdminFeedNotification("top_up", "1"), expected: true},
+		{name: "type filtered", filter: websocket.AdminFeedFilter{TransactionTypes: []string{"top_up"}}, n: createAdminFeedNotification("transfer", "1"), expected: false},
+		{name: "amount equal to minimum", filter: websocket.AdminFeedFilter{MinAmount: decimal.NewFromInt(100)}, n: createAdminFeedNotification("transfer", "100"), expected: true},
+		{name: "amount below minimum", filter: websocket.AdminFeedFilter{MinAmount: decimal.NewFromInt(100)}, n: createAdminFeedNotification("transfer", "99.99"), expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.filter.Matches(tt.n))
+		})
+	}
+}
+
+// TestHandleCommand_SubscribeAdminFeed tests subscribing a super admin to the admin feed.
+func TestHandleCommand_SubscribeAdminFeed(t *testing.T) {
+	handler, _ := setupCommandHandler(new(mocks.MockWalletUseCase))
+	client := newTestClientWithRole(1, "super_admin", newFakeConn())
+
+	response := handler.HandleCommand(context.Background(), client, []byte(`{"id":"req-1","type":"subscribe_admin_feed","payload":{"min_amount":"50000","transaction_types":["transfer"]}}`))
+
+	assert.Equal(t, "response", response.Type)
+	assert.Equal(t, &model.AdminFeedCommandResponse{
+		MinAmount:        decimal.NewFromInt(50000),
+		TransactionTypes: []string{"transfer"},
+	}, response.Payload)
+	assert.True(t, client.AcceptsAdminFeed(createAdminFeedNotification("transfer", "50000")))
+	assert.False(t, client.AcceptsAdminFeed(createAdminFeedNotification("top_up", "50000")))
+
+	response = handler.HandleCommand(context.Background(), client, []byte(`{"id":"req-2","type":"unsubscribe_admin_feed"}`))
+	assert.Equal(t, "response", response.Type)
+	assert.False(t, client.AcceptsAdminFeed(createAdminFeedNotification("transfer", "50000")))
+}
+
+// TestHandleCommand_SubscribeAdminFeed_Rejected tests the role check and filter validation.
+func TestHandleCommand_SubscribeAdminFeed_Rejected(t *testing.T) {
+	handler, _ := setupCommandHandler(new(mocks.MockWalletUseCase))
+
+	tests := []struct {
+		name     string
+		role     string
+		message  string
+		expected int
+	}{
+		{name: "regular user", role: "user", message: `{"id":"req-1","type":"subscribe_admin_feed"}`, expected: fiber.StatusForbidden},
+		{name: "admin", role: "admin", message: `{"id":"req-1","type":"subscribe_admin_feed"}`, expected: fiber.StatusForbidden},
+		{name: "negative minimum", role: "super_admin", message: `{"id":"req-1","type":"subscribe_admin_feed","payload":{"min_amount":"-1"}}`, expected: fiber.StatusBadRequest},
+		{name: "unknown type", role: "super_admin", message: `{"id":"req-1","type":"subscribe_admin_feed","payload":{"transaction_types":["withdraw"]}}`, expected: fiber.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClientWithRole(1, tt.role, newFakeConn())
+			response := handler.HandleCommand(context.Background(), client, []byte(tt.message))
+
+			assert.Equal(t, "error", response.Type)
+			require.NotNil(t, response.Error)
+			assert.Equal(t, tt.expected, response.Error.Code)
+			assert.False(t, client.AcceptsAdminFeed(createAdminFeedNotification("transfer", "1")))
+		})
+	}
+}
+
+// TestHandleCommand_SubscribeAdminFeed_RequiresTwoFactor tests that a super admin session without two-factor authentication cannot stream the admin feed.
+func TestHandleCommand_SubscribeAdminFeed_RequiresTwoFactor(t *testing.T) {
+	handler, _ := setupCommandHandler(new(mocks.MockWalletUseCase))
+	userID := uint(1)
+	client := newTestClientWithAuth(&model.Auth{UserID: &userID, Role: "super_admin"}, newFakeConn())
+
+	response := handler.HandleCommand(context.Background(), client, []byte(`{"id":"req-1","type":"subscribe_admin_feed"}`))
+
+	assert.Equal(t, "error", response.Type)
+	require.NotNil(t, response.Error)
+	assert.Equal(t, fiber.StatusForbidden, response.Error.Code)
+	assert.False(t, client.AcceptsAdminFeed(createAdminFeedNotification("transfer", "1")))
+}
+
+// TestNotifier_NotifyAdminTransaction tests that admin feed messages only reach matching subscribers.
+func TestNotifier_NotifyAdminTransaction(t *testing.T) {
+	hub := createTestHub()
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	notifier := websocket.NewNotifier(hub, log)
+
+	largeTransfers := newFakeConn()
+	largeTransfersClient := newTestClientWithRole(1, "super_admin", largeTransfers)
+	largeTransfersClient.SetAdminFeed(&websocket.AdminFeedFilter{MinAmount: decimal.NewFromInt(1000)})
+
+	everything := newFakeConn()
+	everythingClient := newTestClientWithRole(1, "super_admin", everything)
+	everythingClient.SetAdminFeed(&websocket.AdminFeedFilter{})
+
+	notSubscribed := newFakeConn()
+	notSubscribedClient := newTestClientWithRole(4, "super_admin", notSubscribed)
+
+	for _, client := range []*websocket.Client{largeTransfersClient, everythingClient, notSubscribedClient} {
+		hub.Register(client)
+		defer func() {
+			hub.Unregister(client)
+			client.Close()
+			client.Wait()
+		}()
+	}
+
+	require.NoError(t, notifier.NotifyAdminTransaction(createAdminFeedNotification("transfer", "10")))
+	require.NoError(t, notifier.NotifyAdminTransaction(createAdminFeedNotification("transfer", "5000")))
+
+	assert.Eventually(t, func() bool { return len(everything.written()) == 2 }, time.Second, time.Millisecond)
+	assert.Eventually(t, func() bool { return len(largeTransfers.written()) == 1 }, time.Second, time.Millisecond)
+	assert.Empty(t, notSubscribed.written())
+
+	written := largeTransfers.written()[0]
+	assert.Contains(t, written, `"type":"admin_transaction"`)
+	assert.Contains(t, written, `"amount":"5000"`)
+	assert.Contains(t, written, `"from_username":"bob"`)
+	assert.Contains(t, written, `"to_username":"alice"`)
+	assert.NotContains(t, written, `"seq"`)
+}
+
+// TestHub_BroadcastToAdminFeed_PublishesThroughBroker tests that admin feed messages fan out over the broker.
+func TestHub_BroadcastToAdminFeed_PublishesThroughBroker(t *testing.T) {
+	broker := newFakeBroker()
+	hub := createTestHubWithBroker(broker)
+
+	require.NoError(t, hub.BroadcastToAdminFeed([]byte(`{"type":"admin_transaction","payload":{}}`)))
+
+	require.Len(t, broker.published, 1)
+	assert.True(t, broker.published[0].AdminFeed)
+	assert.JSONEq(t, `{"type":"admin_transaction","payload":{}}`, string(broker.published[0].Message))
+}
+
+// TestNotifier_NotifyAdminTransaction_RoleWithoutPermission tests that a subscriber whose role does not
+// grant the admin feed is dropped at fan-out.
+func TestNotifier_NotifyAdminTransaction_RoleWithoutPermission(t *testing.T) {
+	hub := createTestHub()
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	notifier := websocket.NewNotifier(hub, log)
+
+	conn := newFakeConn()
+	client := newTestClientWithRole(1, "admin", conn)
+	client.SetAdminFeed(&websocket.AdminFeedFilter{})
+	hub.Register(client)
+	defer func() {
+		hub.Unregister(client)
+		client.Close()
+		client.Wait()
+	}()
+
+	notification := createAdminFeedNotification("transfer", "10")
+	require.NoError(t, notifier.NotifyAdminTransaction(notification))
+
+	assert.Eventually(t, func() bool { return !client.AcceptsAdminFeed(notification) }, time.Second, time.Millisecond)
+	assert.Empty(t, conn.written())
+}
+
+// TestNotifier_NotifyAdminTransaction_Reauthorized tests that subscribers are re-checked at fan-out and that
+// a subscriber who lost the permission since subscribing stops receiving the feed.
+func TestNotifier_NotifyAdminTransaction_Reauthorized(t *testing.T) {
+	hub := createTestHub()
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	notifier := websocket.NewNotifier(hub, log)
+
+	allowedConn := newFakeConn()
+	allowed := newTestClientWithRole(1, "super_admin", allowedConn)
+	allowed.SetAdminFeed(&websocket.AdminFeedFilter{})
+
+	demotedConn := newFakeConn()
+	demoted := newTestClientWithRole(2, "super_admin", demotedConn)
+	demoted.SetAdminFeed(&websocket.AdminFeedFilter{})
+
+	hub.SetAdminFeedAuthorizer(func(ctx context.Context, client *websocket.Client) error {
+		if client.UserID == demoted.UserID {
+			return fiber.ErrForbidden
+		}
+		return nil
+	}, 0)
+
+	for _, client := range []*websocket.Client{allowed, demoted} {
+		hub.Register(client)
+		defer func() {
+			hub.Unregister(client)
+			client.Close()
+			client.Wait()
+		}()
+	}
+
+	notification := createAdminFeedNotification("transfer", "10")
+	require.NoError(t, notifier.NotifyAdminTransaction(notification))
+	require.NoError(t, notifier.NotifyAdminTransaction(notification))
+
+	assert.Eventually(t, func() bool { return len(allowedConn.written()) == 2 }, time.Second, time.Millisecond)
+	assert.False(t, demoted.AcceptsAdminFeed(notification))
+	assert.Empty(t, demotedConn.written())
+}
@@ -0,0 +1,237 @@
+package websocket_test
+
+import (
+	"backend/internal/config"
+	"backend/internal/delivery/websocket"
+	"backend/internal/model"
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBroker is an in-process Broker that fans messages out to every subscribed Hub.
+type fakeBroker struct {
+	mu          sync.Mutex
+	published   []*websocket.BrokerMessage
+	subscribers []func(message *websocket.BrokerMessage)
+	publishErr  error
+	subscribed  chan struct{}
+}
+
+func newFakeBroker() *fakeBroker {
+	return &fakeBroker{subscribed: make(chan struct{}, 10)}
+}
+
+func (b *fakeBroker) Publish(ctx context.Context, message *websocket.BrokerMessage) error {
+	b.mu.Lock()
+	if b.publishErr != nil {
+		b.mu.Unlock()
+		return b.publishErr
+	}
+	b.published = append(b.published, message)
+	subscribers := append([]func(*websocket.BrokerMessage){}, b.subscribers...)
+	b.mu.Unlock()
+
+	for _, handler := range subscribers {
+		handler(message)
+	}
+	return nil
+}
+
+func (b *fakeBroker) Subscribe(ctx context.Context, handler func(message *websocket.BrokerMessage)) error {
+	b.mu.Lock()
+	b.subscribers = append(b.subscribers, handler)
+	b.mu.Unlock()
+	b.subscribed <- struct{}{}
+
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func createTestHubWithBroker(broker websocket.Broker) *websocket.Hub {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	return websocket.NewHubWithBroker(log, broker, websocket.NewMemoryEventLog(100, time.Hour))
+}
+
+// TestHub_BroadcastToUser_PublishesThroughBroker tests that a Hub with a broker publishes instead of writing locally.
+func TestHub_BroadcastToUser_PublishesThroughBroker(t *testing.T) {
+	broker := newFakeBroker()
+	hub := createTestHubWithBroker(broker)
+
+	err := hub.BroadcastToUser(7, []byte(`{"type":"test"}`))
+	assert.NoError(t, err)
+
+	require.Len(t, broker.published, 1)
+	assert.Equal(t, uint(7), broker.published[0].UserID)
+	assert.JSONEq(t, `{"seq":1,"type":"test"}`, string(broker.published[0].Message))
+}
+
+// TestHub_BroadcastToUser_PublishError tests that a publish failure is returned to the caller,
+// so the outbox dispatcher retries the event.
+func TestHub_BroadcastToUser_PublishError(t *testing.T) {
+	broker := newFakeBroker()
+	broker.publishErr = errors.New("redis unavailable")
+	hub := createTestHubWithBroker(broker)
+
+	err := hub.BroadcastToUser(7, []byte(`{"type":"test"}`))
+	assert.EqualError(t, err, "redis unavailable")
+}
+
+// TestHub_BroadcastEventToUser_RetryKeepsSeq tests that an event retried after a failed publish
+// is published with the seq it was logged with first, instead of being logged again.
+func TestHub_BroadcastEventToUser_RetryKeepsSeq(t *testing.T) {
+	broker := newFakeBroker()
+	broker.publishErr = errors.New("redis unavailable")
+	hub := createTestHubWithBroker(broker)
+
+	err := hub.BroadcastEventToUser(7, "outbox:1", []byte(`{"type":"test","payload":1}`))
+	assert.EqualError(t, err, "redis unavailable")
+
+	broker.publishErr = nil
+	require.NoError(t, hub.BroadcastEventToUser(7, "outbox:1", []byte(`{"type":"test","payload":1}`)))
+	require.NoError(t, hub.BroadcastEventToUser(7, "outbox:2", []byte(`{"type":"test","payload":2}`)))
+
+	require.Len(t, broker.published, 2)
+	assert.JSONEq(t, `{"seq":1,"type":"test","payload":1}`, string(broker.published[0].Message))
+	assert.JSONEq(t, `{"seq":2,"type":"test","payload":2}`, string(broker.published[1].Message))
+}
+
+// TestHub_Run_DeliversFanOutMessages tests that every running Hub receives messages published by another instance.
+func TestHub_Run_DeliversFanOutMessages(t *testing.T) {
+	broker := newFakeBroker()
+	instanceA := createTestHubWithBroker(broker)
+	instanceB := createTestHubWithBroker(broker)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan struct{}, 2)
+	for _, hub := range []*websocket.Hub{instanceA, instanceB} {
+		go func() {
+			hub.Run(ctx)
+			done <- struct{}{}
+		}()
+	}
+	for range 2 {
+		select {
+		case <-broker.subscribed:
+		case <-time.After(time.Second):
+			t.Fatal("hub did not subscribe to the broker")
+		}
+	}
+
+	// No local connections, delivery on both instances must not fail
+	assert.NoError(t, instanceA.BroadcastToUser(1, []byte(`{"type":"test"}`)))
+	assert.Len(t, broker.subscribers, 2)
+
+	cancel()
+	for range 2 {
+		select {
+		case <-done:
+		case <-time.After(2 * time.Second):
+			t.Fatal("hub did not stop after cancellation")
+		}
+	}
+}
+
+// TestHub_Disconnect_ThroughBroker tests that a disconnect reaches the connections of the user on another instance.
+func TestHub_Disconnect_ThroughBroker(t *testing.T) {
+	broker := newFakeBroker()
+	instanceA := createTestHubWithBroker(broker)
+	instanceB := createTestHubWithBroker(broker)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go instanceB.Run(ctx)
+	select {
+	case <-broker.subscribed:
+	case <-time.After(time.Second):
+		t.Fatal("hub did not subscribe to the broker")
+	}
+
+	conn := newFakeConn()
+	client := newTestClient(3, conn)
+	instanceB.Register(client)
+	defer func() {
+		instanceB.Unregister(client)
+		client.Close()
+		client.Wait()
+	}()
+
+	require.NoError(t, instanceA.Disconnect(&model.DisconnectRequest{UserID: 3}))
+
+	require.Len(t, broker.published, 1)
+	assert.Equal(t, uint(3), broker.published[0].Disconnect.UserID)
+	assert.True(t, conn.isClosed())
+}
+
+// TestHub_Run_MemoryReturnsImmediately tests that Run is a no-op for an in-memory Hub.
+func TestHub_Run_MemoryReturnsImmediately(t *testing.T) {
+	hub := createTestHub()
+
+	done := make(chan struct{})
+	go func() {
+		hub.Run(context.Background())
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("in-memory hub Run should return immediately")
+	}
+}
+
+// TestRedisBroker_PublishSubscribe tests a round trip through a real Redis server configured
+// through .env or REDIS_* environment variables. Without REDIS_HOST it is skipped.
+func TestRedisBroker_PublishSubscribe(t *testing.T) {
+	viperConfig := config.NewViper()
+	if viperConfig.GetString("REDIS_HOST") == "" {
+		t.Skip("REDIS_HOST is not configured, skipping Redis broker test")
+	}
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	client := config.NewRedisClient(viperConfig)
+	defer client.Close()
+
+	broker := websocket.NewRedisBroker(client, "websocket:broadcast:test", log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	received := make(chan *websocket.BrokerMessage, 1)
+	go func() {
+		_ = broker.Subscribe(ctx, func(message *websocket.BrokerMessage) {
+			select {
+			case received <- message:
+			default:
+			}
+		})
+	}()
+
+	// Publish until the subscription is live, pub/sub drops messages without subscribers
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		require.NoError(t, broker.Publish(ctx, &websocket.BrokerMessage{UserID: 42, Message: []byte(`{"type":"wallet_update"}`)}))
+
+		select {
+		case message := <-received:
+			assert.Equal(t, uint(42), message.UserID)
+			assert.JSONEq(t, `{"type":"wallet_update"}`, string(message.Message))
+			return
+		case <-ctx.Done():
+			t.Fatal("message was not received from Redis")
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,421 @@
+package websocket_test
+
+import (
+	"backend/internal/delivery/websocket"
+	"backend/internal/model"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeConn is an in-memory Conn that records writes and detects concurrent writers.
+type fakeConn struct {
+	mu            sync.Mutex
+	messages      [][]byte
+	pings         int
+	writeDeadline time.Time
+	readDeadline  time.Time
+	pongHandler   func(appData string) error
+	concurrent    bool
+	writing       bool
+	// block, when set, holds every write until it is closed or the connection is closed
+	block  chan struct{}
+	closed chan struct{}
+	once   sync.Once
+	// pingErr is returned by pings
+	pingErr error
+}
+
+func newFakeConn() *fakeConn {
+	return &fakeConn{closed: make(chan struct{})}
+}
+
+func (c *fakeConn) WriteMessage(messageType int, data []byte) error {
+	c.mu.Lock()
+	if c.writing {
+		c.concurrent = true
+	}
+	c.writing = true
+	block := c.block
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		c.writing = false
+		c.mu.Unlock()
+	}()
+
+	if block != nil {
+		select {
+		case <-block:
+		case <-c.closed:
+		}
+	}
+
+	select {
+	case <-c.closed:
+		return errors.New("connection closed")
+	default:
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.messages = append(c.messages, data)
+	return nil
+}
+
+func (c *fakeConn) WriteControl(messageType int, data []byte, deadline time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pingErr != nil {
+		return c.pingErr
+	}
+	c.pings++
+	return nil
+}
+
+func (c *fakeConn) SetWriteDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.writeDeadline = t
+	return nil
+}
+
+func (c *fakeConn) SetReadDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.readDeadline = t
+	return nil
+}
+
+func (c *fakeConn) SetPongHandler(h func(appData string) error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pongHandler = h
+}
+
+func (c *fakeConn) Close() error {
+	c.once.Do(func() { close(c.closed) })
+	return nil
+}
+
+func (c *fakeConn) written() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	messages := make([]string, len(c.messages))
+	for i, message := range c.messages {
+		messages[i] = string(message)
+	}
+	return messages
+}
+
+func (c *fakeConn) pingCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.pings
+}
+
+func (c *fakeConn) isClosed() bool {
+	select {
+	case <-c.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// newTestClient creates a Client of a regular user with the default configuration and a silent logger.
+func newTestClient(userID uint, conn websocket.Conn) *websocket.Client {
+	return newTestClientWithRole(userID, "user", conn)
+}
+
+func newTestClientWithRole(userID uint, role string, conn websocket.Conn) *websocket.Client {
+	return newTestClientWithAuth(&model.Auth{UserID: &userID, Role: role, TwoFactor: true}, conn)
+}
+
+func newTestClientWithAuth(auth *model.Auth, conn websocket.Conn) *websocket.Client {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	return websocket.NewClient(auth, conn, websocket.DefaultClientConfig(), log)
+}
+
+func newTestClientWithConfig(userID uint, conn websocket.Conn, config websocket.ClientConfig) *websocket.Client {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	return websocket.NewClient(&model.Auth{UserID: &userID, Role: "user"}, conn, config, log)
+}
+
+// TestClient_SendWritesInOrder tests that queued messages are written in order within a write deadline.
+func TestClient_SendWritesInOrder(t *testing.T) {
+	conn := newFakeConn()
+	client := newTestClient(1, conn)
+	client.Start()
+	defer func() {
+		client.Close()
+		client.Wait()
+	}()
+
+	var expected []string
+	for i := range 10 {
+		message := fmt.Sprintf(`{"type":"test","payload":%d}`, i)
+		expected = append(expected, message)
+		assert.True(t, client.Send([]byte(message)))
+	}
+
+	assert.Eventually(t, func() bool { return len(conn.written()) == 10 }, time.Second, time.Millisecond)
+	assert.Equal(t, expected, conn.written())
+
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+	assert.False(t, conn.writeDeadline.IsZero())
+}
+
+// TestClient_ConcurrentSendsAreSerialized tests that concurrent producers never write to the connection at the same time.
+func TestClient_ConcurrentSendsAreSerialized(t *testing.T) {
+	conn := newFakeConn()
+	config := websocket.DefaultClientConfig()
+	config.SendQueueSize = 1000
+	client := newTestClientWithConfig(1, conn, config)
+	client.Start()
+	defer func() {
+		client.Close()
+		client.Wait()
+	}()
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 25 {
+				client.Send([]byte(`{"type":"test"}`))
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Eventually(t, func() bool { return len(conn.written()) == 500 }, time.Second, time.Millisecond)
+
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+	assert.False(t, conn.concurrent, "connection was written concurrently")
+}
+
+// TestClient_SlowConsumerIsEvicted tests that a full queue closes the client instead of blocking the producer.
+func TestClient_SlowConsumerIsEvicted(t *testing.T) {
+	conn := newFakeConn()
+	conn.block = make(chan struct{})
+	config := websocket.DefaultClientConfig()
+	config.SendQueueSize = 2
+	client := newTestClientWithConfig(1, conn, config)
+	client.Start()
+
+	// The first message is taken by the blocked writer, the next two fill the queue
+	assert.True(t, client.Send([]byte(`{"type":"test","payload":1}`)))
+	assert.Eventually(t, func() bool {
+		conn.mu.Lock()
+		defer conn.mu.Unlock()
+		return conn.writing
+	}, time.Second, time.Millisecond)
+	assert.True(t, client.Send([]byte(`{"type":"test","payload":2}`)))
+	assert.True(t, client.Send([]byte(`{"type":"test","payload":3}`)))
+
+	done := make(chan bool)
+	go func() { done <- client.Send([]byte(`{"type":"test","payload":4}`)) }()
+
+	select {
+	case sent := <-done:
+		assert.False(t, sent)
+	case <-time.After(time.Second):
+		t.Fatal("Send blocked on a slow consumer")
+	}
+
+	client.Wait()
+	assert.True(t, conn.isClosed())
+	assert.False(t, client.Send([]byte(`{"type":"test"}`)))
+
+	select {
+	case <-client.Done():
+	default:
+		t.Fatal("evicted client should be done")
+	}
+}
+
+// TestClient_SendsPings tests that the writer pings the peer periodically.
+func TestClient_SendsPings(t *testing.T) {
+	conn := newFakeConn()
+	config := websocket.DefaultClientConfig()
+	config.PingPeriod = 5 * time.Millisecond
+	client := newTestClientWithConfig(1, conn, config)
+	client.Start()
+	defer func() {
+		client.Close()
+		client.Wait()
+	}()
+
+	assert.Eventually(t, func() bool { return conn.pingCount() >= 2 }, time.Second, time.Millisecond)
+}
+
+// TestClient_FailedPingClosesClient tests that a dead peer is dropped when a ping cannot be written.
+func TestClient_FailedPingClosesClient(t *testing.T) {
+	conn := newFakeConn()
+	conn.pingErr = errors.New("broken pipe")
+	config := websocket.DefaultClientConfig()
+	config.PingPeriod = 5 * time.Millisecond
+	client := newTestClientWithConfig(1, conn, config)
+	client.Start()
+
+	client.Wait()
+	assert.True(t, conn.isClosed())
+}
+
+// TestClient_PongExtendsReadDeadline tests that pongs from the peer extend the read deadline.
+func TestClient_PongExtendsReadDeadline(t *testing.T) {
+	conn := newFakeConn()
+	client := newTestClient(1, conn)
+
+	require.NoError(t, client.PrepareRead())
+
+	conn.mu.Lock()
+	first := conn.readDeadline
+	pongHandler := conn.pongHandler
+	conn.mu.Unlock()
+
+	assert.WithinDuration(t, time.Now().Add(websocket.DefaultClientConfig().PongWait), first, time.Second)
+	require.NotNil(t, pongHandler)
+
+	time.Sleep(5 * time.Millisecond)
+	require.NoError(t, pongHandler(""))
+
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+	assert.True(t, conn.readDeadline.After(first))
+}
+
+// TestHub_DeliversToRegisteredClient tests delivery through the queue of a registered client.
+func TestHub_DeliversToRegisteredClient(t *testing.T) {
+	hub := createTestHub()
+	conn := newFakeConn()
+	client := newTestClient(1, conn)
+
+	hub.Register(client)
+	defer func() {
+		hub.Unregister(client)
+		client.Close()
+		client.Wait()
+	}()
+	assert.Equal(t, 1, hub.GetConnectionCount(1))
+
+	client.Unsubscribe("transaction")
+	require.NoError(t, hub.BroadcastToUser(1, []byte(`{"type":"transaction","payload":{}}`)))
+	require.NoError(t, hub.BroadcastToUser(1, []byte(`{"type":"wallet_update","payload":{}}`)))
+	require.NoError(t, hub.BroadcastToUser(2, []byte(`{"type":"wallet_update","payload":{}}`)))
+
+	assert.Eventually(t, func() bool { return len(conn.written()) == 1 }, time.Second, time.Millisecond)
+	assert.JSONEq(t, `{"seq":2,"type":"wallet_update","payload":{}}`, conn.written()[0])
+}
+
+// TestHub_ConnectReplaysBeforeLiveMessages tests that missed events are written before live ones.
+func TestHub_ConnectReplaysBeforeLiveMessages(t *testing.T) {
+	hub := createTestHub()
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, hub.BroadcastToUser(1, []byte(fmt.Sprintf(`{"type":"transaction","payload":%d}`, i))))
+	}
+
+	conn := newFakeConn()
+	client := newTestClient(1, conn)
+	since := uint64(1)
+	require.NoError(t, hub.Connect(context.Background(), client, &since))
+	defer func() {
+		hub.Unregister(client)
+		client.Close()
+		client.Wait()
+	}()
+
+	require.NoError(t, hub.BroadcastToUser(1, []byte(`{"type":"transaction","payload":4}`)))
+
+	assert.Eventually(t, func() bool { return len(conn.written()) == 3 }, time.Second, time.Millisecond)
+	written := conn.written()
+	assert.JSONEq(t, `{"seq":2,"type":"transaction","payload":2}`, written[0])
+	assert.JSONEq(t, `{"seq":3,"type":"transaction","payload":3}`, written[1])
+	assert.JSONEq(t, `{"seq":4,"type":"transaction","payload":4}`, written[2])
+}
+
+// TestHub_ConnectRequestsResync tests the resync_required message when missed events were dropped.
+func TestHub_ConnectRequestsResync(t *testing.T) {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	hub := websocket.NewHub(log, websocket.NewMemoryEventLog(2, time.Hour))
+	for range 5 {
+		require.NoError(t, hub.BroadcastToUser(1, []byte(`{"type":"transaction","payload":{}}`)))
+	}
+
+	conn := newFakeConn()
+	client := newTestClient(1, conn)
+	since := uint64(1)
+	require.NoError(t, hub.Connect(context.Background(), client, &since))
+	defer func() {
+		hub.Unregister(client)
+		client.Close()
+		client.Wait()
+	}()
+
+	written := conn.written()
+	require.Len(t, written, 1)
+	assert.JSONEq(t, `{"type":"resync_required","payload":{"since":1,"latest_seq":5}}`, written[0])
+}
+
+// racingEventLog logs another event for the user while the hub reads the log, like a concurrent broadcast.
+type racingEventLog struct {
+	*websocket.MemoryEventLog
+	hub  *websocket.Hub
+	once sync.Once
+}
+
+func (l *racingEventLog) Since(ctx context.Context, userID uint, seq uint64) ([]websocket.LoggedEvent, uint64, error) {
+	l.once.Do(func() {
+		_ = l.hub.BroadcastToUser(userID, []byte(`{"type":"transaction","payload":3}`))
+	})
+	return l.MemoryEventLog.Since(ctx, userID, seq)
+}
+
+// TestHub_ConnectDropsReplayedDuplicates tests that an event broadcast during the replay is written once.
+func TestHub_ConnectDropsReplayedDuplicates(t *testing.T) {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	eventLog := &racingEventLog{MemoryEventLog: websocket.NewMemoryEventLog(10, time.Hour)}
+	hub := websocket.NewHub(log, eventLog)
+	eventLog.hub = hub
+	for i := 1; i <= 2; i++ {
+		require.NoError(t, hub.BroadcastToUser(1, []byte(fmt.Sprintf(`{"type":"transaction","payload":%d}`, i))))
+	}
+
+	conn := newFakeConn()
+	client := newTestClient(1, conn)
+	since := uint64(1)
+	require.NoError(t, hub.Connect(context.Background(), client, &since))
+	defer func() {
+		hub.Unregister(client)
+		client.Close()
+		client.Wait()
+	}()
+
+	require.NoError(t, hub.BroadcastToUser(1, []byte(`{"type":"transaction","payload":4}`)))
+
+	assert.Eventually(t, func() bool { return len(conn.written()) >= 3 }, time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	written := conn.written()
+	require.Len(t, written, 3)
+	assert.JSONEq(t, `{"seq":2,"type":"transaction","payload":2}`, written[0])
+	assert.JSONEq(t, `{"seq":3,"type":"transaction","payload":3}`, written[1])
+	assert.JSONEq(t, `{"seq":4,"type":"transaction","payload":4}`, written[2])
+}
@@ -0,0 +1,143 @@
+package websocket_test
+
+import (
+	"backend/internal/config"
+	"backend/internal/delivery/websocket"
+	"backend/internal/model"
+	"backend/tests/mocks"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// setupCommandHandler creates a Handler with an in-memory Hub for command tests.
+func setupCommandHandler(walletUseCase *mocks.MockWalletUseCase) (*websocket.Handler, *websocket.Hub) {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	hub := websocket.NewHub(log, websocket.NewMemoryEventLog(100, time.Hour))
+	return websocket.NewHandler(hub, nil, nil, walletUseCase, config.NewValidator(), log, websocket.DefaultClientConfig(), []string{"super_admin"}), hub
+}
+
+// TestHandleCommand_GetBalance tests the get_balance command.
+func TestHandleCommand_GetBalance(t *testing.T) {
+	walletUseCase := new(mocks.MockWalletUseCase)
+	handler, _ := setupCommandHandler(walletUseCase)
+
+	wallet := &model.WalletResponse{ID: 1, UserID: 1, Balance: decimal.NewFromInt(100000), HeldBalance: decimal.Zero}
+	walletUseCase.On("GetByUserID", mock.Anything, uint(1)).Return(wallet, nil)
+
+	response := handler.HandleCommand(context.Background(), newTestClient(1, nil), []byte(`{"id":"req-1","type":"get_balance"}`))
+
+	assert.Equal(t, "req-1", response.ID)
+	assert.Equal(t, "response", response.Type)
+	assert.Equal(t, "get_balance", response.Command)
+	assert.Equal(t, wallet, response.Payload)
+	assert.Nil(t, response.Error)
+	walletUseCase.AssertExpectations(t)
+}
+
+// TestHandleCommand_GetBalance_NotFound tests that use case errors keep their status code.
+func TestHandleCommand_GetBalance_NotFound(t *testing.T) {
+	walletUseCase := new(mocks.MockWalletUseCase)
+	handler, _ := setupCommandHandler(walletUseCase)
+
+	walletUseCase.On("GetByUserID", mock.Anything, uint(1)).Return(nil, fiber.NewError(fiber.StatusNotFound, "Wallet not found"))
+
+	response := handler.HandleCommand(context.Background(), newTestClient(1, nil), []byte(`{"id":"req-1","type":"get_balance"}`))
+
+	assert.Equal(t, "req-1", response.ID)
+	assert.Equal(t, "error", response.Type)
+	require.NotNil(t, response.Error)
+	assert.Equal(t, fiber.StatusNotFound, response.Error.Code)
+	assert.Equal(t, "Wallet not found", response.Error.Message)
+}
+
+// TestHandleCommand_InvalidMessages tests rejecting malformed commands.
+func TestHandleCommand_InvalidMessages(t *testing.T) {
+	handler, _ := setupCommandHandler(new(mocks.MockWalletUseCase))
+
+	tests := []struct {
+		name    string
+		message string
+	}{
+		{name: "invalid json", message: `not json`},
+		{name: "missing id", message: `{"type":"ping"}`},
+		{name: "missing type", message: `{"id":"req-1"}`},
+		{name: "unknown type", message: `{"id":"req-1","type":"withdraw"}`},
+		{name: "ack without payload", message: `{"id":"req-1","type":"ack"}`},
+		{name: "ack with zero seq", message: `{"id":"req-1","type":"ack","payload":{"seq":0}}`},
+		{name: "subscribe unknown event type", message: `{"id":"req-1","type":"subscribe","payload":{"event_types":["resync_required"]}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := handler.HandleCommand(context.Background(), newTestClient(1, nil), []byte(tt.message))
+
+			assert.Equal(t, "error", response.Type)
+			require.NotNil(t, response.Error)
+			assert.Equal(t, fiber.StatusBadRequest, response.Error.Code)
+		})
+	}
+}
+
+// TestHandleCommand_Ping tests the ping command.
+func TestHandleCommand_Ping(t *testing.T) {
+	handler, _ := setupCommandHandler(new(mocks.MockWalletUseCase))
+
+	response := handler.HandleCommand(context.Background(), newTestClient(1, nil), []byte(`{"id":"req-1","type":"ping"}`))
+
+	assert.Equal(t, "response", response.Type)
+	assert.Equal(t, "ping", response.Command)
+	assert.IsType(t, &model.PingCommandResponse{}, response.Payload)
+}
+
+// TestHandleCommand_SubscribeUnsubscribe tests changing the event types delivered to a connection.
+func TestHandleCommand_SubscribeUnsubscribe(t *testing.T) {
+	handler, _ := setupCommandHandler(new(mocks.MockWalletUseCase))
+	client := newTestClient(1, nil)
+
+	response := handler.HandleCommand(context.Background(), client, []byte(`{"id":"req-1","type":"unsubscribe","payload":{"event_types":["transaction"]}}`))
+	assert.Equal(t, "response", response.Type)
+	assert.Equal(t, &model.SubscriptionCommandResponse{EventTypes: []string{"wallet_update"}}, response.Payload)
+	assert.False(t, client.Accepts("transaction"))
+	assert.True(t, client.Accepts("wallet_update"))
+	assert.True(t, client.Accepts("resync_required"))
+
+	response = handler.HandleCommand(context.Background(), client, []byte(`{"id":"req-2","type":"subscribe","payload":{"event_types":["transaction"]}}`))
+	assert.Equal(t, "response", response.Type)
+	assert.Equal(t, &model.SubscriptionCommandResponse{EventTypes: []string{"transaction", "wallet_update"}}, response.Payload)
+	assert.True(t, client.Accepts("transaction"))
+}
+
+// TestHandleCommand_Ack tests acknowledging delivered notifications.
+func TestHandleCommand_Ack(t *testing.T) {
+	handler, hub := setupCommandHandler(new(mocks.MockWalletUseCase))
+	client := newTestClient(1, nil)
+
+	for range 3 {
+		require.NoError(t, hub.BroadcastToUser(1, []byte(`{"type":"transaction","payload":{}}`)))
+	}
+
+	response := handler.HandleCommand(context.Background(), client, []byte(`{"id":"req-1","type":"ack","payload":{"seq":2}}`))
+	assert.Equal(t, "response", response.Type)
+	assert.Equal(t, &model.AckCommandResponse{Seq: 2}, response.Payload)
+
+	// Acknowledging an older seq keeps the cursor
+	response = handler.HandleCommand(context.Background(), client, []byte(`{"id":"req-2","type":"ack","payload":{"seq":1}}`))
+	assert.Equal(t, &model.AckCommandResponse{Seq: 2}, response.Payload)
+
+	// Events that were not sent cannot be acknowledged
+	response = handler.HandleCommand(context.Background(), client, []byte(`{"id":"req-3","type":"ack","payload":{"seq":10}}`))
+	assert.Equal(t, "error", response.Type)
+	require.NotNil(t, response.Error)
+	assert.Equal(t, fiber.StatusBadRequest, response.Error.Code)
+}
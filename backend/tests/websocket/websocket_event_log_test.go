@@ -0,0 +1,250 @@
+package websocket_test
+
+import (
+	"backend/internal/config"
+	"backend/internal/delivery/websocket"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMemoryEventLog_AppendAssignsSequence tests that messages are numbered per user.
+func TestMemoryEventLog_AppendAssignsSequence(t *testing.T) {
+	eventLog := websocket.NewMemoryEventLog(10, time.Hour)
+	ctx := context.Background()
+
+	first, err := eventLog.Append(ctx, 1, []byte(`{"type":"transaction","payload":{}}`))
+	require.NoError(t, err)
+	second, err := eventLog.Append(ctx, 1, []byte(`{"type":"wallet_update","payload":{}}`))
+	require.NoError(t, err)
+	other, err := eventLog.Append(ctx, 2, []byte(`{"type":"transaction","payload":{}}`))
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{"seq":1,"type":"transaction","payload":{}}`, string(first))
+	assert.JSONEq(t, `{"seq":2,"type":"wallet_update","payload":{}}`, string(second))
+	assert.JSONEq(t, `{"seq":1,"type":"transaction","payload":{}}`, string(other))
+}
+
+// TestMemoryEventLog_AppendRejectsNonObject tests that only JSON objects can be logged.
+func TestMemoryEventLog_AppendRejectsNonObject(t *testing.T) {
+	eventLog := websocket.NewMemoryEventLog(10, time.Hour)
+
+	_, err := eventLog.Append(context.Background(), 1, []byte(`{}`))
+	assert.Error(t, err)
+	_, err = eventLog.Append(context.Background(), 1, []byte(`[1]`))
+	assert.Error(t, err)
+}
+
+// TestMemoryEventLog_Since tests returning the events after a sequence number.
+func TestMemoryEventLog_Since(t *testing.T) {
+	eventLog := websocket.NewMemoryEventLog(10, time.Hour)
+	ctx := context.Background()
+
+	for i := 1; i <= 5; i++ {
+		_, err := eventLog.Append(ctx, 1, []byte(fmt.Sprintf(`{"type":"test","payload":%d}`, i)))
+		require.NoError(t, err)
+	}
+
+	events, latest, err := eventLog.Since(ctx, 1, 3)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), latest)
+	require.Len(t, events, 2)
+	assert.Equal(t, uint64(4), events[0].Seq)
+	assert.JSONEq(t, `{"seq":4,"type":"test","payload":4}`, string(events[0].Message))
+	assert.Equal(t, uint64(5), events[1].Seq)
+}
+
+// TestMemoryEventLog_SinceUnknownUser tests reading the log of a user without events.
+func TestMemoryEventLog_SinceUnknownUser(t *testing.T) {
+	eventLog := websocket.NewMemoryEventLog(10, time.Hour)
+
+	events, latest, err := eventLog.Since(context.Background(), 99, 0)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+	assert.Equal(t, uint64(0), latest)
+}
+
+// TestMemoryEventLog_KeepsLatestEvents tests that the log is bounded per user.
+func TestMemoryEventLog_KeepsLatestEvents(t *testing.T) {
+	eventLog := websocket.NewMemoryEventLog(3, time.Hour)
+	ctx := context.Background()
+
+	for i := 1; i <= 10; i++ {
+		_, err := eventLog.Append(ctx, 1, []byte(`{"type":"test"}`))
+		require.NoError(t, err)
+	}
+
+	events, latest, err := eventLog.Since(ctx, 1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(10), latest)
+	require.Len(t, events, 3)
+	assert.Equal(t, uint64(8), events[0].Seq)
+	assert.Equal(t, uint64(10), events[2].Seq)
+}
+
+// TestMemoryEventLog_Ack tests moving the acknowledged sequence number forward.
+func TestMemoryEventLog_Ack(t *testing.T) {
+	eventLog := websocket.NewMemoryEventLog(10, time.Hour)
+	ctx := context.Background()
+
+	_, ok, err := eventLog.Acked(ctx, 1)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	for range 3 {
+		_, err := eventLog.Append(ctx, 1, []byte(`{"type":"test"}`))
+		require.NoError(t, err)
+	}
+
+	acked, err := eventLog.Ack(ctx, 1, 3)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), acked)
+
+	acked, err = eventLog.Ack(ctx, 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), acked)
+
+	_, err = eventLog.Ack(ctx, 1, 4)
+	assert.ErrorIs(t, err, websocket.ErrAckAhead)
+
+	acked, ok, err = eventLog.Acked(ctx, 1)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(3), acked)
+}
+
+// TestMemoryEventLog_AppendOnce tests that a key is logged once while its event is retained.
+func TestMemoryEventLog_AppendOnce(t *testing.T) {
+	eventLog := websocket.NewMemoryEventLog(2, time.Hour)
+	ctx := context.Background()
+
+	first, err := eventLog.AppendOnce(ctx, 1, "outbox:1", []byte(`{"type":"test","payload":1}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"seq":1,"type":"test","payload":1}`, string(first))
+
+	again, err := eventLog.AppendOnce(ctx, 1, "outbox:1", []byte(`{"type":"test","payload":1}`))
+	require.NoError(t, err)
+	assert.Equal(t, first, again)
+
+	// The key of another user is its own
+	other, err := eventLog.AppendOnce(ctx, 2, "outbox:1", []byte(`{"type":"test","payload":1}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"seq":1,"type":"test","payload":1}`, string(other))
+
+	// Once the event is no longer retained its key is forgotten
+	for range 2 {
+		_, err := eventLog.Append(ctx, 1, []byte(`{"type":"test"}`))
+		require.NoError(t, err)
+	}
+	again, err = eventLog.AppendOnce(ctx, 1, "outbox:1", []byte(`{"type":"test","payload":1}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"seq":4,"type":"test","payload":1}`, string(again))
+
+	_, latest, err := eventLog.Since(ctx, 1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), latest)
+}
+
+// TestMemoryEventLog_Expires tests that the log of a user without new events is dropped after the ttl.
+func TestMemoryEventLog_Expires(t *testing.T) {
+	eventLog := websocket.NewMemoryEventLog(10, 50*time.Millisecond)
+	ctx := context.Background()
+
+	_, err := eventLog.Append(ctx, 1, []byte(`{"type":"test"}`))
+	require.NoError(t, err)
+	_, err = eventLog.Ack(ctx, 1, 1)
+	require.NoError(t, err)
+
+	time.Sleep(100 * time.Millisecond)
+
+	events, latest, err := eventLog.Since(ctx, 1, 0)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+	assert.Equal(t, uint64(0), latest)
+
+	_, ok, err := eventLog.Acked(ctx, 1)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// The sequence starts over, clients still holding a later seq are told to resync
+	logged, err := eventLog.Append(ctx, 1, []byte(`{"type":"test"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"seq":1,"type":"test"}`, string(logged))
+}
+
+// TestRedisEventLog_AppendAndSince tests the Redis event log against a real Redis server
+// configured through .env or REDIS_* environment variables. Without REDIS_HOST it is skipped.
+func TestRedisEventLog_AppendAndSince(t *testing.T) {
+	viperConfig := config.NewViper()
+	if viperConfig.GetString("REDIS_HOST") == "" {
+		t.Skip("REDIS_HOST is not configured, skipping Redis event log test")
+	}
+
+	client := config.NewRedisClient(viperConfig)
+	defer client.Close()
+
+	eventLog := websocket.NewRedisEventLog(client, 3, time.Minute)
+	ctx := context.Background()
+	userID := uint(time.Now().UnixNano() % 1_000_000_000)
+
+	for i := 1; i <= 5; i++ {
+		message, err := eventLog.Append(ctx, userID, []byte(fmt.Sprintf(`{"type":"test","payload":%d}`, i)))
+		require.NoError(t, err)
+		assert.JSONEq(t, fmt.Sprintf(`{"seq":%d,"type":"test","payload":%d}`, i, i), string(message))
+	}
+
+	events, latest, err := eventLog.Since(ctx, userID, 3)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), latest)
+	require.Len(t, events, 2)
+	assert.Equal(t, uint64(4), events[0].Seq)
+
+	events, _, err = eventLog.Since(ctx, userID, 0)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	assert.Equal(t, uint64(3), events[0].Seq)
+
+	acked, err := eventLog.Ack(ctx, userID, 4)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), acked)
+
+	_, err = eventLog.Ack(ctx, userID, 6)
+	assert.ErrorIs(t, err, websocket.ErrAckAhead)
+
+	acked, ok, err := eventLog.Acked(ctx, userID)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(4), acked)
+}
+
+// TestRedisEventLog_AppendOnce tests that the Redis event log logs a key once.
+// Without REDIS_HOST it is skipped.
+func TestRedisEventLog_AppendOnce(t *testing.T) {
+	viperConfig := config.NewViper()
+	if viperConfig.GetString("REDIS_HOST") == "" {
+		t.Skip("REDIS_HOST is not configured, skipping Redis event log test")
+	}
+
+	client := config.NewRedisClient(viperConfig)
+	defer client.Close()
+
+	eventLog := websocket.NewRedisEventLog(client, 3, time.Minute)
+	ctx := context.Background()
+	userID := uint(time.Now().UnixNano() % 1_000_000_000)
+
+	first, err := eventLog.AppendOnce(ctx, userID, "outbox:1", []byte(`{"type":"test","payload":1}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"seq":1,"type":"test","payload":1}`, string(first))
+
+	again, err := eventLog.AppendOnce(ctx, userID, "outbox:1", []byte(`{"type":"test","payload":1}`))
+	require.NoError(t, err)
+	assert.Equal(t, first, again)
+
+	_, latest, err := eventLog.Since(ctx, userID, 0)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), latest)
+}
@@ -3,18 +3,25 @@ package websocket_test
 import (
 	"backend/internal/delivery/websocket"
 	"backend/internal/model"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // createTestHub creates a Hub instance for testing.
 func createTestHub() *websocket.Hub {
 	log := logrus.New()
 	log.SetOutput(io.Discard)
-	return websocket.NewHub(log)
+	return websocket.NewHub(log, websocket.NewMemoryEventLog(100, time.Hour))
 }
 
 // TestHub_NewHub tests creating a new Hub instance.
@@ -49,6 +56,71 @@ func TestHub_BroadcastToNonExistentUser(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+// TestHub_Disconnect tests that only the connections of the disconnected user are closed.
+func TestHub_Disconnect(t *testing.T) {
+	hub := createTestHub()
+
+	phone, laptop, other := newFakeConn(), newFakeConn(), newFakeConn()
+	clients := []*websocket.Client{newTestClient(1, phone), newTestClient(1, laptop), newTestClient(2, other)}
+	for _, client := range clients {
+		hub.Register(client)
+	}
+	defer func() {
+		for _, client := range clients {
+			hub.Unregister(client)
+			client.Close()
+			client.Wait()
+		}
+	}()
+
+	require.NoError(t, hub.Disconnect(&model.DisconnectRequest{UserID: 1}))
+
+	assert.True(t, phone.isClosed())
+	assert.True(t, laptop.isClosed())
+	assert.False(t, other.isClosed())
+	<-clients[0].Done()
+	<-clients[1].Done()
+}
+
+// TestHub_Disconnect_Session tests closing the connections of one session, or of every other session.
+func TestHub_Disconnect_Session(t *testing.T) {
+	userID := uint(1)
+	tests := []struct {
+		name     string
+		request  *model.DisconnectRequest
+		expected map[string]bool
+	}{
+		{name: "revoked session", request: &model.DisconnectRequest{UserID: 1, SessionID: "phone"}, expected: map[string]bool{"phone": true, "laptop": false}},
+		{name: "other sessions", request: &model.DisconnectRequest{UserID: 1, KeepSessionID: "phone"}, expected: map[string]bool{"phone": false, "laptop": true}},
+		{name: "other user", request: &model.DisconnectRequest{UserID: 2, SessionID: "phone"}, expected: map[string]bool{"phone": false, "laptop": false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub := createTestHub()
+
+			conns := make(map[string]*fakeConn)
+			for _, sessionID := range []string{"phone", "laptop"} {
+				conn := newFakeConn()
+				client := newTestClientWithAuth(&model.Auth{UserID: &userID, Role: "user", FamilyID: sessionID}, conn)
+				hub.Register(client)
+				defer func() {
+					hub.Unregister(client)
+					client.Close()
+					client.Wait()
+				}()
+				conns[sessionID] = conn
+			}
+
+			require.NoError(t, hub.Disconnect(tt.request))
+
+			for sessionID, closed := range tt.expected {
+				assert.Equal(t, closed, conns[sessionID].isClosed(), sessionID)
+			}
+		})
+	}
+}
+
 // TestNotifier_NewNotifier tests creating a new Notifier instance.
 func TestNotifier_NewNotifier(t *testing.T) {
 	hub := createTestHub()
@@ -77,7 +149,7 @@ func TestNotifier_NotifyTransaction_NoConnections(t *testing.T) {
 	}
 
 	// Should not return error even when no connections exist
-	err := notifier.NotifyTransaction(999, notification)
+	err := notifier.NotifyTransaction(999, "", notification)
 	assert.NoError(t, err)
 }
 
@@ -99,6 +171,104 @@ func TestNotifier_NotifyWalletUpdate_NoConnections(t *testing.T) {
 	}
 
 	// Should not return error even when no connections exist
-	err := notifier.NotifyWalletUpdate(999, notification)
+	err := notifier.NotifyWalletUpdate(999, "", notification)
 	assert.NoError(t, err)
 }
+
+// fakeInbox is an in-memory NotificationInboxInterface keyed like the notifications table.
+type fakeInbox struct {
+	mu     sync.Mutex
+	stored map[string]*model.NotificationResponse
+	err    error
+}
+
+func newFakeInbox() *fakeInbox {
+	return &fakeInbox{stored: make(map[string]*model.NotificationResponse)}
+}
+
+func (i *fakeInbox) Store(ctx context.Context, userID uint, notificationType string, referenceID uint, payload interface{}) (*model.NotificationResponse, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.err != nil {
+		return nil, i.err
+	}
+
+	key := fmt.Sprintf("%d:%s:%d", userID, notificationType, referenceID)
+	if stored, ok := i.stored[key]; ok {
+		return stored, nil
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	stored := &model.NotificationResponse{
+		ID:          uint(len(i.stored) + 1),
+		Type:        notificationType,
+		ReferenceID: referenceID,
+		Payload:     data,
+	}
+	i.stored[key] = stored
+	return stored, nil
+}
+
+// TestNotifier_NotifyTransaction_StoresInInbox tests that transaction notifications reach the inbox
+// once per transaction and that the live message carries the inbox ID.
+func TestNotifier_NotifyTransaction_StoresInInbox(t *testing.T) {
+	hub := createTestHub()
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	inbox := newFakeInbox()
+	notifier := websocket.NewNotifierWithInbox(hub, inbox, log)
+
+	conn := newFakeConn()
+	client := newTestClient(2, conn)
+	hub.Register(client)
+	defer func() {
+		hub.Unregister(client)
+		client.Close()
+		client.Wait()
+	}()
+
+	// Offline users still get the notification in their inbox
+	require.NoError(t, notifier.NotifyTransaction(999, "", &model.TransactionNotification{TransactionID: 1, TransactionType: "transfer", Amount: "10"}))
+
+	// A retried delivery of the same transaction does not add a second entry
+	for range 2 {
+		require.NoError(t, notifier.NotifyTransaction(2, "", &model.TransactionNotification{TransactionID: 1, TransactionType: "transfer", Amount: "10"}))
+	}
+
+	require.Len(t, inbox.stored, 2)
+	stored := inbox.stored["2:transaction:1"]
+	require.NotNil(t, stored)
+	assert.NotContains(t, string(stored.Payload), "notification_id")
+
+	assert.Eventually(t, func() bool { return len(conn.written()) == 2 }, time.Second, time.Millisecond)
+	for _, written := range conn.written() {
+		assert.Contains(t, written, fmt.Sprintf(`"notification_id":%d`, stored.ID))
+	}
+}
+
+// TestNotifier_NotifyTransaction_InboxError tests that a failed inbox write is returned for a retry.
+func TestNotifier_NotifyTransaction_InboxError(t *testing.T) {
+	hub := createTestHub()
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	inbox := newFakeInbox()
+	inbox.err = errors.New("database is down")
+	notifier := websocket.NewNotifierWithInbox(hub, inbox, log)
+
+	conn := newFakeConn()
+	client := newTestClient(2, conn)
+	hub.Register(client)
+	defer func() {
+		hub.Unregister(client)
+		client.Close()
+		client.Wait()
+	}()
+
+	err := notifier.NotifyTransaction(2, "", &model.TransactionNotification{TransactionID: 1, TransactionType: "transfer", Amount: "10"})
+	assert.Error(t, err)
+	assert.Empty(t, conn.written())
+}
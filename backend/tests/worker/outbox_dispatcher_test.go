@@ -0,0 +1,141 @@
+package worker_test
+
+import (
+	"backend/internal/entity"
+	"backend/internal/model"
+	"backend/internal/worker"
+	"backend/tests/mocks"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// createTestDispatcher creates an OutboxDispatcher backed by a mock notifier.
+func createTestDispatcher() (*worker.OutboxDispatcher, *mocks.MockNotifier) {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	notifier := new(mocks.MockNotifier)
+	return &worker.OutboxDispatcher{
+		Log:         log,
+		Notifier:    notifier,
+		BatchSize:   10,
+		MaxAttempts: 3,
+	}, notifier
+}
+
+// createTestEvent creates an outbox event with the given payload.
+func createTestEvent(t *testing.T, eventType entity.OutboxEventType, payload any) *entity.OutboxEvent {
+	data, err := json.Marshal(payload)
+	assert.NoError(t, err)
+	return &entity.OutboxEvent{
+		ID:        1,
+		UserID:    7,
+		EventType: eventType,
+		Payload:   data,
+		Status:    entity.OutboxEventStatusPending,
+	}
+}
+
+// TestOutboxDispatcher_Deliver_Transaction tests delivering a transaction event.
+func TestOutboxDispatcher_Deliver_Transaction(t *testing.T) {
+	dispatcher, notifier := createTestDispatcher()
+	event := createTestEvent(t, entity.OutboxEventTypeTransaction, &model.TransactionNotification{
+		TransactionID:   42,
+		TransactionType: "transfer",
+		Amount:          "100",
+		ToUserID:        7,
+	})
+
+	notifier.On("NotifyTransaction", uint(7), "outbox:1", mock.MatchedBy(func(n *model.TransactionNotification) bool {
+		return n.TransactionID == 42 && n.Amount == "100"
+	})).Return(nil)
+
+	err := dispatcher.Deliver(event)
+
+	assert.NoError(t, err)
+	notifier.AssertExpectations(t)
+}
+
+// TestOutboxDispatcher_Deliver_WalletUpdate tests delivering a wallet update event.
+func TestOutboxDispatcher_Deliver_WalletUpdate(t *testing.T) {
+	dispatcher, notifier := createTestDispatcher()
+	event := createTestEvent(t, entity.OutboxEventTypeWalletUpdate, &model.WalletUpdateNotification{
+		WalletID:   3,
+		NewBalance: "250",
+		MutationID: 9,
+	})
+
+	notifier.On("NotifyWalletUpdate", uint(7), "outbox:1", mock.MatchedBy(func(n *model.WalletUpdateNotification) bool {
+		return n.WalletID == 3 && n.MutationID == 9
+	})).Return(nil)
+
+	err := dispatcher.Deliver(event)
+
+	assert.NoError(t, err)
+	notifier.AssertExpectations(t)
+}
+
+// TestOutboxDispatcher_Deliver_AdminTransaction tests delivering an admin feed event.
+func TestOutboxDispatcher_Deliver_AdminTransaction(t *testing.T) {
+	dispatcher, notifier := createTestDispatcher()
+	event := createTestEvent(t, entity.OutboxEventTypeAdminTransaction, &model.AdminTransactionNotification{
+		TransactionID:   42,
+		TransactionType: "top_up",
+		Amount:          "100",
+		ToUserID:        9,
+		ToUsername:      "alice",
+	})
+
+	notifier.On("NotifyAdminTransaction", mock.MatchedBy(func(n *model.AdminTransactionNotification) bool {
+		return n.TransactionID == 42 && n.ToUsername == "alice"
+	})).Return(nil)
+
+	err := dispatcher.Deliver(event)
+
+	assert.NoError(t, err)
+	notifier.AssertExpectations(t)
+}
+
+// TestOutboxDispatcher_Deliver_NotifierError tests that notifier failures are returned for retry.
+func TestOutboxDispatcher_Deliver_NotifierError(t *testing.T) {
+	dispatcher, notifier := createTestDispatcher()
+	event := createTestEvent(t, entity.OutboxEventTypeTransaction, &model.TransactionNotification{TransactionID: 1})
+
+	notifier.On("NotifyTransaction", uint(7), "outbox:1", mock.Anything).Return(errors.New("write failed"))
+
+	err := dispatcher.Deliver(event)
+
+	assert.EqualError(t, err, "write failed")
+}
+
+// TestOutboxDispatcher_Deliver_UnknownType tests that unknown event types are rejected.
+func TestOutboxDispatcher_Deliver_UnknownType(t *testing.T) {
+	dispatcher, notifier := createTestDispatcher()
+	event := createTestEvent(t, entity.OutboxEventType("unknown"), map[string]string{})
+
+	err := dispatcher.Deliver(event)
+
+	assert.Error(t, err)
+	notifier.AssertNotCalled(t, "NotifyTransaction", mock.Anything, mock.Anything, mock.Anything)
+	notifier.AssertNotCalled(t, "NotifyWalletUpdate", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestOutboxDispatcher_Deliver_InvalidPayload tests that a corrupt payload is reported as an error.
+func TestOutboxDispatcher_Deliver_InvalidPayload(t *testing.T) {
+	dispatcher, _ := createTestDispatcher()
+	event := &entity.OutboxEvent{
+		ID:        1,
+		UserID:    7,
+		EventType: entity.OutboxEventTypeWalletUpdate,
+		Payload:   json.RawMessage(`not json`),
+	}
+
+	err := dispatcher.Deliver(event)
+
+	assert.Error(t, err)
+}